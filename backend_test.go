@@ -0,0 +1,44 @@
+//go:build (js && wasm) || safejstest
+
+package safejs
+
+import (
+	"testing"
+
+	"github.com/hack-pad/safejs/internal/assert"
+	"github.com/hack-pad/safejs/internal/backend"
+	"github.com/hack-pad/safejs/internal/js"
+)
+
+// recordingBackend records the properties read through it, and fails reads of the property "fail"
+type recordingBackend struct {
+	backend.Backend
+	gets []string
+}
+
+func (b *recordingBackend) Get(v js.Value, p string) js.Value {
+	b.gets = append(b.gets, p)
+	if p == "fail" {
+		panic(js.Error{Value: b.Backend.Global().Get("Error").New("failed get")})
+	}
+	return b.Backend.Get(v, p)
+}
+
+func TestBackend(t *testing.T) { //nolint:paralleltest // Replaces the backend for all tests
+	recorder := &recordingBackend{Backend: backend.Default()}
+	previous := backend.Set(recorder)
+	defer backend.Set(previous)
+
+	obj, err := ValueOf(map[string]any{"foo": "bar"})
+	assert.NoError(t, err)
+	value, err := obj.Get("foo")
+	assert.NoError(t, err)
+	str, err := value.String()
+	assert.NoError(t, err)
+	assert.Equal(t, "bar", str)
+
+	_, err = obj.Get("fail")
+	assert.EqualError(t, err, "JavaScript error: failed get")
+	assert.Equal(t, []string{"foo", "fail"}, recorder.gets)
+	assert.Equal(t, true, Unsafe(obj).Get("foo").Equal(Unsafe(value)))
+}
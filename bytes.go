@@ -1,27 +1,106 @@
-//go:build js && wasm
-
 package safejs
 
 import (
-	"syscall/js"
+	"fmt"
 
+	"github.com/hack-pad/safejs/internal/backend"
 	"github.com/hack-pad/safejs/internal/catch"
 )
 
+var (
+	jsArrayBuffer       = newCachedGlobal("ArrayBuffer")
+	jsSharedArrayBuffer = newCachedGlobal("SharedArrayBuffer")
+)
+
 // CopyBytesToGo copies bytes from src to dst.
 // Returns the number of bytes copied, which is the minimum of the lengths of src and dst.
-// Returns an error if src is not an Uint8Array or Uint8ClampedArray.
+// Returns an error if src is not an ArrayBuffer, SharedArrayBuffer, DataView, or TypedArray.
+//
+// A DataView or TypedArray copies the bytes of its view, i.e. from its byteOffset up to its byteLength.
 func CopyBytesToGo(dst []byte, src Value) (int, error) {
+	srcBytes, err := uint8View("CopyBytesToGo", "src", src)
+	if err != nil {
+		return 0, err
+	}
 	return catch.Try(func() int {
-		return js.CopyBytesToGo(dst, src.jsValue)
+		return backend.Current().CopyBytesToGo(dst, srcBytes.jsValue)
 	})
 }
 
 // CopyBytesToJS copies bytes from src to dst.
 // Returns the number of bytes copied, which is the minimum of the lengths of src and dst.
-// Returns an error if dst is not an Uint8Array or Uint8ClampedArray.
+// Returns an error if dst is not an ArrayBuffer, SharedArrayBuffer, DataView, or TypedArray.
+//
+// A DataView or TypedArray copies into the bytes of its view, i.e. from its byteOffset up to its byteLength.
 func CopyBytesToJS(dst Value, src []byte) (int, error) {
+	dstBytes, err := uint8View("CopyBytesToJS", "dst", dst)
+	if err != nil {
+		return 0, err
+	}
 	return catch.Try(func() int {
-		return js.CopyBytesToJS(dst.jsValue, src)
+		return backend.Current().CopyBytesToJS(dstBytes.jsValue, src)
 	})
 }
+
+// uint8View returns a Uint8Array over the bytes of value.
+// Uint8Arrays and Uint8ClampedArrays are returned as-is, buffers are wrapped entirely, and other views are wrapped from their byteOffset to byteLength.
+func uint8View(funcName, argName string, value Value) (Value, error) {
+	for _, constructor := range []*cachedGlobal{jsUint8Array, jsUint8ClampedArray} {
+		isInstance, err := instanceOfGlobal(value, constructor)
+		if err != nil {
+			return Value{}, err
+		}
+		if isInstance {
+			return value, nil
+		}
+	}
+
+	uint8Array, err := jsUint8Array.Get()
+	if err != nil {
+		return Value{}, err
+	}
+	for _, constructor := range []*cachedGlobal{jsArrayBuffer, jsSharedArrayBuffer} {
+		isInstance, err := instanceOfGlobal(value, constructor)
+		if err != nil {
+			return Value{}, err
+		}
+		if isInstance {
+			return uint8Array.New(value)
+		}
+	}
+
+	arrayBuffer, err := jsArrayBuffer.Get()
+	if err != nil {
+		return Value{}, err
+	}
+	isView, err := arrayBuffer.Call("isView", value)
+	if err != nil {
+		return Value{}, err
+	}
+	if isViewBool, err := isView.Bool(); err != nil || !isViewBool {
+		return Value{}, fmt.Errorf("%s: expected %s to be an ArrayBuffer, SharedArrayBuffer, DataView, or TypedArray", funcName, argName)
+	}
+	buffer, err := value.Get("buffer")
+	if err != nil {
+		return Value{}, err
+	}
+	byteOffset, err := value.Get("byteOffset")
+	if err != nil {
+		return Value{}, err
+	}
+	byteLength, err := value.Get("byteLength")
+	if err != nil {
+		return Value{}, err
+	}
+	return uint8Array.New(buffer, byteOffset, byteLength)
+}
+
+// instanceOfGlobal reports whether value is an instance of the given global.
+// Returns an error if the global could not be retrieved, including when it is not defined by this JavaScript runtime.
+func instanceOfGlobal(value Value, global *cachedGlobal) (bool, error) {
+	globalValue, err := global.Get()
+	if err != nil {
+		return false, err
+	}
+	return value.InstanceOf(globalValue)
+}
@@ -1,4 +1,4 @@
-//go:build js && wasm
+//go:build (js && wasm) || safejstest
 
 package safejs
 
@@ -11,8 +11,80 @@ import (
 func TestCopyBytesError(t *testing.T) {
 	t.Parallel()
 	_, err := CopyBytesToGo(nil, Undefined())
-	assert.EqualError(t, err, "syscall/js: CopyBytesToGo: expected src to be an Uint8Array or Uint8ClampedArray")
+	assert.EqualError(t, err, "CopyBytesToGo: expected src to be an ArrayBuffer, SharedArrayBuffer, DataView, or TypedArray")
 
 	_, err = CopyBytesToJS(Undefined(), nil)
-	assert.EqualError(t, err, "syscall/js: CopyBytesToJS: expected dst to be an Uint8Array or Uint8ClampedArray")
+	assert.EqualError(t, err, "CopyBytesToJS: expected dst to be an ArrayBuffer, SharedArrayBuffer, DataView, or TypedArray")
+
+	obj, err := NewObject()
+	assert.NoError(t, err)
+	_, err = CopyBytesToGo(nil, obj)
+	assert.EqualError(t, err, "CopyBytesToGo: expected src to be an ArrayBuffer, SharedArrayBuffer, DataView, or TypedArray")
+}
+
+func TestInstanceOfGlobalLookupError(t *testing.T) {
+	t.Parallel()
+	obj, err := NewObject()
+	assert.NoError(t, err)
+	_, err = instanceOfGlobal(obj, newCachedGlobal("safejsUndefinedGlobal"))
+	assert.EqualError(t, err, `global "safejsUndefinedGlobal" is not defined`)
+}
+
+func TestCopyBytes(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		description string
+		newValue    func(t *testing.T, buffer Value) Value
+		expectBytes []byte
+	}{
+		{
+			description: "Uint8Array",
+			newValue: func(t *testing.T, buffer Value) Value {
+				return newTypedArray(t, "Uint8Array", buffer)
+			},
+			expectBytes: []byte{1, 2, 3, 4, 5, 6, 7, 8},
+		},
+		{
+			description: "ArrayBuffer",
+			newValue: func(t *testing.T, buffer Value) Value {
+				return buffer
+			},
+			expectBytes: []byte{1, 2, 3, 4, 5, 6, 7, 8},
+		},
+		{
+			description: "DataView",
+			newValue: func(t *testing.T, buffer Value) Value {
+				return newTypedArray(t, "DataView", buffer, 1, 3)
+			},
+			expectBytes: []byte{2, 3, 4},
+		},
+		{
+			description: "Uint16Array",
+			newValue: func(t *testing.T, buffer Value) Value {
+				return newTypedArray(t, "Uint16Array", buffer, 2, 2)
+			},
+			expectBytes: []byte{3, 4, 5, 6},
+		},
+	} {
+		tc := tc
+		t.Run(tc.description, func(t *testing.T) {
+			t.Parallel()
+			buffer := newTypedArray(t, "ArrayBuffer", 8)
+			_, err := CopyBytesToJS(buffer, []byte{1, 2, 3, 4, 5, 6, 7, 8})
+			assert.NoError(t, err)
+			value := tc.newValue(t, buffer)
+
+			dst := make([]byte, 10)
+			n, err := CopyBytesToGo(dst, value)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectBytes, dst[:n])
+
+			n, err = CopyBytesToJS(value, []byte{9, 9})
+			assert.NoError(t, err)
+			assert.Equal(t, 2, n)
+			n, err = CopyBytesToGo(dst, value)
+			assert.NoError(t, err)
+			assert.Equal(t, append([]byte{9, 9}, tc.expectBytes[2:]...), dst[:n])
+		})
+	}
 }
@@ -1,8 +1,10 @@
-//go:build js && wasm
-
 /*
 Package safejs provides guardrails around the [syscall/js] package, like turning thrown exceptions into errors.
 
+The package builds for every platform, so cross-platform packages may import it.
+Outside js/wasm, operations which need a JavaScript runtime return [ErrUnsupported], and [Global] returns undefined.
+To run code using safejs in native tests, see the safejstest package.
+
 Since [syscall/js] is experimental, this package may have breaking changes to stay aligned with the latest versions of Go.
 */
 package safejs
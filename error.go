@@ -1,13 +1,15 @@
-//go:build js && wasm
-
 package safejs
 
 import (
-	"syscall/js"
-
+	"github.com/hack-pad/safejs/internal/backend"
 	"github.com/hack-pad/safejs/internal/catch"
+	"github.com/hack-pad/safejs/internal/js"
 )
 
+// ErrUnsupported is returned by every operation which needs a JavaScript runtime, when built for platforms other than js/wasm.
+// Cross-platform packages can check for it with errors.Is to guard JavaScript usage at runtime.
+var ErrUnsupported = backend.ErrUnsupported
+
 // Error wraps a JavaScript error.
 type Error struct {
 	err js.Error
@@ -1,12 +1,12 @@
-//go:build js && wasm
+//go:build (js && wasm) || safejstest
 
 package safejs
 
 import (
-	"syscall/js"
 	"testing"
 
 	"github.com/hack-pad/safejs/internal/assert"
+	"github.com/hack-pad/safejs/internal/js"
 )
 
 func TestError(t *testing.T) {
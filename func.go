@@ -1,11 +1,9 @@
-//go:build js && wasm
-
 package safejs
 
 import (
-	"syscall/js"
-
+	"github.com/hack-pad/safejs/internal/backend"
 	"github.com/hack-pad/safejs/internal/catch"
+	"github.com/hack-pad/safejs/internal/js"
 )
 
 // Func is a wrapped Go function to be called by JavaScript.
@@ -27,14 +25,14 @@ func toJSFunc(fn func(this Value, args []Value) any) (js.Func, error) {
 		return toJSValue(result)
 	}
 	return catch.Try(func() js.Func {
-		return js.FuncOf(jsFunc)
+		return backend.Current().FuncOf(jsFunc)
 	})
 }
 
 // Release frees up resources allocated for the function. The function must not be invoked after calling Release.
 // It is allowed to call Release while the function is still running.
 func (f Func) Release() {
-	f.fn.Release()
+	backend.Current().Release(f.fn)
 }
 
 // Value returns this Func's inner Value. For example, using value.Invoke() calls the function.
@@ -1,15 +1,22 @@
-//go:build js && wasm
-
 package safejs
 
 import (
 	"fmt"
-	"syscall/js"
+	"sync"
+
+	"github.com/hack-pad/safejs/internal/backend"
+)
+
+var (
+	jsArray   = newCachedGlobal("Array")
+	jsObject  = newCachedGlobal("Object")
+	jsReflect = newCachedGlobal("Reflect")
+	jsSymbol  = newCachedGlobal("Symbol")
 )
 
 // Global returns the JavaScript global object, usually "window" or "global".
 func Global() Value {
-	return Safe(js.Global())
+	return Safe(backend.Current().Global())
 }
 
 // MustGetGlobal fetches the given global, then verifies it is truthy. Panics on error or falsy values.
@@ -40,3 +47,31 @@ func getGlobal(property string) (Value, error) {
 	}
 	return value, nil
 }
+
+// cachedGlobal fetches a global on first successful use, then returns the same value for all subsequent calls.
+// Failed lookups are not cached, so a transient failure is retried on the next call.
+type cachedGlobal struct {
+	property string
+	mu       sync.Mutex
+	value    Value
+	ok       bool
+}
+
+func newCachedGlobal(property string) *cachedGlobal {
+	return &cachedGlobal{property: property}
+}
+
+// Get returns the global, fetching it with getGlobal if not yet cached.
+func (c *cachedGlobal) Get() (Value, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ok {
+		return c.value, nil
+	}
+	value, err := getGlobal(c.property)
+	if err != nil {
+		return Value{}, err
+	}
+	c.value, c.ok = value, true
+	return value, nil
+}
@@ -1,4 +1,4 @@
-//go:build js && wasm
+//go:build (js && wasm) || safejstest
 
 package safejs
 
@@ -39,3 +39,25 @@ func TestMustGetGlobal(t *testing.T) {
 		assert.EqualError(t, err, `global "Uint8Array-foo" is not defined`)
 	})
 }
+
+func TestCachedGlobal(t *testing.T) {
+	t.Parallel()
+	const property = "safejsCachedGlobal"
+	cached := newCachedGlobal(property)
+	_, err := cached.Get()
+	assert.EqualError(t, err, `global "safejsCachedGlobal" is not defined`)
+
+	assert.NoError(t, Global().Set(property, "foo"))
+	value, err := cached.Get()
+	assert.NoError(t, err)
+	str, err := value.String()
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", str)
+
+	assert.NoError(t, Global().Delete(property))
+	value, err = cached.Get()
+	assert.NoError(t, err)
+	str, err = value.String()
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", str)
+}
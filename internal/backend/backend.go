@@ -0,0 +1,98 @@
+// Package backend contains the JavaScript runtime operations behind safejs.Value, so they can be swapped out for testing, tracing, or sandboxing.
+package backend
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"github.com/hack-pad/safejs/internal/js"
+)
+
+// ErrUnsupported is returned by operations which need a JavaScript runtime, on platforms without one
+var ErrUnsupported = errors.New("safejs: JavaScript is not supported on this platform")
+
+// Backend runs JavaScript operations on values.
+// The default is [syscall/js] for js/wasm, and an unsupported Backend for other platforms.
+//
+// Methods panic like their [syscall/js] equivalents, and safejs turns those panics into errors.
+// Null and Undefined are constants, so they always come from [syscall/js].
+type Backend interface {
+	// Global returns the JavaScript global object, like js.Global()
+	Global() js.Value
+	// ValueOf returns x as a JavaScript value, like js.ValueOf(x)
+	ValueOf(x any) js.Value
+	// FuncOf returns a function to be used by JavaScript, like js.FuncOf(fn)
+	FuncOf(fn func(this js.Value, args []js.Value) any) js.Func
+	// Release frees up resources allocated for fn, like fn.Release()
+	Release(fn js.Func)
+	// CopyBytesToGo copies bytes from src to dst, like js.CopyBytesToGo(dst, src)
+	CopyBytesToGo(dst []byte, src js.Value) int
+	// CopyBytesToJS copies bytes from src to dst, like js.CopyBytesToJS(dst, src)
+	CopyBytesToJS(dst js.Value, src []byte) int
+
+	// Get returns v's property p, like v.Get(p)
+	Get(v js.Value, p string) js.Value
+	// Set sets v's property p to x, like v.Set(p, x)
+	Set(v js.Value, p string, x any)
+	// Delete deletes v's property p, like v.Delete(p)
+	Delete(v js.Value, p string)
+	// Index returns v's index i, like v.Index(i)
+	Index(v js.Value, i int) js.Value
+	// SetIndex sets v's index i to x, like v.SetIndex(i, x)
+	SetIndex(v js.Value, i int, x any)
+	// Length returns v's "length" property, like v.Length()
+	Length(v js.Value) int
+	// Call calls v's method m, like v.Call(m, args...)
+	Call(v js.Value, m string, args ...any) js.Value
+	// Invoke calls v, like v.Invoke(args...)
+	Invoke(v js.Value, args ...any) js.Value
+	// New calls v as a constructor, like v.New(args...)
+	New(v js.Value, args ...any) js.Value
+	// InstanceOf reports whether v is an instance of t, like v.InstanceOf(t)
+	InstanceOf(v, t js.Value) bool
+	// Equal reports whether v === w, like v.Equal(w)
+	Equal(v, w js.Value) bool
+	// Type returns v's type, like v.Type()
+	Type(v js.Value) js.Type
+
+	// Bool returns v as a bool, like v.Bool()
+	Bool(v js.Value) bool
+	// Float returns v as a float64, like v.Float()
+	Float(v js.Value) float64
+	// Int returns v as an int, like v.Int()
+	Int(v js.Value) int
+	// String returns v as a string, like v.String()
+	String(v js.Value) string
+	// Truthy returns v's JavaScript "truthiness", like v.Truthy()
+	Truthy(v js.Value) bool
+	// IsNaN reports whether v is NaN, like v.IsNaN()
+	IsNaN(v js.Value) bool
+	// IsNull reports whether v is null, like v.IsNull()
+	IsNull(v js.Value) bool
+	// IsUndefined reports whether v is undefined, like v.IsUndefined()
+	IsUndefined(v js.Value) bool
+}
+
+// holder wraps a Backend, since atomic.Value requires the same concrete type for every store
+type holder struct {
+	backend Backend
+}
+
+var current atomic.Value
+
+func init() {
+	current.Store(holder{backend: Default()})
+}
+
+// Current returns the Backend in use
+func Current() Backend {
+	return current.Load().(holder).backend
+}
+
+// Set replaces the Backend in use with b and returns the previous one. A nil b restores the default.
+func Set(b Backend) (previous Backend) {
+	if b == nil {
+		b = Default()
+	}
+	return current.Swap(holder{backend: b}).(holder).backend
+}
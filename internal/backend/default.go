@@ -0,0 +1,112 @@
+//go:build (js && wasm) || safejstest
+
+package backend
+
+import "github.com/hack-pad/safejs/internal/js"
+
+// jsBackend calls the JavaScript runtime directly: [syscall/js], or the fake runtime for safejstest builds
+type jsBackend struct{}
+
+func (jsBackend) Global() js.Value {
+	return js.Global()
+}
+
+func (jsBackend) ValueOf(x any) js.Value {
+	return js.ValueOf(x)
+}
+
+func (jsBackend) FuncOf(fn func(this js.Value, args []js.Value) any) js.Func {
+	return js.FuncOf(fn)
+}
+
+func (jsBackend) Release(fn js.Func) {
+	fn.Release()
+}
+
+func (jsBackend) CopyBytesToGo(dst []byte, src js.Value) int {
+	return js.CopyBytesToGo(dst, src)
+}
+
+func (jsBackend) CopyBytesToJS(dst js.Value, src []byte) int {
+	return js.CopyBytesToJS(dst, src)
+}
+
+func (jsBackend) Get(v js.Value, p string) js.Value {
+	return v.Get(p)
+}
+
+func (jsBackend) Set(v js.Value, p string, x any) {
+	v.Set(p, x)
+}
+
+func (jsBackend) Delete(v js.Value, p string) {
+	v.Delete(p)
+}
+
+func (jsBackend) Index(v js.Value, i int) js.Value {
+	return v.Index(i)
+}
+
+func (jsBackend) SetIndex(v js.Value, i int, x any) {
+	v.SetIndex(i, x)
+}
+
+func (jsBackend) Length(v js.Value) int {
+	return v.Length()
+}
+
+func (jsBackend) Call(v js.Value, m string, args ...any) js.Value {
+	return v.Call(m, args...)
+}
+
+func (jsBackend) Invoke(v js.Value, args ...any) js.Value {
+	return v.Invoke(args...)
+}
+
+func (jsBackend) New(v js.Value, args ...any) js.Value {
+	return v.New(args...)
+}
+
+func (jsBackend) InstanceOf(v, t js.Value) bool {
+	return v.InstanceOf(t)
+}
+
+func (jsBackend) Equal(v, w js.Value) bool {
+	return v.Equal(w)
+}
+
+func (jsBackend) Type(v js.Value) js.Type {
+	return v.Type()
+}
+
+func (jsBackend) Bool(v js.Value) bool {
+	return v.Bool()
+}
+
+func (jsBackend) Float(v js.Value) float64 {
+	return v.Float()
+}
+
+func (jsBackend) Int(v js.Value) int {
+	return v.Int()
+}
+
+func (jsBackend) String(v js.Value) string {
+	return v.String()
+}
+
+func (jsBackend) Truthy(v js.Value) bool {
+	return v.Truthy()
+}
+
+func (jsBackend) IsNaN(v js.Value) bool {
+	return v.IsNaN()
+}
+
+func (jsBackend) IsNull(v js.Value) bool {
+	return v.IsNull()
+}
+
+func (jsBackend) IsUndefined(v js.Value) bool {
+	return v.IsUndefined()
+}
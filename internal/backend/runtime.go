@@ -0,0 +1,8 @@
+//go:build (js && wasm) || safejstest
+
+package backend
+
+// Default returns the Backend which calls the JavaScript runtime directly
+func Default() Backend {
+	return jsBackend{}
+}
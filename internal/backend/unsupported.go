@@ -0,0 +1,116 @@
+//go:build !js && !safejstest
+
+package backend
+
+import "github.com/hack-pad/safejs/internal/js"
+
+// Default returns the Backend for platforms without a JavaScript runtime, which panics with ErrUnsupported
+func Default() Backend {
+	return unsupportedBackend{}
+}
+
+// unsupportedBackend fails every operation which needs a JavaScript runtime.
+// Without a runtime, the only values are undefined and null, so operations which can't fail still work.
+type unsupportedBackend struct{}
+
+func (unsupportedBackend) Global() js.Value {
+	return js.Undefined()
+}
+
+func (unsupportedBackend) ValueOf(any) js.Value {
+	panic(ErrUnsupported)
+}
+
+func (unsupportedBackend) FuncOf(func(this js.Value, args []js.Value) any) js.Func {
+	panic(ErrUnsupported)
+}
+
+func (unsupportedBackend) Release(js.Func) {}
+
+func (unsupportedBackend) CopyBytesToGo([]byte, js.Value) int {
+	panic(ErrUnsupported)
+}
+
+func (unsupportedBackend) CopyBytesToJS(js.Value, []byte) int {
+	panic(ErrUnsupported)
+}
+
+func (unsupportedBackend) Get(js.Value, string) js.Value {
+	panic(ErrUnsupported)
+}
+
+func (unsupportedBackend) Set(js.Value, string, any) {
+	panic(ErrUnsupported)
+}
+
+func (unsupportedBackend) Delete(js.Value, string) {
+	panic(ErrUnsupported)
+}
+
+func (unsupportedBackend) Index(js.Value, int) js.Value {
+	panic(ErrUnsupported)
+}
+
+func (unsupportedBackend) SetIndex(js.Value, int, any) {
+	panic(ErrUnsupported)
+}
+
+func (unsupportedBackend) Length(js.Value) int {
+	panic(ErrUnsupported)
+}
+
+func (unsupportedBackend) Call(js.Value, string, ...any) js.Value {
+	panic(ErrUnsupported)
+}
+
+func (unsupportedBackend) Invoke(js.Value, ...any) js.Value {
+	panic(ErrUnsupported)
+}
+
+func (unsupportedBackend) New(js.Value, ...any) js.Value {
+	panic(ErrUnsupported)
+}
+
+func (unsupportedBackend) InstanceOf(js.Value, js.Value) bool {
+	panic(ErrUnsupported)
+}
+
+func (unsupportedBackend) Equal(v, w js.Value) bool {
+	return v.Equal(w)
+}
+
+func (unsupportedBackend) Type(v js.Value) js.Type {
+	return v.Type()
+}
+
+func (unsupportedBackend) Bool(js.Value) bool {
+	panic(ErrUnsupported)
+}
+
+func (unsupportedBackend) Float(js.Value) float64 {
+	panic(ErrUnsupported)
+}
+
+func (unsupportedBackend) Int(js.Value) int {
+	panic(ErrUnsupported)
+}
+
+func (unsupportedBackend) String(js.Value) string {
+	panic(ErrUnsupported)
+}
+
+func (unsupportedBackend) Truthy(js.Value) bool {
+	panic(ErrUnsupported)
+}
+
+func (unsupportedBackend) IsNaN(v js.Value) bool {
+	return v.IsNaN()
+}
+
+func (unsupportedBackend) IsNull(v js.Value) bool {
+	return v.IsNull()
+}
+
+func (unsupportedBackend) IsUndefined(v js.Value) bool {
+	return v.IsUndefined()
+}
@@ -1,12 +1,10 @@
-//go:build js && wasm
-
 // Package catch runs functions and returns panic values as errors instead.
 package catch
 
 import (
 	"fmt"
-	"syscall/js"
 
+	"github.com/hack-pad/safejs/internal/js"
 	"github.com/hack-pad/safejs/internal/stackerr"
 )
 
@@ -1,13 +1,13 @@
-//go:build js && wasm
+//go:build (js && wasm) || safejstest
 
 package catch
 
 import (
 	"errors"
-	"syscall/js"
 	"testing"
 
 	"github.com/hack-pad/safejs/internal/assert"
+	"github.com/hack-pad/safejs/internal/js"
 )
 
 func TestTry(t *testing.T) {
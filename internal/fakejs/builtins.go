@@ -0,0 +1,565 @@
+package fakejs
+
+import (
+	"math"
+	"strings"
+	"sync"
+)
+
+// globals holds the global object and built-in prototypes
+type globals struct {
+	global              *object
+	objectPrototype     *object
+	functionPrototype   *object
+	arrayPrototype      *object
+	errorPrototype      *object
+	typeErrorPrototype  *object
+	rangeErrorPrototype *object
+	wellKnownSymbols    map[string]*symbol
+	symbolRegistry      symbolRegistry
+}
+
+var (
+	realmOnce  sync.Once
+	realmValue *globals
+)
+
+// realm returns the global object and built-ins, creating them on first use
+func realm() *globals {
+	realmOnce.Do(func() {
+		realmValue = newGlobals()
+	})
+	return realmValue
+}
+
+func newGlobals() *globals {
+	objectPrototype := newObject(nil)
+	functionPrototype := newObject(objectPrototype)
+	functionPrototype.class = "Function"
+	functionPrototype.fn = &function{call: func(this Value, args []Value) Value {
+		return Undefined()
+	}}
+	r := &globals{
+		global:            newObject(objectPrototype),
+		objectPrototype:   objectPrototype,
+		functionPrototype: functionPrototype,
+		wellKnownSymbols:  make(map[string]*symbol),
+	}
+	r.global.class = "global"
+	r.global.set("globalThis", objectValue(r.global))
+	r.global.set("self", objectValue(r.global))
+	r.global.set("undefined", Undefined())
+	r.global.set("NaN", floatValue(math.NaN()))
+	r.global.set("Infinity", floatValue(math.Inf(1)))
+
+	r.setupSymbol()
+	r.setupObject()
+	r.setupFunction()
+	r.setupArray()
+	r.setupPrimitiveWrappers()
+	r.setupErrors()
+	r.setupReflect()
+	r.setupBuffers()
+	r.setupTypedArrays()
+	return r
+}
+
+// method sets obj's property name to a new built-in function, which can't be used as a constructor
+func (r *globals) method(obj *object, name string, length int, call func(this Value, args []Value) Value) *object {
+	fn := r.newFunction(name, length, call)
+	obj.set(name, objectValue(fn))
+	return fn
+}
+
+// newConstructor creates a global constructor with the given prototype.
+// If construct is nil, "new" calls call with a new object.
+func (r *globals) newConstructor(name string, length int, prototype *object, call func(this Value, args []Value) Value, construct func(newTarget *object, args []Value) Value) *object {
+	constructor := r.newFunction(name, length, call)
+	constructor.fn.constructor = true
+	constructor.fn.construct = construct
+	addPrototype(constructor, prototype)
+	r.global.set(name, objectValue(constructor))
+	return constructor
+}
+
+// thisObject returns this as an object, or throws a TypeError mentioning method
+func thisObject(this Value, method string) *object {
+	obj, ok := this.ref.(*object)
+	if !ok {
+		throwTypeError("%s called on non-object", method)
+	}
+	return obj
+}
+
+// argumentObject returns args[i] as an object, or throws a TypeError mentioning method
+func argumentObject(args []Value, i int, method string) *object {
+	obj, ok := argument(args, i).ref.(*object)
+	if !ok {
+		throwTypeError("%s called on non-object", method)
+	}
+	return obj
+}
+
+// listFromArrayLike returns the elements of an array-like object, like the arguments list of Function.prototype.apply
+func listFromArrayLike(v Value) []Value {
+	obj, ok := v.ref.(*object)
+	if !ok {
+		throwTypeError("CreateListFromArrayLike called on non-object")
+	}
+	length := int(toIntegerOrInfinity(obj.get("length")))
+	values := make([]Value, 0, length)
+	for i := 0; i < length; i++ {
+		values = append(values, obj.get(indexKey(i)))
+	}
+	return values
+}
+
+func (r *globals) setupObject() {
+	var objectType *object
+	toObject := func(newTarget *object, args []Value) Value {
+		value := argument(args, 0)
+		switch value.ref.(type) {
+		case *object:
+			return value
+		case nil, null:
+			return objectValue(newObject(prototypeFrom(newTarget, r.objectPrototype)))
+		default:
+			return objectValue(r.wrapPrimitive(value))
+		}
+	}
+	objectType = r.newConstructor("Object", 1, r.objectPrototype, func(this Value, args []Value) Value {
+		return toObject(objectType, args)
+	}, toObject)
+
+	r.method(objectType, "keys", 1, func(this Value, args []Value) Value {
+		value := argument(args, 0)
+		if value.IsUndefined() || value.IsNull() {
+			throwTypeError("Cannot convert undefined or null to object")
+		}
+		var keys []Value
+		if obj, ok := value.ref.(*object); ok {
+			for _, key := range obj.ownKeys() {
+				keys = append(keys, stringValue(key))
+			}
+		}
+		return objectValue(newArray(keys))
+	})
+	r.method(objectType, "create", 1, func(this Value, args []Value) Value {
+		proto := argument(args, 0)
+		if proto.IsNull() {
+			return objectValue(newObject(nil))
+		}
+		return objectValue(newObject(argumentObject(args, 0, "Object.create")))
+	})
+	r.method(objectType, "freeze", 1, func(this Value, args []Value) Value {
+		value := argument(args, 0)
+		if obj, ok := value.ref.(*object); ok {
+			obj.freeze()
+		}
+		return value
+	})
+	r.method(objectType, "isFrozen", 1, func(this Value, args []Value) Value {
+		obj, ok := argument(args, 0).ref.(*object)
+		return boolValue(!ok || obj.isFrozen())
+	})
+	r.method(objectType, "getPrototypeOf", 1, func(this Value, args []Value) Value {
+		return prototypeValue(argumentObject(args, 0, "Object.getPrototypeOf"))
+	})
+	r.method(objectType, "setPrototypeOf", 2, func(this Value, args []Value) Value {
+		obj := argumentObject(args, 0, "Object.setPrototypeOf")
+		if proto := argument(args, 1); proto.IsNull() {
+			obj.setPrototype(nil)
+		} else {
+			obj.setPrototype(argumentObject(args, 1, "Object.setPrototypeOf"))
+		}
+		return objectValue(obj)
+	})
+
+	r.method(r.objectPrototype, "hasOwnProperty", 1, func(this Value, args []Value) Value {
+		return boolValue(thisObject(this, "Object.prototype.hasOwnProperty").hasOwn(toString(argument(args, 0))))
+	})
+	r.method(r.objectPrototype, "toString", 0, func(this Value, args []Value) Value {
+		switch ref := this.ref.(type) {
+		case nil:
+			return stringValue("[object Undefined]")
+		case null:
+			return stringValue("[object Null]")
+		case *object:
+			if tag, ok := ref.getSymbol(r.wellKnownSymbols["toStringTag"]).ref.(string); ok {
+				return stringValue("[object " + tag + "]")
+			}
+			return stringValue("[object " + ref.class + "]")
+		default:
+			typeName := this.Type().String()
+			return stringValue("[object " + strings.ToUpper(typeName[:1]) + typeName[1:] + "]")
+		}
+	})
+	r.method(r.objectPrototype, "valueOf", 0, func(this Value, args []Value) Value {
+		return this
+	})
+}
+
+func prototypeValue(obj *object) Value {
+	proto := obj.prototype()
+	if proto == nil {
+		return Null()
+	}
+	return objectValue(proto)
+}
+
+func (r *globals) setupFunction() {
+	functionType := r.newConstructor("Function", 1, r.functionPrototype, func(this Value, args []Value) Value {
+		throwError("Function constructor is not supported: fakejs can't run JavaScript source code")
+		return Undefined()
+	}, nil)
+	functionType.fn.constructor = false
+
+	r.method(r.functionPrototype, "call", 1, func(this Value, args []Value) Value {
+		return thisObject(this, "Function.prototype.call").callFunc(argument(args, 0), tail(args))
+	})
+	r.method(r.functionPrototype, "apply", 2, func(this Value, args []Value) Value {
+		fn := thisObject(this, "Function.prototype.apply")
+		var applyArgs []Value
+		if argsList := argument(args, 1); !argsList.IsUndefined() && !argsList.IsNull() {
+			applyArgs = listFromArrayLike(argsList)
+		}
+		return fn.callFunc(argument(args, 0), applyArgs)
+	})
+	r.method(r.functionPrototype, "bind", 1, func(this Value, args []Value) Value {
+		fn := thisObject(this, "Function.prototype.bind")
+		if !fn.isCallable() {
+			throwTypeError("Bind must be called on a function")
+		}
+		return objectValue(r.bind(fn, argument(args, 0), tail(args)))
+	})
+	r.method(r.functionPrototype, "toString", 0, func(this Value, args []Value) Value {
+		fn := thisObject(this, "Function.prototype.toString")
+		return stringValue("function " + toString(fn.get("name")) + "() { [native code] }")
+	})
+}
+
+// tail returns args without the first argument
+func tail(args []Value) []Value {
+	if len(args) == 0 {
+		return nil
+	}
+	return args[1:]
+}
+
+func (r *globals) setupArray() {
+	r.arrayPrototype = newObject(r.objectPrototype)
+	r.arrayPrototype.class = "Array"
+	var arrayType *object
+	newArrayFrom := func(newTarget *object, args []Value) Value {
+		array := newArray(nil)
+		array.proto = prototypeFrom(newTarget, r.arrayPrototype)
+		if len(args) == 1 && args[0].Type() == TypeNumber {
+			array.setLength(args[0])
+		} else {
+			array.elements = append([]Value(nil), args...)
+		}
+		return objectValue(array)
+	}
+	arrayType = r.newConstructor("Array", 1, r.arrayPrototype, func(this Value, args []Value) Value {
+		return newArrayFrom(arrayType, args)
+	}, newArrayFrom)
+
+	r.method(arrayType, "isArray", 1, func(this Value, args []Value) Value {
+		obj, ok := argument(args, 0).ref.(*object)
+		return boolValue(ok && obj.isArray)
+	})
+	r.method(arrayType, "of", 0, func(this Value, args []Value) Value {
+		return objectValue(newArray(append([]Value(nil), args...)))
+	})
+	r.method(arrayType, "from", 1, func(this Value, args []Value) Value {
+		return objectValue(newArray(listFromArrayLike(argument(args, 0))))
+	})
+
+	thisArray := func(this Value, method string) *object {
+		obj, ok := this.ref.(*object)
+		if !ok || !obj.isArray {
+			throwTypeError("Array.prototype.%s called on non-array", method)
+		}
+		return obj
+	}
+	elements := func(array *object) []Value {
+		array.mu.Lock()
+		defer array.mu.Unlock()
+		return append([]Value(nil), array.elements...)
+	}
+	r.method(r.arrayPrototype, "push", 1, func(this Value, args []Value) Value {
+		array := thisArray(this, "push")
+		array.mu.Lock()
+		defer array.mu.Unlock()
+		array.elements = append(array.elements, args...)
+		return floatValue(float64(len(array.elements)))
+	})
+	r.method(r.arrayPrototype, "pop", 0, func(this Value, args []Value) Value {
+		array := thisArray(this, "pop")
+		array.mu.Lock()
+		defer array.mu.Unlock()
+		if len(array.elements) == 0 {
+			return Undefined()
+		}
+		last := array.elements[len(array.elements)-1]
+		array.elements = array.elements[:len(array.elements)-1]
+		return last
+	})
+	r.method(r.arrayPrototype, "shift", 0, func(this Value, args []Value) Value {
+		array := thisArray(this, "shift")
+		array.mu.Lock()
+		defer array.mu.Unlock()
+		if len(array.elements) == 0 {
+			return Undefined()
+		}
+		first := array.elements[0]
+		array.elements = append([]Value(nil), array.elements[1:]...)
+		return first
+	})
+	r.method(r.arrayPrototype, "indexOf", 1, func(this Value, args []Value) Value {
+		for i, element := range elements(thisArray(this, "indexOf")) {
+			if element.Equal(argument(args, 0)) {
+				return floatValue(float64(i))
+			}
+		}
+		return floatValue(-1)
+	})
+	r.method(r.arrayPrototype, "includes", 1, func(this Value, args []Value) Value {
+		search := argument(args, 0)
+		for _, element := range elements(thisArray(this, "includes")) {
+			if element.Equal(search) || (element.IsNaN() && search.IsNaN()) {
+				return boolValue(true)
+			}
+		}
+		return boolValue(false)
+	})
+	join := r.method(r.arrayPrototype, "join", 1, func(this Value, args []Value) Value {
+		separator := ","
+		if !argument(args, 0).IsUndefined() {
+			separator = toString(args[0])
+		}
+		var s []string
+		for _, element := range elements(thisArray(this, "join")) {
+			if element.IsUndefined() || element.IsNull() {
+				s = append(s, "")
+			} else {
+				s = append(s, toString(element))
+			}
+		}
+		return stringValue(strings.Join(s, separator))
+	})
+	r.arrayPrototype.set("toString", objectValue(join))
+	r.method(r.arrayPrototype, "slice", 2, func(this Value, args []Value) Value {
+		values := elements(thisArray(this, "slice"))
+		start := relativeIndex(argument(args, 0), len(values), 0)
+		end := relativeIndex(argument(args, 1), len(values), len(values))
+		if end < start {
+			end = start
+		}
+		return objectValue(newArray(append([]Value(nil), values[start:end]...)))
+	})
+	r.method(r.arrayPrototype, "forEach", 1, func(this Value, args []Value) Value {
+		callback := argumentObject(args, 0, "Array.prototype.forEach")
+		for i, element := range elements(thisArray(this, "forEach")) {
+			callback.callFunc(argument(args, 1), []Value{element, floatValue(float64(i)), this})
+		}
+		return Undefined()
+	})
+	r.method(r.arrayPrototype, "map", 1, func(this Value, args []Value) Value {
+		callback := argumentObject(args, 0, "Array.prototype.map")
+		values := elements(thisArray(this, "map"))
+		for i, element := range values {
+			values[i] = callback.callFunc(argument(args, 1), []Value{element, floatValue(float64(i)), this})
+		}
+		return objectValue(newArray(values))
+	})
+	values := r.method(r.arrayPrototype, "values", 0, func(this Value, args []Value) Value {
+		return objectValue(r.newIterator(elements(thisArray(this, "values"))))
+	})
+	r.arrayPrototype.setSymbol(r.wellKnownSymbols["iterator"], objectValue(values))
+}
+
+// newIterator returns an iterator over values, like the result of Array.prototype.values
+func (r *globals) newIterator(values []Value) *object {
+	iterator := newObject(r.objectPrototype)
+	iterator.class = "Array Iterator"
+	next := 0
+	var mu sync.Mutex
+	r.method(iterator, "next", 0, func(this Value, args []Value) Value {
+		mu.Lock()
+		defer mu.Unlock()
+		result := newObject(r.objectPrototype)
+		if next >= len(values) {
+			result.set("value", Undefined())
+			result.set("done", boolValue(true))
+		} else {
+			result.set("value", values[next])
+			result.set("done", boolValue(false))
+			next++
+		}
+		return objectValue(result)
+	})
+	return iterator
+}
+
+// wrapPrimitive returns a wrapper object for a primitive value, like new String("foo")
+func (r *globals) wrapPrimitive(value Value) *object {
+	constructorName := map[Type]string{
+		TypeBoolean: "Boolean",
+		TypeNumber:  "Number",
+		TypeString:  "String",
+		TypeSymbol:  "Symbol",
+	}[value.Type()]
+	constructor := r.global.get(constructorName).ref.(*object)
+	obj := newObject(prototypeFrom(constructor, r.objectPrototype))
+	obj.class = constructorName
+	obj.primitive = value
+	return obj
+}
+
+func (r *globals) setupPrimitiveWrappers() {
+	for _, wrapper := range []struct {
+		name    string
+		convert func(args []Value) Value
+	}{
+		{name: "Boolean", convert: func(args []Value) Value {
+			return boolValue(toBoolean(argument(args, 0)))
+		}},
+		{name: "Number", convert: func(args []Value) Value {
+			if len(args) == 0 {
+				return floatValue(0)
+			}
+			return floatValue(toNumber(args[0]))
+		}},
+		{name: "String", convert: func(args []Value) Value {
+			if len(args) == 0 {
+				return stringValue("")
+			}
+			if sym, ok := args[0].ref.(*symbol); ok {
+				return stringValue(sym.String())
+			}
+			return stringValue(toString(args[0]))
+		}},
+	} {
+		wrapper := wrapper
+		prototype := newObject(r.objectPrototype)
+		r.newConstructor(wrapper.name, 1, prototype, func(this Value, args []Value) Value {
+			return wrapper.convert(args)
+		}, func(newTarget *object, args []Value) Value {
+			obj := r.wrapPrimitive(wrapper.convert(args))
+			obj.setPrototype(prototypeFrom(newTarget, prototype))
+			return objectValue(obj)
+		})
+		thisPrimitive := func(this Value) Value {
+			if this.Type().String() == strings.ToLower(wrapper.name) {
+				return this
+			}
+			if obj, ok := this.ref.(*object); ok && obj.class == wrapper.name {
+				return obj.primitive
+			}
+			throwTypeError("%s.prototype.valueOf requires that 'this' be a %s", wrapper.name, wrapper.name)
+			return Undefined()
+		}
+		r.method(prototype, "valueOf", 0, func(this Value, args []Value) Value {
+			return thisPrimitive(this)
+		})
+		r.method(prototype, "toString", 0, func(this Value, args []Value) Value {
+			return stringValue(toString(thisPrimitive(this)))
+		})
+	}
+
+	numberType := r.global.get("Number").ref.(*object)
+	numberType.set("NaN", floatValue(math.NaN()))
+	numberType.set("POSITIVE_INFINITY", floatValue(math.Inf(1)))
+	numberType.set("NEGATIVE_INFINITY", floatValue(math.Inf(-1)))
+	numberType.set("MAX_SAFE_INTEGER", floatValue(1<<53-1))
+	numberType.set("MIN_SAFE_INTEGER", floatValue(-(1<<53 - 1)))
+	numberType.set("EPSILON", floatValue(math.Nextafter(1, 2)-1))
+	r.method(numberType, "isNaN", 1, func(this Value, args []Value) Value {
+		return boolValue(argument(args, 0).IsNaN())
+	})
+	r.method(numberType, "isFinite", 1, func(this Value, args []Value) Value {
+		f, ok := argument(args, 0).ref.(float64)
+		return boolValue(ok && !math.IsNaN(f) && !math.IsInf(f, 0))
+	})
+	r.method(numberType, "isInteger", 1, func(this Value, args []Value) Value {
+		f, ok := argument(args, 0).ref.(float64)
+		return boolValue(ok && !math.IsInf(f, 0) && f == math.Trunc(f))
+	})
+}
+
+func (r *globals) setupErrors() {
+	r.errorPrototype = r.setupError("Error", r.objectPrototype)
+	r.typeErrorPrototype = r.setupError("TypeError", r.errorPrototype)
+	r.rangeErrorPrototype = r.setupError("RangeError", r.errorPrototype)
+	r.setupError("SyntaxError", r.errorPrototype)
+	r.setupError("ReferenceError", r.errorPrototype)
+
+	r.method(r.errorPrototype, "toString", 0, func(this Value, args []Value) Value {
+		obj := thisObject(this, "Error.prototype.toString")
+		name, message := toString(obj.get("name")), toString(obj.get("message"))
+		switch {
+		case message == "":
+			return stringValue(name)
+		case name == "":
+			return stringValue(message)
+		default:
+			return stringValue(name + ": " + message)
+		}
+	})
+}
+
+// setupError creates the global error constructor name, returning its prototype
+func (r *globals) setupError(name string, parentPrototype *object) *object {
+	prototype := newObject(parentPrototype)
+	prototype.set("name", stringValue(name))
+	prototype.set("message", stringValue(""))
+	var constructor *object
+	newErrorFrom := func(newTarget *object, args []Value) Value {
+		obj := newObject(prototypeFrom(newTarget, prototype))
+		obj.class = "Error"
+		if message := argument(args, 0); !message.IsUndefined() {
+			obj.set("message", stringValue(toString(message)))
+		}
+		return objectValue(obj)
+	}
+	constructor = r.newConstructor(name, 1, prototype, func(this Value, args []Value) Value {
+		return newErrorFrom(constructor, args)
+	}, newErrorFrom)
+	return prototype
+}
+
+func (r *globals) setupReflect() {
+	reflect := newObject(r.objectPrototype)
+	reflect.class = "Reflect"
+	r.global.set("Reflect", objectValue(reflect))
+
+	r.method(reflect, "apply", 3, func(this Value, args []Value) Value {
+		return argumentObject(args, 0, "Reflect.apply").callFunc(argument(args, 1), listFromArrayLike(argument(args, 2)))
+	})
+	r.method(reflect, "construct", 2, func(this Value, args []Value) Value {
+		return construct(argumentObject(args, 0, "Reflect.construct"), listFromArrayLike(argument(args, 1)))
+	})
+	r.method(reflect, "get", 2, func(this Value, args []Value) Value {
+		return argumentObject(args, 0, "Reflect.get").getProperty(argument(args, 1))
+	})
+	r.method(reflect, "set", 3, func(this Value, args []Value) Value {
+		return boolValue(argumentObject(args, 0, "Reflect.set").setProperty(argument(args, 1), argument(args, 2)))
+	})
+	r.method(reflect, "has", 2, func(this Value, args []Value) Value {
+		return boolValue(argumentObject(args, 0, "Reflect.has").hasProperty(argument(args, 1)))
+	})
+	r.method(reflect, "deleteProperty", 2, func(this Value, args []Value) Value {
+		return boolValue(argumentObject(args, 0, "Reflect.deleteProperty").deleteProperty(argument(args, 1)))
+	})
+	r.method(reflect, "getPrototypeOf", 1, func(this Value, args []Value) Value {
+		return prototypeValue(argumentObject(args, 0, "Reflect.getPrototypeOf"))
+	})
+	r.method(reflect, "ownKeys", 1, func(this Value, args []Value) Value {
+		var keys []Value
+		for _, key := range argumentObject(args, 0, "Reflect.ownKeys").ownKeys() {
+			keys = append(keys, stringValue(key))
+		}
+		return objectValue(newArray(keys))
+	})
+}
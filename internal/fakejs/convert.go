@@ -0,0 +1,214 @@
+package fakejs
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// toBoolean converts v to a boolean, like JavaScript's Boolean(v)
+func toBoolean(v Value) bool {
+	switch ref := v.ref.(type) {
+	case nil, null:
+		return false
+	case bool:
+		return ref
+	case float64:
+		return ref != 0 && !math.IsNaN(ref)
+	case string:
+		return ref != ""
+	default:
+		return true
+	}
+}
+
+// toNumber converts v to a number, like JavaScript's Number(v). Throws a TypeError for Symbols.
+func toNumber(v Value) float64 {
+	switch ref := v.ref.(type) {
+	case nil:
+		return math.NaN()
+	case null:
+		return 0
+	case bool:
+		if ref {
+			return 1
+		}
+		return 0
+	case float64:
+		return ref
+	case string:
+		return stringToNumber(ref)
+	case *symbol:
+		throwTypeError("Cannot convert a Symbol value to a number")
+		return 0
+	default:
+		return toNumber(toPrimitive(v))
+	}
+}
+
+// radixPrefixes are the prefixes of JavaScript's non-decimal integer literals, and their bases
+var radixPrefixes = map[string]int{"0x": 16, "0o": 8, "0b": 2}
+
+func stringToNumber(s string) float64 {
+	s = strings.TrimSpace(s)
+	switch s {
+	case "":
+		return 0
+	case "Infinity", "+Infinity":
+		return math.Inf(1)
+	case "-Infinity":
+		return math.Inf(-1)
+	}
+	if len(s) > 2 {
+		if base, ok := radixPrefixes[strings.ToLower(s[:2])]; ok {
+			i, err := strconv.ParseUint(s[2:], base, 64)
+			if err != nil {
+				return math.NaN()
+			}
+			return float64(i)
+		}
+	}
+	if strings.ContainsAny(s, "_xXpP") || strings.EqualFold(s, "inf") || strings.EqualFold(s, "nan") {
+		return math.NaN() // valid for Go, but not JavaScript
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return math.NaN()
+	}
+	return f
+}
+
+// toString converts v to a string, like JavaScript's String(v) except it throws a TypeError for Symbols
+func toString(v Value) string {
+	switch ref := v.ref.(type) {
+	case nil:
+		return "undefined"
+	case null:
+		return "null"
+	case bool:
+		return strconv.FormatBool(ref)
+	case float64:
+		return numberToString(ref)
+	case string:
+		return ref
+	case *symbol:
+		throwTypeError("Cannot convert a Symbol value to a string")
+		return ""
+	default:
+		return toString(toPrimitive(v))
+	}
+}
+
+// numberToString formats f like JavaScript's Number.prototype.toString()
+func numberToString(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "NaN"
+	case math.IsInf(f, 1):
+		return "Infinity"
+	case math.IsInf(f, -1):
+		return "-Infinity"
+	case f == 0:
+		return "0"
+	}
+	abs := math.Abs(f)
+	if abs >= 1e21 || abs < 1e-6 {
+		// JavaScript uses exponents without leading zeros, like 1e+21 and 1e-7
+		s := strconv.FormatFloat(f, 'e', -1, 64)
+		mantissa, exponent, _ := strings.Cut(s, "e")
+		sign, digits := exponent[:1], strings.TrimLeft(exponent[1:], "0")
+		return mantissa + "e" + sign + digits
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// toPrimitive converts an object to a primitive value, like JavaScript's default string conversion
+func toPrimitive(v Value) Value {
+	obj, ok := v.ref.(*object)
+	if !ok {
+		return v
+	}
+	if obj.primitive.ref != nil {
+		return obj.primitive
+	}
+	for _, method := range []string{"toString", "valueOf"} {
+		if fn, ok := obj.get(method).ref.(*object); ok && fn.isCallable() {
+			result := fn.callFunc(v, nil)
+			if _, isObject := result.ref.(*object); !isObject {
+				return result
+			}
+		}
+	}
+	throwTypeError("Cannot convert object to primitive value")
+	return Undefined()
+}
+
+// toIntegerOrInfinity converts v to an integer, like JavaScript's built-in functions do for integer arguments
+func toIntegerOrInfinity(v Value) float64 {
+	f := toNumber(v)
+	if math.IsNaN(f) {
+		return 0
+	}
+	return math.Trunc(f)
+}
+
+// relativeIndex converts v to an index from 0 to length, where negative values count back from length, like the arguments of Array.prototype.slice
+func relativeIndex(v Value, length int, defaultIndex int) int {
+	if v.IsUndefined() {
+		return defaultIndex
+	}
+	i := toIntegerOrInfinity(v)
+	if i < 0 {
+		i += float64(length)
+	}
+	return int(math.Max(0, math.Min(i, float64(length))))
+}
+
+// describe returns a short description of v for error messages
+func describe(v Value) string {
+	switch ref := v.ref.(type) {
+	case string:
+		return strconv.Quote(ref)
+	case *symbol:
+		return ref.String()
+	case *object:
+		if ref.isCallable() {
+			if name := ref.get("name"); name.Type() == TypeString && name.ref != "" {
+				return name.ref.(string)
+			}
+			return "function"
+		}
+		return "#<" + ref.class + ">"
+	default:
+		return toString(v)
+	}
+}
+
+// argument returns args[i], or undefined if there are too few arguments
+func argument(args []Value, i int) Value {
+	if i < len(args) {
+		return args[i]
+	}
+	return Undefined()
+}
+
+func throwError(format string, args ...any) {
+	panic(Error{Value: objectValue(newError(realm().errorPrototype, fmt.Sprintf(format, args...)))})
+}
+
+func throwTypeError(format string, args ...any) {
+	panic(Error{Value: objectValue(newError(realm().typeErrorPrototype, fmt.Sprintf(format, args...)))})
+}
+
+func throwRangeError(format string, args ...any) {
+	panic(Error{Value: objectValue(newError(realm().rangeErrorPrototype, fmt.Sprintf(format, args...)))})
+}
+
+// newError returns a new error object with the given prototype, like Error.prototype or TypeError.prototype
+func newError(proto *object, message string) *object {
+	obj := newObject(proto)
+	obj.class = "Error"
+	obj.set("message", stringValue(message))
+	return obj
+}
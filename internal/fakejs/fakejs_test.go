@@ -0,0 +1,287 @@
+package fakejs
+
+import (
+	"math"
+	"testing"
+
+	"github.com/hack-pad/safejs/internal/assert"
+)
+
+// catchPanic runs fn and returns the value it panicked with, if any
+func catchPanic(fn func()) (recovered any) {
+	defer func() {
+		recovered = recover()
+	}()
+	fn()
+	return nil
+}
+
+// thrownMessage runs fn and returns the message of the JavaScript error it throws
+func thrownMessage(t *testing.T, fn func()) string {
+	t.Helper()
+	jsErr, ok := catchPanic(fn).(Error)
+	if !ok {
+		t.Fatal("Expected a JavaScript error to be thrown")
+	}
+	return jsErr.Error()
+}
+
+func TestValueOf(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		value        any
+		expectType   Type
+		expectString string
+	}{
+		{value: nil, expectType: TypeNull, expectString: "<null>"},
+		{value: true, expectType: TypeBoolean, expectString: "<boolean: true>"},
+		{value: 1, expectType: TypeNumber, expectString: "<number: 1>"},
+		{value: 1.5, expectType: TypeNumber, expectString: "<number: 1.5>"},
+		{value: "foo", expectType: TypeString, expectString: "foo"},
+		{value: []any{1, "a"}, expectType: TypeObject, expectString: "<object>"},
+		{value: map[string]any{"a": 1}, expectType: TypeObject, expectString: "<object>"},
+		{value: Undefined(), expectType: TypeUndefined, expectString: "<undefined>"},
+	} {
+		tc := tc
+		t.Run(tc.expectString, func(t *testing.T) {
+			t.Parallel()
+			value := ValueOf(tc.value)
+			assert.Equal(t, tc.expectType, value.Type())
+			assert.Equal(t, tc.expectString, value.String())
+		})
+	}
+
+	t.Run("invalid value", func(t *testing.T) {
+		t.Parallel()
+		assert.Equal(t, "ValueOf: invalid value", catchPanic(func() {
+			ValueOf(struct{}{})
+		}))
+	})
+}
+
+func TestNumberToString(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		value  float64
+		expect string
+	}{
+		{value: 0, expect: "0"},
+		{value: -1.25, expect: "-1.25"},
+		{value: 1e21, expect: "1e+21"},
+		{value: 1e-7, expect: "1e-7"},
+		{value: math.NaN(), expect: "NaN"},
+		{value: math.Inf(-1), expect: "-Infinity"},
+	} {
+		tc := tc
+		t.Run(tc.expect, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.expect, numberToString(tc.value))
+		})
+	}
+}
+
+func TestStringToNumber(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		value  string
+		expect float64
+	}{
+		{value: "", expect: 0},
+		{value: " 12 ", expect: 12},
+		{value: "0x1f", expect: 31},
+		{value: "0b101", expect: 5},
+		{value: "-Infinity", expect: math.Inf(-1)},
+	} {
+		tc := tc
+		t.Run(tc.value, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.expect, stringToNumber(tc.value))
+		})
+	}
+
+	for _, value := range []string{"1_000", "inf", "0x", "abc"} {
+		value := value
+		t.Run(value, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, true, math.IsNaN(stringToNumber(value)))
+		})
+	}
+}
+
+func TestObjectProperties(t *testing.T) {
+	t.Parallel()
+	obj := Global().Get("Object").New()
+	obj.Set("a", 1)
+	assert.Equal(t, 1, obj.Get("a").Int())
+	assert.Equal(t, true, obj.Call("hasOwnProperty", "a").Bool())
+	obj.Delete("a")
+	assert.Equal(t, true, obj.Get("a").IsUndefined())
+	assert.Equal(t, "[object Object]", obj.Call("toString").String())
+
+	keys := Global().Get("Object").Call("keys", map[string]any{"b": 2, "a": 1})
+	assert.Equal(t, 2, keys.Length())
+}
+
+func TestObjectFreeze(t *testing.T) {
+	t.Parallel()
+	reflect := Global().Get("Reflect")
+	obj := ValueOf(map[string]any{"a": 1})
+	Global().Get("Object").Call("freeze", obj)
+	assert.Equal(t, true, Global().Get("Object").Call("isFrozen", obj).Bool())
+
+	obj.Set("a", 2)
+	assert.Equal(t, 1, obj.Get("a").Int())
+	assert.Equal(t, false, reflect.Call("set", obj, "b", 1).Bool())
+	assert.Equal(t, false, reflect.Call("deleteProperty", obj, "a").Bool())
+	assert.Equal(t, true, reflect.Call("deleteProperty", obj, "missing").Bool())
+	assert.Equal(t, true, obj.Get("b").IsUndefined())
+}
+
+func TestObjectPrototypeChain(t *testing.T) {
+	t.Parallel()
+	proto := ValueOf(map[string]any{"greeting": "hi"})
+	obj := Global().Get("Object").Call("create", proto)
+	assert.Equal(t, "hi", obj.Get("greeting").String())
+	assert.Equal(t, true, Global().Get("Object").Call("getPrototypeOf", obj).Equal(proto))
+}
+
+func TestArray(t *testing.T) {
+	t.Parallel()
+	array := ValueOf([]any{1, 2})
+	array.Call("push", 3)
+	assert.Equal(t, 3, array.Length())
+	assert.Equal(t, 3, array.Index(2).Int())
+	assert.Equal(t, "1,2,3", array.Call("join").String())
+	assert.Equal(t, true, array.InstanceOf(Global().Get("Array")))
+	assert.Equal(t, true, Global().Get("Array").Call("isArray", array).Bool())
+
+	array.SetIndex(4, "e")
+	assert.Equal(t, 5, array.Length())
+	assert.Equal(t, true, array.Index(3).IsUndefined())
+
+	array.Set("length", 1)
+	assert.Equal(t, "1", array.Call("toString").String())
+
+	assert.Equal(t, "JavaScript error: Invalid array length", thrownMessage(t, func() {
+		array.Set("length", -1)
+	}))
+}
+
+func TestFuncOf(t *testing.T) {
+	t.Parallel()
+	fn := FuncOf(func(this Value, args []Value) any {
+		return len(args)
+	})
+	defer fn.Release()
+	assert.Equal(t, TypeFunction, fn.Type())
+	assert.Equal(t, 2, fn.Invoke(1, 2).Int())
+	assert.Equal(t, 1, fn.Call("call", nil, "a").Int())
+	assert.Equal(t, 3, fn.Call("apply", nil, []any{1, 2, 3}).Int())
+	assert.Equal(t, 3, fn.Call("bind", nil, 1, 2).Invoke(3).Int())
+}
+
+func TestFuncOfConstructor(t *testing.T) {
+	t.Parallel()
+	constructor := FuncOf(func(this Value, args []Value) any {
+		this.Set("name", args[0])
+		return nil
+	})
+	defer constructor.Release()
+	instance := constructor.New("foo")
+	assert.Equal(t, "foo", instance.Get("name").String())
+	assert.Equal(t, true, instance.InstanceOf(constructor.Value))
+	assert.Equal(t, true, instance.InstanceOf(Global().Get("Object")))
+	assert.Equal(t, false, instance.InstanceOf(Global().Get("Array")))
+}
+
+func TestFuncRelease(t *testing.T) {
+	t.Parallel()
+	fn := FuncOf(func(this Value, args []Value) any {
+		return nil
+	})
+	fn.Release()
+	assert.Equal(t, "JavaScript error: call to released function", thrownMessage(t, func() {
+		fn.Invoke()
+	}))
+}
+
+func TestThrownErrors(t *testing.T) {
+	t.Parallel()
+	t.Run("call non-function property", func(t *testing.T) {
+		t.Parallel()
+		assert.Equal(t, "syscall/js: Value.Call: property foo is not a function, got undefined", catchPanic(func() {
+			ValueOf(map[string]any{}).Call("foo")
+		}))
+	})
+
+	t.Run("new non-constructor", func(t *testing.T) {
+		t.Parallel()
+		err := catchPanic(func() {
+			Global().Get("Symbol").New()
+		})
+		jsErr, ok := err.(Error)
+		assert.Equal(t, true, ok)
+		assert.Equal(t, true, jsErr.InstanceOf(Global().Get("TypeError")))
+		assert.Equal(t, "JavaScript error: Symbol is not a constructor", jsErr.Error())
+	})
+
+	t.Run("thrown from Go callback", func(t *testing.T) {
+		t.Parallel()
+		fn := FuncOf(func(this Value, args []Value) any {
+			panic(Error{Value: Global().Get("RangeError").New("boom")})
+		})
+		defer fn.Release()
+		assert.Equal(t, "JavaScript error: boom", thrownMessage(t, func() {
+			fn.Invoke()
+		}))
+	})
+
+	t.Run("value error", func(t *testing.T) {
+		t.Parallel()
+		err := catchPanic(func() {
+			ValueOf("foo").Int()
+		})
+		valueErr, ok := err.(*ValueError)
+		assert.Equal(t, true, ok)
+		assert.Equal(t, "syscall/js: call of Value.Int on string", valueErr.Error())
+	})
+}
+
+func TestSymbol(t *testing.T) {
+	t.Parallel()
+	symbolType := Global().Get("Symbol")
+	sym := symbolType.Invoke("foo")
+	assert.Equal(t, TypeSymbol, sym.Type())
+	assert.Equal(t, "Symbol(foo)", symbolType.Get("prototype").Get("toString").Call("call", sym).String())
+	assert.Equal(t, false, sym.Equal(symbolType.Invoke("foo")))
+
+	registered := symbolType.Call("for", "bar")
+	assert.Equal(t, true, registered.Equal(symbolType.Call("for", "bar")))
+	assert.Equal(t, "bar", symbolType.Call("keyFor", registered).String())
+	assert.Equal(t, true, symbolType.Call("keyFor", sym).IsUndefined())
+}
+
+func TestTypedArrays(t *testing.T) {
+	t.Parallel()
+	array := Global().Get("Uint8Array").New(4)
+	assert.Equal(t, 4, CopyBytesToJS(array, []byte{1, 2, 3, 4, 5}))
+	assert.Equal(t, 4, array.Get("buffer").Get("byteLength").Int())
+
+	view := Global().Get("DataView").New(array.Get("buffer"))
+	assert.Equal(t, 0x0403, view.Call("getUint16", 2, true).Int())
+	view.Call("setUint8", 0, 300)
+	assert.Equal(t, 44, array.Index(0).Int())
+
+	clamped := Global().Get("Uint8ClampedArray").New([]any{300, -1})
+	dst := make([]byte, 2)
+	assert.Equal(t, 2, CopyBytesToGo(dst, clamped))
+	assert.Equal(t, []byte{255, 0}, dst)
+
+	floats := Global().Get("Float64Array").New(array.Call("subarray", 1, 3))
+	assert.Equal(t, 2, floats.Length())
+	assert.Equal(t, 2.0, floats.Index(0).Float())
+
+	assert.Equal(t, "syscall/js: CopyBytesToGo: expected src to be a Uint8Array or Uint8ClampedArray", catchPanic(func() {
+		CopyBytesToGo(dst, floats)
+	}))
+}
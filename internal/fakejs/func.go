@@ -0,0 +1,111 @@
+package fakejs
+
+import (
+	"sync/atomic"
+)
+
+// Func is a wrapped Go function to be called by JavaScript.
+type Func struct {
+	// Value is the JavaScript function that calls the Go function.
+	Value
+	released *int32
+}
+
+// FuncOf returns a function to be used by JavaScript. See [syscall/js.FuncOf] for details.
+//
+// Like functions created by JavaScript's "function" keyword, it may be called with "new" too.
+// Calling the function after Release throws an Error.
+func FuncOf(fn func(this Value, args []Value) any) Func {
+	released := new(int32)
+	r := realm()
+	obj := r.newFunction("", 0, func(this Value, args []Value) Value {
+		if atomic.LoadInt32(released) != 0 {
+			throwError("call to released function")
+		}
+		return ValueOf(fn(this, args))
+	})
+	obj.fn.constructor = true
+	addPrototype(obj, newObject(r.objectPrototype))
+	return Func{Value: objectValue(obj), released: released}
+}
+
+// Release frees up resources allocated for the function. The function must not be invoked after calling Release.
+func (c Func) Release() {
+	if c.released != nil {
+		atomic.StoreInt32(c.released, 1)
+	}
+}
+
+// function is the callable part of a function object
+type function struct {
+	call func(this Value, args []Value) Value
+	// constructor is true if the function may be called with "new"
+	constructor bool
+	// construct creates new objects for built-in constructors, like Array, instead of calling call with a new object.
+	// The new object's prototype should come from newTarget's "prototype" property.
+	construct func(newTarget *object, args []Value) Value
+	// boundTarget and boundArgs are set for functions returned by Function.prototype.bind
+	boundTarget *object
+	boundArgs   []Value
+}
+
+func (r *globals) newFunction(name string, length int, call func(this Value, args []Value) Value) *object {
+	obj := newObject(r.functionPrototype)
+	obj.class = "Function"
+	obj.fn = &function{call: call}
+	obj.set("name", stringValue(name))
+	obj.set("length", floatValue(float64(length)))
+	return obj
+}
+
+// addPrototype sets constructor's "prototype" property to prototype, and prototype's "constructor" property to constructor
+func addPrototype(constructor, prototype *object) {
+	constructor.set("prototype", objectValue(prototype))
+	prototype.set("constructor", objectValue(constructor))
+}
+
+// callFunc calls function o with the given this value and arguments. Throws a TypeError if o is not a function.
+func (o *object) callFunc(this Value, args []Value) Value {
+	if !o.isCallable() {
+		throwTypeError("%s is not a function", describe(objectValue(o)))
+	}
+	return o.fn.call(this, args)
+}
+
+// construct calls fn as a constructor, like JavaScript's "new" operator
+func construct(fn *object, args []Value) Value {
+	if !fn.isCallable() || !fn.fn.constructor {
+		throwTypeError("%s is not a constructor", describe(objectValue(fn)))
+	}
+	if target := fn.fn.boundTarget; target != nil {
+		return construct(target, append(append([]Value(nil), fn.fn.boundArgs...), args...))
+	}
+	if fn.fn.construct != nil {
+		return fn.fn.construct(fn, args)
+	}
+	obj := newObject(prototypeFrom(fn, realm().objectPrototype))
+	result := fn.fn.call(objectValue(obj), args)
+	if _, isObject := result.ref.(*object); isObject {
+		return result
+	}
+	return objectValue(obj)
+}
+
+// prototypeFrom returns constructor's "prototype" property, or fallback if it isn't an object
+func prototypeFrom(constructor *object, fallback *object) *object {
+	if proto, ok := constructor.get("prototype").ref.(*object); ok {
+		return proto
+	}
+	return fallback
+}
+
+// bind returns a new function which calls target with the given this value and leading arguments, like Function.prototype.bind
+func (r *globals) bind(target *object, this Value, args []Value) *object {
+	bound := r.newFunction("bound "+toString(target.get("name")), 0, func(_ Value, callArgs []Value) Value {
+		return target.callFunc(this, append(append([]Value(nil), args...), callArgs...))
+	})
+	bound.fn.constructor = target.fn.constructor
+	bound.fn.boundTarget = target
+	bound.fn.boundArgs = args
+	return bound
+}
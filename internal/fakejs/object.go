@@ -0,0 +1,342 @@
+package fakejs
+
+import (
+	"strconv"
+	"sync"
+)
+
+// object is a JavaScript object, including functions, arrays, and other built-in objects with special behavior
+type object struct {
+	mu      sync.Mutex
+	proto   *object
+	class   string // like "Object" or "Array", used in descriptions like "[object Array]"
+	props   map[string]Value
+	keys    []string // props keys in insertion order
+	symbols map[*symbol]Value
+
+	// fn is set for functions
+	fn *function
+	// isArray is true for arrays, which store their indexes and length in elements
+	isArray  bool
+	elements []Value
+	// primitive is set for wrapper objects like new String("foo")
+	primitive Value
+	// buffer is set for ArrayBuffers and SharedArrayBuffers
+	buffer *buffer
+	// view is set for DataViews and typed arrays
+	view *view
+	// frozen is set by Object.freeze, after which properties can't be added, changed, or deleted
+	frozen bool
+}
+
+func newObject(proto *object) *object {
+	return &object{
+		proto: proto,
+		class: "Object",
+		props: make(map[string]Value),
+	}
+}
+
+func newArray(elements []Value) *object {
+	obj := newObject(realm().arrayPrototype)
+	obj.class = "Array"
+	obj.isArray = true
+	obj.elements = elements
+	return obj
+}
+
+// indexKey returns the property key for index i
+func indexKey(i int) string {
+	return strconv.Itoa(i)
+}
+
+// arrayIndex returns the index for key, if it's a canonical array index like "0" and not "00" or "-1"
+func arrayIndex(key string) (int, bool) {
+	i, err := strconv.Atoi(key)
+	if err != nil || i < 0 || strconv.Itoa(i) != key {
+		return 0, false
+	}
+	return i, true
+}
+
+func (o *object) isCallable() bool {
+	return o.fn != nil
+}
+
+// get returns property key of o or its prototype chain, or undefined if it's not found
+func (o *object) get(key string) Value {
+	for obj := o; obj != nil; obj = obj.prototype() {
+		if value, ok := obj.getOwn(key); ok {
+			return value
+		}
+	}
+	return Undefined()
+}
+
+func (o *object) prototype() *object {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.proto
+}
+
+func (o *object) setPrototype(proto *object) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.proto = proto
+}
+
+// getOwn returns o's own property key
+func (o *object) getOwn(key string) (Value, bool) {
+	if o.view != nil {
+		if value, ok := o.view.get(key); ok {
+			return value, true
+		}
+	}
+	if o.buffer != nil && key == "byteLength" {
+		return floatValue(float64(o.buffer.byteLength())), true
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.isArray {
+		if key == "length" {
+			return floatValue(float64(len(o.elements))), true
+		}
+		if i, ok := arrayIndex(key); ok {
+			if i < len(o.elements) {
+				return o.elements[i], true
+			}
+			return Undefined(), false
+		}
+	}
+	if s, ok := o.primitive.ref.(string); ok && key == "length" {
+		return floatValue(float64(len(s))), true
+	}
+	value, ok := o.props[key]
+	return value, ok
+}
+
+func (o *object) hasOwn(key string) bool {
+	_, ok := o.getOwn(key)
+	return ok
+}
+
+// set sets o's own property key to value. Throws a RangeError for invalid array lengths.
+// Does nothing if o is frozen, like assignments outside of strict mode.
+func (o *object) set(key string, value Value) {
+	if o.isFrozen() {
+		return
+	}
+	if o.view != nil && o.view.set(key, value) {
+		return
+	}
+	if o.isArray {
+		if key == "length" {
+			o.setLength(value)
+			return
+		}
+		if i, ok := arrayIndex(key); ok {
+			o.mu.Lock()
+			defer o.mu.Unlock()
+			for len(o.elements) <= i {
+				o.elements = append(o.elements, Undefined())
+			}
+			o.elements[i] = value
+			return
+		}
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if _, exists := o.props[key]; !exists {
+		o.keys = append(o.keys, key)
+	}
+	o.props[key] = value
+}
+
+func (o *object) setLength(value Value) {
+	length := toNumber(value)
+	if length < 0 || length != float64(uint32(length)) {
+		throwRangeError("Invalid array length")
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	newLength := int(length)
+	if newLength < len(o.elements) {
+		o.elements = o.elements[:newLength]
+	}
+	for len(o.elements) < newLength {
+		o.elements = append(o.elements, Undefined())
+	}
+}
+
+// delete deletes o's own property key. Does nothing if o is frozen.
+func (o *object) delete(key string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.frozen {
+		return
+	}
+	if i, ok := arrayIndex(key); ok && o.isArray {
+		if i < len(o.elements) {
+			o.elements[i] = Undefined()
+		}
+		return
+	}
+	if _, exists := o.props[key]; !exists {
+		return
+	}
+	delete(o.props, key)
+	for i, k := range o.keys {
+		if k == key {
+			o.keys = append(o.keys[:i:i], o.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// ownKeys returns o's own string keys, with array indexes first
+func (o *object) ownKeys() []string {
+	var keys []string
+	if o.view != nil && o.view.kind != nil {
+		for i := 0; i < o.view.length; i++ {
+			keys = append(keys, indexKey(i))
+		}
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for i := range o.elements {
+		keys = append(keys, indexKey(i))
+	}
+	return append(keys, o.keys...)
+}
+
+// getSymbol returns the property keyed by sym of o or its prototype chain, or undefined if it's not found
+func (o *object) getSymbol(sym *symbol) Value {
+	for obj := o; obj != nil; obj = obj.prototype() {
+		obj.mu.Lock()
+		value, ok := obj.symbols[sym]
+		obj.mu.Unlock()
+		if ok {
+			return value
+		}
+	}
+	return Undefined()
+}
+
+func (o *object) setSymbol(sym *symbol, value Value) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.frozen {
+		return
+	}
+	if o.symbols == nil {
+		o.symbols = make(map[*symbol]Value)
+	}
+	o.symbols[sym] = value
+}
+
+func (o *object) deleteSymbol(sym *symbol) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.frozen {
+		return
+	}
+	delete(o.symbols, sym)
+}
+
+// getProperty returns the property of o keyed by a string or Symbol value
+func (o *object) getProperty(key Value) Value {
+	if sym, ok := key.ref.(*symbol); ok {
+		return o.getSymbol(sym)
+	}
+	return o.get(toString(key))
+}
+
+// setProperty sets the property of o keyed by a string or Symbol value, like Reflect.set. Returns false if o is frozen.
+func (o *object) setProperty(key, value Value) bool {
+	if o.isFrozen() {
+		return false
+	}
+	if sym, ok := key.ref.(*symbol); ok {
+		o.setSymbol(sym, value)
+		return true
+	}
+	o.set(toString(key), value)
+	return true
+}
+
+// deleteProperty deletes the property of o keyed by a string or Symbol value, like Reflect.deleteProperty.
+// Returns false if o is frozen and has the property.
+func (o *object) deleteProperty(key Value) bool {
+	sym, isSymbol := key.ref.(*symbol)
+	if o.isFrozen() {
+		if isSymbol {
+			o.mu.Lock()
+			_, found := o.symbols[sym]
+			o.mu.Unlock()
+			return !found
+		}
+		return !o.hasOwn(toString(key))
+	}
+	if isSymbol {
+		o.deleteSymbol(sym)
+		return true
+	}
+	o.delete(toString(key))
+	return true
+}
+
+func (o *object) isFrozen() bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.frozen
+}
+
+func (o *object) freeze() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.frozen = true
+}
+
+func (o *object) hasProperty(key Value) bool {
+	for obj := o; obj != nil; obj = obj.prototype() {
+		if sym, ok := key.ref.(*symbol); ok {
+			obj.mu.Lock()
+			_, found := obj.symbols[sym]
+			obj.mu.Unlock()
+			if found {
+				return true
+			}
+		} else if obj.hasOwn(toString(key)) {
+			return true
+		}
+	}
+	return false
+}
+
+// instanceOf reports whether v is an instance of t, like JavaScript's instanceof operator
+func instanceOf(v, t Value) bool {
+	constructor, ok := t.ref.(*object)
+	if !ok {
+		throwTypeError("Right-hand side of 'instanceof' is not an object")
+	}
+	if !constructor.isCallable() {
+		throwTypeError("Right-hand side of 'instanceof' is not callable")
+	}
+	if target := constructor.fn.boundTarget; target != nil {
+		return instanceOf(v, objectValue(target))
+	}
+	prototype, ok := constructor.get("prototype").ref.(*object)
+	if !ok {
+		throwTypeError("Function has non-object prototype in instanceof check")
+	}
+	obj, ok := v.ref.(*object)
+	if !ok {
+		return false
+	}
+	for proto := obj.prototype(); proto != nil; proto = proto.prototype() {
+		if proto == prototype {
+			return true
+		}
+	}
+	return false
+}
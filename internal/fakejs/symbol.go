@@ -0,0 +1,97 @@
+package fakejs
+
+import "sync"
+
+// symbol is a JavaScript Symbol
+type symbol struct {
+	description string
+}
+
+func (s *symbol) String() string {
+	return "Symbol(" + s.description + ")"
+}
+
+// wellKnownSymbols are the names of JavaScript's built-in Symbols, like Symbol.iterator
+var wellKnownSymbols = []string{
+	"asyncIterator",
+	"hasInstance",
+	"isConcatSpreadable",
+	"iterator",
+	"match",
+	"matchAll",
+	"replace",
+	"search",
+	"species",
+	"split",
+	"toPrimitive",
+	"toStringTag",
+	"unscopables",
+}
+
+// symbolRegistry is the global Symbol registry used by Symbol.for
+type symbolRegistry struct {
+	mu      sync.Mutex
+	symbols map[string]*symbol
+}
+
+func (s *symbolRegistry) symbolFor(key string) *symbol {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.symbols == nil {
+		s.symbols = make(map[string]*symbol)
+	}
+	sym, ok := s.symbols[key]
+	if !ok {
+		sym = &symbol{description: key}
+		s.symbols[key] = sym
+	}
+	return sym
+}
+
+func (s *symbolRegistry) keyFor(sym *symbol) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, registered := range s.symbols {
+		if registered == sym {
+			return key, true
+		}
+	}
+	return "", false
+}
+
+func (r *globals) setupSymbol() {
+	symbolType := r.newConstructor("Symbol", 0, newObject(r.objectPrototype), func(this Value, args []Value) Value {
+		description := argument(args, 0)
+		if description.IsUndefined() {
+			return Value{ref: &symbol{}}
+		}
+		return Value{ref: &symbol{description: toString(description)}}
+	}, nil)
+	symbolType.fn.constructor = false
+	for _, name := range wellKnownSymbols {
+		sym := &symbol{description: "Symbol." + name}
+		r.wellKnownSymbols[name] = sym
+		symbolType.set(name, Value{ref: sym})
+	}
+	r.method(symbolType, "for", 1, func(this Value, args []Value) Value {
+		return Value{ref: r.symbolRegistry.symbolFor(toString(argument(args, 0)))}
+	})
+	r.method(symbolType, "keyFor", 1, func(this Value, args []Value) Value {
+		sym, ok := argument(args, 0).ref.(*symbol)
+		if !ok {
+			throwTypeError("%s is not a symbol", describe(argument(args, 0)))
+		}
+		if key, ok := r.symbolRegistry.keyFor(sym); ok {
+			return stringValue(key)
+		}
+		return Undefined()
+	})
+	symbolPrototype := prototypeFrom(symbolType, nil)
+	r.method(symbolPrototype, "toString", 0, func(this Value, args []Value) Value {
+		sym, ok := toPrimitive(this).ref.(*symbol)
+		if !ok {
+			throwTypeError("Symbol.prototype.toString requires that 'this' be a Symbol")
+		}
+		return stringValue(sym.String())
+	})
+}
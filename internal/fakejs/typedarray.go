@@ -0,0 +1,416 @@
+package fakejs
+
+import (
+	"encoding/binary"
+	"math"
+	"sync"
+)
+
+// buffer is the memory of an ArrayBuffer or SharedArrayBuffer
+type buffer struct {
+	mu   sync.Mutex
+	data []byte
+}
+
+func (b *buffer) byteLength() int {
+	return len(b.data) // fixed size, so no lock needed
+}
+
+// read copies the bytes of b starting at offset into dst
+func (b *buffer) read(dst []byte, offset int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	copy(dst, b.data[offset:])
+}
+
+// write copies src into the bytes of b starting at offset
+func (b *buffer) write(offset int, src []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	copy(b.data[offset:], src)
+}
+
+// elementKind describes the elements of a kind of typed array, like Float32Array
+type elementKind struct {
+	name string
+	size int
+	// decode and encode convert between little-endian bytes and JavaScript numbers
+	decode func(b []byte) float64
+	encode func(b []byte, f float64)
+}
+
+// typedArrayKinds are the supported typed arrays. BigInt64Array and BigUint64Array store and return numbers, since BigInts are not supported.
+var typedArrayKinds = []*elementKind{
+	{name: "Int8Array", size: 1,
+		decode: func(b []byte) float64 { return float64(int8(b[0])) },
+		encode: func(b []byte, f float64) { b[0] = byte(toInt32(f)) }},
+	{name: "Uint8Array", size: 1,
+		decode: func(b []byte) float64 { return float64(b[0]) },
+		encode: func(b []byte, f float64) { b[0] = byte(toInt32(f)) }},
+	{name: "Uint8ClampedArray", size: 1,
+		decode: func(b []byte) float64 { return float64(b[0]) },
+		encode: func(b []byte, f float64) { b[0] = clampUint8(f) }},
+	{name: "Int16Array", size: 2,
+		decode: func(b []byte) float64 { return float64(int16(binary.LittleEndian.Uint16(b))) },
+		encode: func(b []byte, f float64) { binary.LittleEndian.PutUint16(b, uint16(toInt32(f))) }},
+	{name: "Uint16Array", size: 2,
+		decode: func(b []byte) float64 { return float64(binary.LittleEndian.Uint16(b)) },
+		encode: func(b []byte, f float64) { binary.LittleEndian.PutUint16(b, uint16(toInt32(f))) }},
+	{name: "Int32Array", size: 4,
+		decode: func(b []byte) float64 { return float64(int32(binary.LittleEndian.Uint32(b))) },
+		encode: func(b []byte, f float64) { binary.LittleEndian.PutUint32(b, uint32(toInt32(f))) }},
+	{name: "Uint32Array", size: 4,
+		decode: func(b []byte) float64 { return float64(binary.LittleEndian.Uint32(b)) },
+		encode: func(b []byte, f float64) { binary.LittleEndian.PutUint32(b, uint32(toInt32(f))) }},
+	{name: "Float32Array", size: 4,
+		decode: func(b []byte) float64 { return float64(math.Float32frombits(binary.LittleEndian.Uint32(b))) },
+		encode: func(b []byte, f float64) { binary.LittleEndian.PutUint32(b, math.Float32bits(float32(f))) }},
+	{name: "Float64Array", size: 8,
+		decode: func(b []byte) float64 { return math.Float64frombits(binary.LittleEndian.Uint64(b)) },
+		encode: func(b []byte, f float64) { binary.LittleEndian.PutUint64(b, math.Float64bits(f)) }},
+	{name: "BigInt64Array", size: 8,
+		decode: func(b []byte) float64 { return float64(int64(binary.LittleEndian.Uint64(b))) },
+		encode: func(b []byte, f float64) { binary.LittleEndian.PutUint64(b, uint64(int64(f))) }},
+	{name: "BigUint64Array", size: 8,
+		decode: func(b []byte) float64 { return float64(binary.LittleEndian.Uint64(b)) },
+		encode: func(b []byte, f float64) { binary.LittleEndian.PutUint64(b, uint64(f)) }},
+}
+
+// toInt32 converts f to a 32-bit integer with wrap-around, like JavaScript's bitwise operators
+func toInt32(f float64) int32 {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0
+	}
+	return int32(uint32(int64(math.Mod(math.Trunc(f), 1<<32))))
+}
+
+// clampUint8 converts f to a byte like Uint8ClampedArray, rounding half to even
+func clampUint8(f float64) byte {
+	switch {
+	case math.IsNaN(f) || f <= 0:
+		return 0
+	case f >= 255:
+		return 255
+	default:
+		return byte(math.RoundToEven(f))
+	}
+}
+
+// view is a DataView or typed array over a buffer
+type view struct {
+	kind       *elementKind // nil for DataView
+	bufferObj  *object
+	data       *buffer
+	byteOffset int
+	// length is the number of elements, or bytes for a DataView
+	length int
+}
+
+func (v *view) byteLength() int {
+	if v.kind == nil {
+		return v.length
+	}
+	return v.length * v.kind.size
+}
+
+// get returns view properties and typed array elements
+func (v *view) get(key string) (Value, bool) {
+	switch key {
+	case "buffer":
+		return objectValue(v.bufferObj), true
+	case "byteOffset":
+		return floatValue(float64(v.byteOffset)), true
+	case "byteLength":
+		return floatValue(float64(v.byteLength())), true
+	}
+	if v.kind == nil {
+		return Undefined(), false
+	}
+	if key == "length" {
+		return floatValue(float64(v.length)), true
+	}
+	if i, ok := arrayIndex(key); ok {
+		if i >= v.length {
+			return Undefined(), true
+		}
+		b := make([]byte, v.kind.size)
+		v.data.read(b, v.byteOffset+i*v.kind.size)
+		return floatValue(v.kind.decode(b)), true
+	}
+	return Undefined(), false
+}
+
+// set sets typed array elements, returning true if key is an index. Out of range indexes are ignored.
+func (v *view) set(key string, value Value) bool {
+	if v.kind == nil {
+		return false
+	}
+	i, ok := arrayIndex(key)
+	if !ok {
+		return false
+	}
+	f := toNumber(value)
+	if i < v.length {
+		b := make([]byte, v.kind.size)
+		v.kind.encode(b, f)
+		v.data.write(v.byteOffset+i*v.kind.size, b)
+	}
+	return true
+}
+
+func (r *globals) newBuffer(prototype *object, class string, byteLength int) *object {
+	obj := newObject(prototype)
+	obj.class = class
+	obj.buffer = &buffer{data: make([]byte, byteLength)}
+	return obj
+}
+
+// byteLengthArgument converts v to a valid byte length or element count, or throws a RangeError
+func byteLengthArgument(v Value, message string) int {
+	length := toIntegerOrInfinity(v)
+	if length < 0 || length > math.MaxInt32 {
+		throwRangeError(message)
+	}
+	return int(length)
+}
+
+func (r *globals) setupBuffers() {
+	for _, class := range []string{"ArrayBuffer", "SharedArrayBuffer"} {
+		class := class
+		prototype := newObject(r.objectPrototype)
+		constructor := r.newConstructor(class, 1, prototype, func(this Value, args []Value) Value {
+			throwTypeError("Constructor %s requires 'new'", class)
+			return Undefined()
+		}, func(newTarget *object, args []Value) Value {
+			byteLength := byteLengthArgument(argument(args, 0), "Invalid array buffer length")
+			return objectValue(r.newBuffer(prototypeFrom(newTarget, prototype), class, byteLength))
+		})
+		r.method(prototype, "slice", 2, func(this Value, args []Value) Value {
+			obj := thisObject(this, class+".prototype.slice")
+			if obj.buffer == nil || obj.class != class {
+				throwTypeError("Method %s.prototype.slice called on incompatible receiver %s", class, describe(this))
+			}
+			length := obj.buffer.byteLength()
+			start := relativeIndex(argument(args, 0), length, 0)
+			end := relativeIndex(argument(args, 1), length, length)
+			if end < start {
+				end = start
+			}
+			sliced := r.newBuffer(prototypeFrom(constructor, prototype), class, end-start)
+			obj.buffer.read(sliced.buffer.data, start)
+			return objectValue(sliced)
+		})
+		if class == "ArrayBuffer" {
+			r.method(constructor, "isView", 1, func(this Value, args []Value) Value {
+				obj, ok := argument(args, 0).ref.(*object)
+				return boolValue(ok && obj.view != nil)
+			})
+		}
+	}
+
+	prototype := newObject(r.objectPrototype)
+	r.newConstructor("DataView", 1, prototype, func(this Value, args []Value) Value {
+		throwTypeError("Constructor DataView requires 'new'")
+		return Undefined()
+	}, func(newTarget *object, args []Value) Value {
+		bufferObj, ok := argument(args, 0).ref.(*object)
+		if !ok || bufferObj.buffer == nil {
+			throwTypeError("First argument to DataView constructor must be an ArrayBuffer")
+		}
+		bufferLength := bufferObj.buffer.byteLength()
+		byteOffset := byteLengthArgument(argument(args, 1), "Start offset is outside the bounds of the buffer")
+		if byteOffset > bufferLength {
+			throwRangeError("Start offset %d is outside the bounds of the buffer", byteOffset)
+		}
+		byteLength := bufferLength - byteOffset
+		if !argument(args, 2).IsUndefined() {
+			byteLength = byteLengthArgument(args[2], "Invalid DataView length")
+			if byteOffset+byteLength > bufferLength {
+				throwRangeError("Invalid DataView length %d", byteLength)
+			}
+		}
+		obj := newObject(prototypeFrom(newTarget, prototype))
+		obj.class = "DataView"
+		obj.view = &view{bufferObj: bufferObj, data: bufferObj.buffer, byteOffset: byteOffset, length: byteLength}
+		return objectValue(obj)
+	})
+	for _, kind := range typedArrayKinds {
+		if kind.name == "Uint8ClampedArray" {
+			continue
+		}
+		kind := kind
+		name := kind.name[:len(kind.name)-len("Array")]
+		r.method(prototype, "get"+name, 1, func(this Value, args []Value) Value {
+			view, offset := r.dataViewOffset(this, "get"+name, argument(args, 0), kind.size)
+			b := make([]byte, kind.size)
+			view.data.read(b, offset)
+			if !toBoolean(argument(args, 1)) {
+				reverseBytes(b)
+			}
+			return floatValue(kind.decode(b))
+		})
+		r.method(prototype, "set"+name, 2, func(this Value, args []Value) Value {
+			view, offset := r.dataViewOffset(this, "set"+name, argument(args, 0), kind.size)
+			b := make([]byte, kind.size)
+			kind.encode(b, toNumber(argument(args, 1)))
+			if !toBoolean(argument(args, 2)) {
+				reverseBytes(b)
+			}
+			view.data.write(offset, b)
+			return Undefined()
+		})
+	}
+}
+
+// dataViewOffset returns the DataView this and the buffer offset for reading size bytes at byteOffset, or throws if they're invalid
+func (r *globals) dataViewOffset(this Value, method string, byteOffset Value, size int) (*view, int) {
+	obj, ok := this.ref.(*object)
+	if !ok || obj.view == nil || obj.view.kind != nil {
+		throwTypeError("Method DataView.prototype.%s called on incompatible receiver %s", method, describe(this))
+	}
+	offset := byteLengthArgument(byteOffset, "Offset is outside the bounds of the DataView")
+	if offset+size > obj.view.length {
+		throwRangeError("Offset is outside the bounds of the DataView")
+	}
+	return obj.view, obj.view.byteOffset + offset
+}
+
+// reverseBytes converts between little-endian and big-endian byte order
+func reverseBytes(b []byte) {
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+}
+
+func (r *globals) setupTypedArrays() {
+	arrayBufferType := r.global.get("ArrayBuffer").ref.(*object)
+	for _, kind := range typedArrayKinds {
+		kind := kind
+		prototype := newObject(r.objectPrototype)
+		var constructor *object
+		constructor = r.newConstructor(kind.name, 3, prototype, func(this Value, args []Value) Value {
+			throwTypeError("Constructor %s requires 'new'", kind.name)
+			return Undefined()
+		}, func(newTarget *object, args []Value) Value {
+			return objectValue(r.newTypedArray(kind, prototypeFrom(newTarget, prototype), arrayBufferType, args))
+		})
+		constructor.set("BYTES_PER_ELEMENT", floatValue(float64(kind.size)))
+		prototype.set("BYTES_PER_ELEMENT", floatValue(float64(kind.size)))
+		r.method(prototype, "subarray", 2, func(this Value, args []Value) Value {
+			array := r.thisTypedArray(this, kind, "subarray")
+			start := relativeIndex(argument(args, 0), array.view.length, 0)
+			end := relativeIndex(argument(args, 1), array.view.length, array.view.length)
+			if end < start {
+				end = start
+			}
+			return objectValue(r.newTypedArray(kind, prototypeFrom(constructor, prototype), arrayBufferType, []Value{
+				objectValue(array.view.bufferObj),
+				floatValue(float64(array.view.byteOffset + start*kind.size)),
+				floatValue(float64(end - start)),
+			}))
+		})
+		r.method(prototype, "set", 1, func(this Value, args []Value) Value {
+			array := r.thisTypedArray(this, kind, "set")
+			values := listFromArrayLike(argument(args, 0))
+			offset := byteLengthArgument(argument(args, 1), "offset is out of bounds")
+			if offset+len(values) > array.view.length {
+				throwRangeError("offset is out of bounds")
+			}
+			for i, value := range values {
+				array.set(indexKey(offset+i), value)
+			}
+			return Undefined()
+		})
+		r.method(prototype, "fill", 1, func(this Value, args []Value) Value {
+			array := r.thisTypedArray(this, kind, "fill")
+			start := relativeIndex(argument(args, 1), array.view.length, 0)
+			end := relativeIndex(argument(args, 2), array.view.length, array.view.length)
+			for i := start; i < end; i++ {
+				array.set(indexKey(i), argument(args, 0))
+			}
+			return this
+		})
+	}
+}
+
+func (r *globals) thisTypedArray(this Value, kind *elementKind, method string) *object {
+	obj, ok := this.ref.(*object)
+	if !ok || obj.view == nil || obj.view.kind != kind {
+		throwTypeError("Method %s.prototype.%s called on incompatible receiver %s", kind.name, method, describe(this))
+	}
+	return obj
+}
+
+// newTypedArray creates a typed array from constructor arguments: a length, an array-like object, or a buffer with an optional byte offset and length
+func (r *globals) newTypedArray(kind *elementKind, prototype, arrayBufferType *object, args []Value) *object {
+	obj := newObject(prototype)
+	obj.class = kind.name
+	source, isObject := argument(args, 0).ref.(*object)
+	switch {
+	case !isObject:
+		length := byteLengthArgument(argument(args, 0), "Invalid typed array length")
+		bufferObj := r.newBuffer(prototypeFrom(arrayBufferType, r.objectPrototype), "ArrayBuffer", length*kind.size)
+		obj.view = &view{kind: kind, bufferObj: bufferObj, data: bufferObj.buffer, length: length}
+	case source.buffer != nil:
+		bufferLength := source.buffer.byteLength()
+		byteOffset := byteLengthArgument(argument(args, 1), "Start offset is outside the bounds of the buffer")
+		if byteOffset%kind.size != 0 {
+			throwRangeError("start offset of %s should be a multiple of %d", kind.name, kind.size)
+		}
+		if byteOffset > bufferLength {
+			throwRangeError("Start offset %d is outside the bounds of the buffer", byteOffset)
+		}
+		var length int
+		if argument(args, 2).IsUndefined() {
+			if (bufferLength-byteOffset)%kind.size != 0 {
+				throwRangeError("byte length of %s should be a multiple of %d", kind.name, kind.size)
+			}
+			length = (bufferLength - byteOffset) / kind.size
+		} else {
+			length = byteLengthArgument(args[2], "Invalid typed array length")
+			if byteOffset+length*kind.size > bufferLength {
+				throwRangeError("Invalid typed array length: %d", length)
+			}
+		}
+		obj.view = &view{kind: kind, bufferObj: source, data: source.buffer, byteOffset: byteOffset, length: length}
+	default:
+		values := listFromArrayLike(objectValue(source))
+		bufferObj := r.newBuffer(prototypeFrom(arrayBufferType, r.objectPrototype), "ArrayBuffer", len(values)*kind.size)
+		obj.view = &view{kind: kind, bufferObj: bufferObj, data: bufferObj.buffer, length: len(values)}
+		for i, value := range values {
+			obj.view.set(indexKey(i), value)
+		}
+	}
+	return obj
+}
+
+// uint8Bytes returns the buffer and byte range of a Uint8Array or Uint8ClampedArray, or panics like syscall/js if v is neither
+func uint8Bytes(v Value, funcName, argName string) (data *buffer, offset, length int) {
+	obj, ok := v.ref.(*object)
+	if !ok || obj.view == nil || obj.view.kind == nil || obj.view.kind.size != 1 || obj.view.kind.name == "Int8Array" {
+		panic("syscall/js: " + funcName + ": expected " + argName + " to be a Uint8Array or Uint8ClampedArray")
+	}
+	return obj.view.data, obj.view.byteOffset, obj.view.length
+}
+
+// CopyBytesToGo copies bytes from src to dst. Panics if src is not a Uint8Array or Uint8ClampedArray.
+// Returns the number of bytes copied, which is the minimum of the lengths of src and dst.
+func CopyBytesToGo(dst []byte, src Value) int {
+	data, offset, length := uint8Bytes(src, "CopyBytesToGo", "src")
+	if length < len(dst) {
+		dst = dst[:length]
+	}
+	data.read(dst, offset)
+	return len(dst)
+}
+
+// CopyBytesToJS copies bytes from src to dst. Panics if dst is not a Uint8Array or Uint8ClampedArray.
+// Returns the number of bytes copied, which is the minimum of the lengths of src and dst.
+func CopyBytesToJS(dst Value, src []byte) int {
+	data, offset, length := uint8Bytes(dst, "CopyBytesToJS", "dst")
+	if length < len(src) {
+		src = src[:length]
+	}
+	data.write(offset, src)
+	return len(src)
+}
@@ -0,0 +1,355 @@
+// Package fakejs is an in-memory JavaScript object model with the same API and panicking behavior as [syscall/js].
+//
+// It supports objects, arrays, functions, prototypes, instanceof, symbols, typed arrays, and thrown errors, but does not run JavaScript source code.
+// Like a browser, there is one global object shared by the whole program.
+package fakejs
+
+import (
+	"math"
+	"unsafe"
+)
+
+// Type represents the JavaScript type of a Value.
+type Type int
+
+// Available JavaScript types, matching [syscall/js]
+const (
+	TypeUndefined Type = iota
+	TypeNull
+	TypeBoolean
+	TypeNumber
+	TypeString
+	TypeSymbol
+	TypeObject
+	TypeFunction
+)
+
+// String returns the type's name, like "undefined" or "object", matching JavaScript's typeof operator.
+func (t Type) String() string {
+	switch t {
+	case TypeUndefined:
+		return "undefined"
+	case TypeNull:
+		return "null"
+	case TypeBoolean:
+		return "boolean"
+	case TypeNumber:
+		return "number"
+	case TypeString:
+		return "string"
+	case TypeSymbol:
+		return "symbol"
+	case TypeObject:
+		return "object"
+	case TypeFunction:
+		return "function"
+	default:
+		panic("bad type")
+	}
+}
+
+// Value represents a JavaScript value. The zero value is the JavaScript value "undefined".
+type Value struct {
+	// ref is nil for undefined, or one of null, bool, float64, string, *symbol, or *object
+	ref any
+}
+
+type null struct{}
+
+// Error wraps a JavaScript error, like a thrown value.
+type Error struct {
+	// Value is the underlying JavaScript error value.
+	Value
+}
+
+// Error implements the error interface.
+func (e Error) Error() string {
+	return "JavaScript error: " + e.Get("message").String()
+}
+
+// ValueError occurs when a Value method is called on a Value that does not support it.
+type ValueError struct {
+	Method string
+	Type   Type
+}
+
+// Error implements the error interface.
+func (e *ValueError) Error() string {
+	return "syscall/js: call of " + e.Method + " on " + e.Type.String()
+}
+
+// Undefined returns the JavaScript value "undefined".
+func Undefined() Value {
+	return Value{}
+}
+
+// Null returns the JavaScript value "null".
+func Null() Value {
+	return Value{ref: null{}}
+}
+
+// Global returns the JavaScript global object.
+func Global() Value {
+	return Value{ref: realm().global}
+}
+
+// ValueOf returns x as a JavaScript value, following the same rules as [syscall/js.ValueOf].
+// Panics if x is not one of the supported types.
+func ValueOf(x any) Value {
+	switch x := x.(type) {
+	case Value:
+		return x
+	case Func:
+		return x.Value
+	case nil:
+		return Null()
+	case bool:
+		return Value{ref: x}
+	case int:
+		return floatValue(float64(x))
+	case int8:
+		return floatValue(float64(x))
+	case int16:
+		return floatValue(float64(x))
+	case int32:
+		return floatValue(float64(x))
+	case int64:
+		return floatValue(float64(x))
+	case uint:
+		return floatValue(float64(x))
+	case uint8:
+		return floatValue(float64(x))
+	case uint16:
+		return floatValue(float64(x))
+	case uint32:
+		return floatValue(float64(x))
+	case uint64:
+		return floatValue(float64(x))
+	case uintptr:
+		return floatValue(float64(x))
+	case unsafe.Pointer:
+		return floatValue(float64(uintptr(x)))
+	case float32:
+		return floatValue(float64(x))
+	case float64:
+		return floatValue(x)
+	case string:
+		return Value{ref: x}
+	case []any:
+		elements := make([]Value, len(x))
+		for i, element := range x {
+			elements[i] = ValueOf(element)
+		}
+		return Value{ref: newArray(elements)}
+	case map[string]any:
+		obj := newObject(realm().objectPrototype)
+		for key, value := range x {
+			obj.set(key, ValueOf(value))
+		}
+		return Value{ref: obj}
+	default:
+		panic("ValueOf: invalid value")
+	}
+}
+
+func floatValue(f float64) Value {
+	return Value{ref: f}
+}
+
+func stringValue(s string) Value {
+	return Value{ref: s}
+}
+
+func boolValue(b bool) Value {
+	return Value{ref: b}
+}
+
+func objectValue(o *object) Value {
+	return Value{ref: o}
+}
+
+// Type returns the JavaScript type of the value v, like JavaScript's typeof operator except it returns TypeNull for null.
+func (v Value) Type() Type {
+	switch ref := v.ref.(type) {
+	case nil:
+		return TypeUndefined
+	case null:
+		return TypeNull
+	case bool:
+		return TypeBoolean
+	case float64:
+		return TypeNumber
+	case string:
+		return TypeString
+	case *symbol:
+		return TypeSymbol
+	case *object:
+		if ref.isCallable() {
+			return TypeFunction
+		}
+		return TypeObject
+	default:
+		panic("bad type")
+	}
+}
+
+// object returns v's object, or panics with a ValueError for method if v is not an object
+func (v Value) object(method string) *object {
+	obj, ok := v.ref.(*object)
+	if !ok {
+		panic(&ValueError{Method: method, Type: v.Type()})
+	}
+	return obj
+}
+
+// Get returns the JavaScript property p of value v. Panics if v is not a JavaScript object.
+func (v Value) Get(p string) Value {
+	return v.object("Value.Get").get(p)
+}
+
+// Set sets the JavaScript property p of value v to ValueOf(x). Panics if v is not a JavaScript object.
+func (v Value) Set(p string, x any) {
+	v.object("Value.Set").set(p, ValueOf(x))
+}
+
+// Delete deletes the JavaScript property p of value v. Panics if v is not a JavaScript object.
+func (v Value) Delete(p string) {
+	v.object("Value.Delete").delete(p)
+}
+
+// Index returns JavaScript index i of value v. Panics if v is not a JavaScript object.
+func (v Value) Index(i int) Value {
+	return v.object("Value.Index").get(indexKey(i))
+}
+
+// SetIndex sets the JavaScript index i of value v to ValueOf(x). Panics if v is not a JavaScript object.
+func (v Value) SetIndex(i int, x any) {
+	v.object("Value.SetIndex").set(indexKey(i), ValueOf(x))
+}
+
+// Length returns the JavaScript property "length" of v. Panics if v is not a JavaScript object.
+func (v Value) Length() int {
+	length := toNumber(v.object("Value.Length").get("length"))
+	if math.IsNaN(length) {
+		return 0
+	}
+	return int(length)
+}
+
+// Call does a JavaScript call to the method m of value v with the given arguments.
+// Panics if v has no method m or the function throws an error.
+func (v Value) Call(m string, args ...any) Value {
+	method := v.object("Value.Call").get(m)
+	fn, ok := method.ref.(*object)
+	if !ok || !fn.isCallable() {
+		panic("syscall/js: Value.Call: property " + m + " is not a function, got " + method.Type().String())
+	}
+	return fn.callFunc(v, valuesOf(args))
+}
+
+// Invoke does a JavaScript call of the value v with the given arguments.
+// Panics if v is not a JavaScript function or the function throws an error.
+func (v Value) Invoke(args ...any) Value {
+	fn, ok := v.ref.(*object)
+	if !ok || !fn.isCallable() {
+		panic(&ValueError{Method: "Value.Invoke", Type: v.Type()})
+	}
+	return fn.callFunc(Undefined(), valuesOf(args))
+}
+
+// New uses JavaScript's "new" operator with value v as constructor and the given arguments.
+// Panics if v is not a JavaScript function or the constructor throws an error.
+func (v Value) New(args ...any) Value {
+	fn, ok := v.ref.(*object)
+	if !ok || !fn.isCallable() {
+		panic(&ValueError{Method: "Value.New", Type: v.Type()})
+	}
+	return construct(fn, valuesOf(args))
+}
+
+// InstanceOf reports whether v is an instance of type t according to JavaScript's instanceof operator.
+// Panics with a TypeError if t is not a constructor.
+func (v Value) InstanceOf(t Value) bool {
+	return instanceOf(v, t)
+}
+
+// Equal reports whether v and w are equal according to JavaScript's === operator.
+func (v Value) Equal(w Value) bool {
+	return v.ref == w.ref
+}
+
+// IsUndefined reports whether v is the JavaScript value "undefined".
+func (v Value) IsUndefined() bool {
+	return v.ref == nil
+}
+
+// IsNull reports whether v is the JavaScript value "null".
+func (v Value) IsNull() bool {
+	_, isNull := v.ref.(null)
+	return isNull
+}
+
+// IsNaN reports whether v is the JavaScript value "NaN".
+func (v Value) IsNaN() bool {
+	f, ok := v.ref.(float64)
+	return ok && math.IsNaN(f)
+}
+
+// Float returns the value v as a float64. Panics if v is not a JavaScript number.
+func (v Value) Float() float64 {
+	return v.float("Value.Float")
+}
+
+// Int returns the value v truncated to an int. Panics if v is not a JavaScript number.
+func (v Value) Int() int {
+	return int(v.float("Value.Int"))
+}
+
+func (v Value) float(method string) float64 {
+	f, ok := v.ref.(float64)
+	if !ok {
+		panic(&ValueError{Method: method, Type: v.Type()})
+	}
+	return f
+}
+
+// Bool returns the value v as a bool. Panics if v is not a JavaScript boolean.
+func (v Value) Bool() bool {
+	b, ok := v.ref.(bool)
+	if !ok {
+		panic(&ValueError{Method: "Value.Bool", Type: v.Type()})
+	}
+	return b
+}
+
+// Truthy returns the JavaScript "truthiness" of the value v.
+func (v Value) Truthy() bool {
+	return toBoolean(v)
+}
+
+// String returns the value v as a string.
+// Like [syscall/js.Value.String], it does not panic for other types, and instead returns a string like "<T>" or "<T: V>".
+func (v Value) String() string {
+	switch ref := v.ref.(type) {
+	case string:
+		return ref
+	case nil:
+		return "<undefined>"
+	case null:
+		return "<null>"
+	case bool:
+		return "<boolean: " + toString(v) + ">"
+	case float64:
+		return "<number: " + toString(v) + ">"
+	default:
+		return "<" + v.Type().String() + ">"
+	}
+}
+
+func valuesOf(args []any) []Value {
+	values := make([]Value, len(args))
+	for i, arg := range args {
+		values[i] = ValueOf(arg)
+	}
+	return values
+}
@@ -0,0 +1,6 @@
+// Package js selects the JavaScript types used by safejs.
+// It aliases [syscall/js] in js/wasm builds, and the pure-Go fake in fakejs for native builds with the "safejstest" build tag.
+//
+// Other native builds get minimal stand-in types without the fake, where the only values are undefined and null.
+// The backend package fails every operation with ErrUnsupported for them.
+package js
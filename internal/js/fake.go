@@ -0,0 +1,41 @@
+//go:build !js && safejstest
+
+package js
+
+import js "github.com/hack-pad/safejs/internal/fakejs"
+
+type (
+	// Value is a JavaScript value
+	Value = js.Value
+	// Func is a wrapped Go function to be called by JavaScript
+	Func = js.Func
+	// Error wraps a JavaScript error
+	Error = js.Error
+	// ValueError occurs when a Value method is called on a Value that does not support it
+	ValueError = js.ValueError
+	// Type represents the JavaScript type of a Value
+	Type = js.Type
+)
+
+// Available JavaScript types
+const (
+	TypeUndefined = js.TypeUndefined
+	TypeNull      = js.TypeNull
+	TypeBoolean   = js.TypeBoolean
+	TypeNumber    = js.TypeNumber
+	TypeString    = js.TypeString
+	TypeSymbol    = js.TypeSymbol
+	TypeObject    = js.TypeObject
+	TypeFunction  = js.TypeFunction
+)
+
+// JavaScript runtime functions
+var (
+	CopyBytesToGo = js.CopyBytesToGo
+	CopyBytesToJS = js.CopyBytesToJS
+	FuncOf        = js.FuncOf
+	Global        = js.Global
+	Null          = js.Null
+	Undefined     = js.Undefined
+	ValueOf       = js.ValueOf
+)
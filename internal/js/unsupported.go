@@ -0,0 +1,100 @@
+//go:build !js && !safejstest
+
+package js
+
+// Value is a JavaScript value.
+// Without a JavaScript runtime, the only values are undefined and null.
+type Value struct {
+	null bool
+}
+
+// Func is a wrapped Go function to be called by JavaScript
+type Func struct {
+	Value
+}
+
+// Error wraps a JavaScript error
+type Error struct {
+	Value
+}
+
+// Error implements the error interface
+func (e Error) Error() string {
+	return "JavaScript error: " + e.Type().String()
+}
+
+// Type represents the JavaScript type of a Value
+type Type int
+
+// Available JavaScript types, matching [syscall/js]
+const (
+	TypeUndefined Type = iota
+	TypeNull
+	TypeBoolean
+	TypeNumber
+	TypeString
+	TypeSymbol
+	TypeObject
+	TypeFunction
+)
+
+func (t Type) String() string {
+	switch t {
+	case TypeUndefined:
+		return "undefined"
+	case TypeNull:
+		return "null"
+	case TypeBoolean:
+		return "boolean"
+	case TypeNumber:
+		return "number"
+	case TypeString:
+		return "string"
+	case TypeSymbol:
+		return "symbol"
+	case TypeObject:
+		return "object"
+	case TypeFunction:
+		return "function"
+	default:
+		panic("bad type")
+	}
+}
+
+// Undefined returns the JavaScript value "undefined"
+func Undefined() Value {
+	return Value{}
+}
+
+// Null returns the JavaScript value "null"
+func Null() Value {
+	return Value{null: true}
+}
+
+// Equal reports whether v and w are equal according to JavaScript's === operator
+func (v Value) Equal(w Value) bool {
+	return v == w
+}
+
+// Type returns the JavaScript type of v
+func (v Value) Type() Type {
+	if v.null {
+		return TypeNull
+	}
+	return TypeUndefined
+}
+
+// IsNaN reports whether v is the JavaScript value "NaN"
+func (v Value) IsNaN() bool {
+	return false
+}
+
+// IsNull reports whether v is the JavaScript value "null"
+func (v Value) IsNull() bool {
+	return v.null
+}
+
+// IsUndefined reports whether v is the JavaScript value "undefined"
+func (v Value) IsUndefined() bool {
+	return !v.null
+}
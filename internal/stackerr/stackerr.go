@@ -1,5 +1,3 @@
-//go:build js && wasm
-
 // Package stackerr adds stack traces to verbose error messages.
 package stackerr
 
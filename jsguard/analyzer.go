@@ -9,9 +9,11 @@ import (
 	"go/ast"
 	"go/printer"
 	"go/token"
+	"go/types"
 	"log"
 	"os"
 	"strings"
+	"sync"
 
 	"golang.org/x/tools/go/analysis"
 )
@@ -23,20 +25,51 @@ var Analyzer = &analysis.Analyzer{
 	Run:  run,
 }
 
+// Analyzers returns all of jsguard's analyzers, starting with Analyzer
+func Analyzers() []*analysis.Analyzer {
+	return []*analysis.Analyzer{
+		Analyzer,
+		CallbackBlockAnalyzer,
+		CallbackPanicAnalyzer,
+		ExportedJSAnalyzer,
+		FuncLeakAnalyzer,
+		FuncReleaseAnalyzer,
+		IgnoredErrorAnalyzer,
+		MustGetGlobalAnalyzer,
+		UnsafeCallerAnalyzer,
+		ValueConversionAnalyzer,
+	}
+}
+
+// warnGOOS warns once when GOOS is unset, like in go vet or golangci-lint. cmd/jsguard always sets it, based on its -target flag.
+var warnGOOS sync.Once
+
 func run(pass *analysis.Pass) (interface{}, error) {
-	if os.Getenv("GOOS") != "js" {
-		log.Println("GOOS is not set to 'js', results are likely empty.")
+	if os.Getenv("GOOS") == "" {
+		warnGOOS.Do(func() {
+			log.Println("GOOS is not set, so only files built for the current platform are analyzed. Set GOOS=js GOARCH=wasm to analyze js/wasm files too.")
+		})
+	}
+
+	cfg, err := loadConfig(pass)
+	if err != nil {
+		return nil, err
 	}
+	finishBaseline, err := applyBaseline(pass)
+	if err != nil {
+		return nil, err
+	}
+	missingReasons := honorIgnoreDirectives(pass)
+	reportMissingReasons(pass, missingReasons)
 
 	for _, file := range pass.Files {
-		syscallJSImportName, ok := jsImportName(file.Imports)
-		if ok {
-			ast.Inspect(file, func(node ast.Node) bool {
-				return inspectNode(pass, node, syscallJSImportName)
-			})
-		}
+		calls := make(map[*ast.SelectorExpr]*ast.CallExpr)
+		fix := newFileFix(pass, file)
+		ast.Inspect(file, func(node ast.Node) bool {
+			return inspectNode(pass, cfg, node, calls, fix)
+		})
 	}
-	return nil, nil
+	return nil, finishBaseline()
 }
 
 func isSafeCall(typeName, functionName string) bool {
@@ -65,23 +98,64 @@ func isSafeCall(typeName, functionName string) bool {
 	}
 }
 
-func jsImportName(imports []*ast.ImportSpec) (string, bool) {
-	for _, imprt := range imports {
-		if imprt.Path.Value == `"syscall/js"` {
-			if imprt.Name != nil {
-				return imprt.Name.Name, true
-			}
-			return "js", true
+// inspectNode reports unsafe syscall/js functions and methods, whether they're called directly or used as values.
+// Selectors already handled as part of a call are recorded in calls.
+// Calls get suggested fixes from fix, if available.
+func inspectNode(pass *analysis.Pass, cfg *config, node ast.Node, calls map[*ast.SelectorExpr]*ast.CallExpr, fix *fileFix) bool {
+	switch node := node.(type) {
+	case *ast.CallExpr:
+		selector, ok := unparen(node.Fun).(*ast.SelectorExpr)
+		if ok {
+			calls[selector] = node
+			inspectSelector(pass, cfg, selector, node, fix)
+		}
+	case *ast.SelectorExpr:
+		if _, isCall := calls[node]; !isCall {
+			inspectSelector(pass, cfg, node, nil, nil)
+		}
+	}
+	return true
+}
+
+// unparen returns expr with any enclosing parentheses removed
+func unparen(expr ast.Expr) ast.Expr {
+	for {
+		paren, ok := expr.(*ast.ParenExpr)
+		if !ok {
+			return expr
 		}
+		expr = paren.X
 	}
-	return "", false
 }
 
-func inspectNode(pass *analysis.Pass, node ast.Node, syscallJSImportName string) bool {
-	recurse := false
-	recurse = inspectMethodCall(pass, node) || recurse
-	recurse = inspectPackageCall(pass, node, syscallJSImportName) || recurse
-	return recurse
+// parentMap returns the parent of every node in root
+func parentMap(root ast.Node) map[ast.Node]ast.Node {
+	parents := make(map[ast.Node]ast.Node)
+	var stack []ast.Node
+	ast.Inspect(root, func(node ast.Node) bool {
+		if node == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		if len(stack) > 0 {
+			parents[node] = stack[len(stack)-1]
+		}
+		stack = append(stack, node)
+		return true
+	})
+	return parents
+}
+
+// parentOf returns the parent of node, skipping any enclosing parentheses
+func parentOf(parents map[ast.Node]ast.Node, node ast.Node) ast.Node {
+	parent := parents[node]
+	for {
+		paren, ok := parent.(*ast.ParenExpr)
+		if !ok {
+			return parent
+		}
+		parent = parents[paren]
+	}
 }
 
 func formatNode(fset *token.FileSet, x interface{}) string {
@@ -93,45 +167,67 @@ func formatNode(fset *token.FileSet, x interface{}) string {
 	return buf.String()
 }
 
-func inspectMethodCall(pass *analysis.Pass, node ast.Node) bool {
-	callExpr, ok := node.(*ast.CallExpr)
-	if !ok {
-		return true
+// selectedFunc returns the function or method selector refers to, including promoted methods of embedded fields.
+// Returns nil if selector is not a function or method.
+func selectedFunc(pass *analysis.Pass, selector *ast.SelectorExpr) *types.Func {
+	if selection, ok := pass.TypesInfo.Selections[selector]; ok {
+		if selection.Kind() == types.FieldVal {
+			return nil
+		}
+		fn, _ := selection.Obj().(*types.Func)
+		return fn
 	}
-	selector, ok := callExpr.Fun.(*ast.SelectorExpr)
-	if !ok {
-		return true
+	fn, _ := pass.TypesInfo.Uses[selector.Sel].(*types.Func) // qualified identifier, like js.ValueOf
+	return fn
+}
+
+// funcTypeName returns the package path for functions or the fully qualified receiver type for methods.
+// For example, "syscall/js" for js.ValueOf and "syscall/js.Value" for js.Value.Get.
+func funcTypeName(fn *types.Func) (string, bool) {
+	if fn.Pkg() == nil {
+		return "", false
 	}
-	selectorIdent, ok := selector.X.(*ast.Ident)
-	if !ok {
-		return true
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return fn.Pkg().Path(), true
 	}
-	typeName := pass.TypesInfo.TypeOf(selectorIdent).String()
-	if !isSafeCall(typeName, selector.Sel.Name) {
-		pass.Reportf(callExpr.Pos(), "unsafe method call on %s found: %s(...)", typeName, formatNode(pass.Fset, selector))
+	recvType := recv.Type()
+	if pointer, ok := recvType.(*types.Pointer); ok {
+		recvType = pointer.Elem()
 	}
-	return true
+	return recvType.String(), true
 }
 
-func inspectPackageCall(pass *analysis.Pass, node ast.Node, syscallJSImportName string) bool {
-	callExpr, ok := node.(*ast.CallExpr)
-	if !ok {
-		return true
-	}
-	selector, ok := callExpr.Fun.(*ast.SelectorExpr)
-	if !ok {
-		return true
+// inspectSelector reports selector if it refers to an unsafe syscall/js function or method, or one configured as unsafe.
+// callExpr is the call of selector, or nil if selector is used as a value.
+func inspectSelector(pass *analysis.Pass, cfg *config, selector *ast.SelectorExpr, callExpr *ast.CallExpr, fix *fileFix) {
+	fn := selectedFunc(pass, selector)
+	if fn == nil || cfg.isSafeCall(fn) {
+		return
 	}
-	selectorIdent, ok := selector.X.(*ast.Ident)
-	if !ok {
-		return true
-	}
-	if selectorIdent.Name != syscallJSImportName {
-		return true
+	typeName, _ := funcTypeName(fn)
+	if fn.Pkg().Path() != jsPackagePath {
+		fix = nil // only syscall/js calls can be rewritten to safejs
 	}
-	if !isSafeCall("syscall/js", selector.Sel.Name) {
-		pass.Reportf(callExpr.Pos(), "unsafe call to syscall/js found: %s(...)",
-			formatNode(pass.Fset, selector))
+	isMethod := fn.Type().(*types.Signature).Recv() != nil
+	switch {
+	case callExpr != nil && isMethod:
+		pass.Report(analysis.Diagnostic{
+			Pos:            callExpr.Pos(),
+			Category:       ruleUnsafeMethod,
+			Message:        fmt.Sprintf("unsafe method call on %s found: %s(...)", typeName, formatNode(pass.Fset, selector)),
+			SuggestedFixes: fix.SuggestedFixes(callExpr),
+		})
+	case callExpr != nil:
+		pass.Report(analysis.Diagnostic{
+			Pos:            callExpr.Pos(),
+			Category:       ruleUnsafeCall,
+			Message:        fmt.Sprintf("unsafe call to %s found: %s(...)", fn.Pkg().Path(), formatNode(pass.Fset, selector)),
+			SuggestedFixes: fix.SuggestedFixes(callExpr),
+		})
+	case isMethod:
+		reportf(pass, ruleUnsafeMethodValue, selector.Pos(), "unsafe method value of %s found: %s", typeName, formatNode(pass.Fset, selector))
+	default:
+		reportf(pass, ruleUnsafeFuncValue, selector.Pos(), "unsafe function value from %s found: %s", fn.Pkg().Path(), formatNode(pass.Fset, selector))
 	}
-	return true
 }
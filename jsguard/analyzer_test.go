@@ -96,29 +96,28 @@ func Foo() {
 	result0 := result[0]
 	expected := []analysis.Diagnostic{
 		{
-			Pos:     filePos(t, result0.Pass, fooName, strings.Index(fooFile, "js.CopyBytesToGo(")),
-			Message: "unsafe call to syscall/js found: js.CopyBytesToGo(...)",
+			Pos:      filePos(t, result0.Pass, fooName, strings.Index(fooFile, "js.CopyBytesToGo(")),
+			Category: "unsafe-call",
+			Message:  "unsafe call to syscall/js found: js.CopyBytesToGo(...)",
 		},
 		{
-			Pos:     filePos(t, result0.Pass, fooName, strings.Index(fooFile, "js.CopyBytesToJS(")),
-			Message: "unsafe call to syscall/js found: js.CopyBytesToJS(...)",
+			Pos:      filePos(t, result0.Pass, fooName, strings.Index(fooFile, "js.CopyBytesToJS(")),
+			Category: "unsafe-call",
+			Message:  "unsafe call to syscall/js found: js.CopyBytesToJS(...)",
 		},
 		{
-			Pos:     filePos(t, result0.Pass, fooName, strings.Index(fooFile, "js.FuncOf(")),
-			Message: "unsafe call to syscall/js found: js.FuncOf(...)",
+			Pos:      filePos(t, result0.Pass, fooName, strings.Index(fooFile, "js.FuncOf(")),
+			Category: "unsafe-call",
+			Message:  "unsafe call to syscall/js found: js.FuncOf(...)",
 		},
 		{
-			Pos:     filePos(t, result0.Pass, fooName, strings.Index(fooFile, "js.ValueOf(")),
-			Message: "unsafe call to syscall/js found: js.ValueOf(...)",
+			Pos:            filePos(t, result0.Pass, fooName, strings.Index(fooFile, "js.ValueOf(")),
+			Category:       "unsafe-call",
+			Message:        "unsafe call to syscall/js found: js.ValueOf(...)",
+			SuggestedFixes: rewriteFix,
 		},
 	}
-	assert.Equal(t, len(expected), len(result0.Diagnostics))
-	if len(result0.Diagnostics) < len(expected) {
-		expected = expected[:len(result0.Diagnostics)]
-	}
-	for i := range expected {
-		assert.Equal(t, expected[i], result0.Diagnostics[i])
-	}
+	assertDiagnostics(t, expected, result0.Diagnostics)
 }
 
 func TestAliasPackageCall(t *testing.T) {
@@ -151,21 +150,19 @@ func Foo() {
 	result0 := result[0]
 	expected := []analysis.Diagnostic{
 		{
-			Pos:     filePos(t, result0.Pass, fooName, strings.Index(fooFile, "alias.ValueOf(")),
-			Message: "unsafe call to syscall/js found: alias.ValueOf(...)",
+			Pos:            filePos(t, result0.Pass, fooName, strings.Index(fooFile, "alias.ValueOf(")),
+			Category:       "unsafe-call",
+			Message:        "unsafe call to syscall/js found: alias.ValueOf(...)",
+			SuggestedFixes: rewriteFix,
 		},
 		{
-			Pos:     filePos(t, result0.Pass, fooName, strings.Index(fooFile, "value.String(")),
-			Message: "unsafe method call on syscall/js.Value found: value.String(...)",
+			Pos:            filePos(t, result0.Pass, fooName, strings.Index(fooFile, "value.String(")),
+			Category:       "unsafe-method",
+			Message:        "unsafe method call on syscall/js.Value found: value.String(...)",
+			SuggestedFixes: rewriteFix,
 		},
 	}
-	assert.Equal(t, len(expected), len(result0.Diagnostics))
-	if len(result0.Diagnostics) < len(expected) {
-		expected = expected[:len(result0.Diagnostics)]
-	}
-	for i := range expected {
-		assert.Equal(t, expected[i], result0.Diagnostics[i])
-	}
+	assertDiagnostics(t, expected, result0.Diagnostics)
 }
 
 func TestMethodCall(t *testing.T) {
@@ -218,75 +215,368 @@ func Foo(err js.Error, value js.Value, typ js.Type) {
 	result0 := result[0]
 	expected := []analysis.Diagnostic{
 		{
-			Pos:     filePos(t, result0.Pass, fooName, strings.Index(fooFile, "err.Error(")),
-			Message: "unsafe method call on syscall/js.Error found: err.Error(...)",
+			Pos:      filePos(t, result0.Pass, fooName, strings.Index(fooFile, "err.Error(")),
+			Category: "unsafe-method",
+			Message:  "unsafe method call on syscall/js.Error found: err.Error(...)",
 		},
 		{
-			Pos:     filePos(t, result0.Pass, fooName, strings.Index(fooFile, "value.Bool(")),
-			Message: "unsafe method call on syscall/js.Value found: value.Bool(...)",
+			Pos:            filePos(t, result0.Pass, fooName, strings.Index(fooFile, "value.Bool(")),
+			Category:       "unsafe-method",
+			Message:        "unsafe method call on syscall/js.Value found: value.Bool(...)",
+			SuggestedFixes: rewriteFix,
 		},
 		{
-			Pos:     filePos(t, result0.Pass, fooName, strings.Index(fooFile, "value.Call(")),
-			Message: "unsafe method call on syscall/js.Value found: value.Call(...)",
+			Pos:            filePos(t, result0.Pass, fooName, strings.Index(fooFile, "value.Call(")),
+			Category:       "unsafe-method",
+			Message:        "unsafe method call on syscall/js.Value found: value.Call(...)",
+			SuggestedFixes: rewriteFix,
 		},
 		{
-			Pos:     filePos(t, result0.Pass, fooName, strings.Index(fooFile, "value.Delete(")),
-			Message: "unsafe method call on syscall/js.Value found: value.Delete(...)",
+			Pos:            filePos(t, result0.Pass, fooName, strings.Index(fooFile, "value.Delete(")),
+			Category:       "unsafe-method",
+			Message:        "unsafe method call on syscall/js.Value found: value.Delete(...)",
+			SuggestedFixes: rewriteFix,
 		},
 		{
-			Pos:     filePos(t, result0.Pass, fooName, strings.Index(fooFile, "value.Float(")),
-			Message: "unsafe method call on syscall/js.Value found: value.Float(...)",
+			Pos:            filePos(t, result0.Pass, fooName, strings.Index(fooFile, "value.Float(")),
+			Category:       "unsafe-method",
+			Message:        "unsafe method call on syscall/js.Value found: value.Float(...)",
+			SuggestedFixes: rewriteFix,
 		},
 		{
-			Pos:     filePos(t, result0.Pass, fooName, strings.Index(fooFile, "value.Get(")),
-			Message: "unsafe method call on syscall/js.Value found: value.Get(...)",
+			Pos:            filePos(t, result0.Pass, fooName, strings.Index(fooFile, "value.Get(")),
+			Category:       "unsafe-method",
+			Message:        "unsafe method call on syscall/js.Value found: value.Get(...)",
+			SuggestedFixes: rewriteFix,
 		},
 		{
-			Pos:     filePos(t, result0.Pass, fooName, strings.Index(fooFile, "value.Index(")),
-			Message: "unsafe method call on syscall/js.Value found: value.Index(...)",
+			Pos:            filePos(t, result0.Pass, fooName, strings.Index(fooFile, "value.Index(")),
+			Category:       "unsafe-method",
+			Message:        "unsafe method call on syscall/js.Value found: value.Index(...)",
+			SuggestedFixes: rewriteFix,
 		},
 		{
-			Pos:     filePos(t, result0.Pass, fooName, strings.Index(fooFile, "value.InstanceOf(")),
-			Message: "unsafe method call on syscall/js.Value found: value.InstanceOf(...)",
+			Pos:            filePos(t, result0.Pass, fooName, strings.Index(fooFile, "value.InstanceOf(")),
+			Category:       "unsafe-method",
+			Message:        "unsafe method call on syscall/js.Value found: value.InstanceOf(...)",
+			SuggestedFixes: rewriteFix,
 		},
 		{
-			Pos:     filePos(t, result0.Pass, fooName, strings.Index(fooFile, "value.Int(")),
-			Message: "unsafe method call on syscall/js.Value found: value.Int(...)",
+			Pos:            filePos(t, result0.Pass, fooName, strings.Index(fooFile, "value.Int(")),
+			Category:       "unsafe-method",
+			Message:        "unsafe method call on syscall/js.Value found: value.Int(...)",
+			SuggestedFixes: rewriteFix,
 		},
 		{
-			Pos:     filePos(t, result0.Pass, fooName, strings.Index(fooFile, "value.Invoke(")),
-			Message: "unsafe method call on syscall/js.Value found: value.Invoke(...)",
+			Pos:            filePos(t, result0.Pass, fooName, strings.Index(fooFile, "value.Invoke(")),
+			Category:       "unsafe-method",
+			Message:        "unsafe method call on syscall/js.Value found: value.Invoke(...)",
+			SuggestedFixes: rewriteFix,
 		},
 		{
-			Pos:     filePos(t, result0.Pass, fooName, strings.Index(fooFile, "value.Length(")),
-			Message: "unsafe method call on syscall/js.Value found: value.Length(...)",
+			Pos:            filePos(t, result0.Pass, fooName, strings.Index(fooFile, "value.Length(")),
+			Category:       "unsafe-method",
+			Message:        "unsafe method call on syscall/js.Value found: value.Length(...)",
+			SuggestedFixes: rewriteFix,
 		},
 		{
-			Pos:     filePos(t, result0.Pass, fooName, strings.Index(fooFile, "value.New(")),
-			Message: "unsafe method call on syscall/js.Value found: value.New(...)",
+			Pos:            filePos(t, result0.Pass, fooName, strings.Index(fooFile, "value.New(")),
+			Category:       "unsafe-method",
+			Message:        "unsafe method call on syscall/js.Value found: value.New(...)",
+			SuggestedFixes: rewriteFix,
 		},
 		{
-			Pos:     filePos(t, result0.Pass, fooName, strings.Index(fooFile, "value.Set(")),
-			Message: "unsafe method call on syscall/js.Value found: value.Set(...)",
+			Pos:            filePos(t, result0.Pass, fooName, strings.Index(fooFile, "value.Set(")),
+			Category:       "unsafe-method",
+			Message:        "unsafe method call on syscall/js.Value found: value.Set(...)",
+			SuggestedFixes: rewriteFix,
 		},
 		{
-			Pos:     filePos(t, result0.Pass, fooName, strings.Index(fooFile, "value.SetIndex(")),
-			Message: "unsafe method call on syscall/js.Value found: value.SetIndex(...)",
+			Pos:            filePos(t, result0.Pass, fooName, strings.Index(fooFile, "value.SetIndex(")),
+			Category:       "unsafe-method",
+			Message:        "unsafe method call on syscall/js.Value found: value.SetIndex(...)",
+			SuggestedFixes: rewriteFix,
 		},
 		{
-			Pos:     filePos(t, result0.Pass, fooName, strings.Index(fooFile, "value.String(")),
-			Message: "unsafe method call on syscall/js.Value found: value.String(...)",
+			Pos:            filePos(t, result0.Pass, fooName, strings.Index(fooFile, "value.String(")),
+			Category:       "unsafe-method",
+			Message:        "unsafe method call on syscall/js.Value found: value.String(...)",
+			SuggestedFixes: rewriteFix,
 		},
 		{
-			Pos:     filePos(t, result0.Pass, fooName, strings.Index(fooFile, "value.Truthy(")),
-			Message: "unsafe method call on syscall/js.Value found: value.Truthy(...)",
+			Pos:            filePos(t, result0.Pass, fooName, strings.Index(fooFile, "value.Truthy(")),
+			Category:       "unsafe-method",
+			Message:        "unsafe method call on syscall/js.Value found: value.Truthy(...)",
+			SuggestedFixes: rewriteFix,
 		},
 	}
-	assert.Equal(t, len(expected), len(result0.Diagnostics))
-	if len(result0.Diagnostics) < len(expected) {
-		expected = expected[:len(result0.Diagnostics)]
+	assertDiagnostics(t, expected, result0.Diagnostics)
+}
+
+func assertDiagnostics(t *testing.T, expected, actual []analysis.Diagnostic) {
+	t.Helper()
+	assert.Equal(t, len(expected), len(actual))
+	if len(actual) < len(expected) {
+		expected = expected[:len(actual)]
+	}
+	for i := range expected {
+		// compare each field, since newer analysis frameworks also fill in fields like URL
+		assert.Equal(t, expected[i].Pos, actual[i].Pos)
+		assert.Equal(t, expected[i].End, actual[i].End)
+		assert.Equal(t, expected[i].Category, actual[i].Category)
+		assert.Equal(t, expected[i].Message, actual[i].Message)
+		assertSuggestedFixes(t, expected[i].SuggestedFixes, actual[i].SuggestedFixes)
+		assert.Equal(t, expected[i].Related, actual[i].Related)
+	}
+}
+
+// rewriteFix is expected on diagnostics with a fix rewriting their statement to safejs. The fix tests check its edits.
+var rewriteFix = []analysis.SuggestedFix{{Message: fixMessage}}
+
+// assertSuggestedFixes compares fixes, but only compares the messages of expected fixes without any edits, like rewriteFix
+func assertSuggestedFixes(t *testing.T, expected, actual []analysis.SuggestedFix) {
+	t.Helper()
+	if len(expected) != len(actual) {
+		assert.Equal(t, expected, actual)
+		return
 	}
 	for i := range expected {
-		assert.Equal(t, expected[i], result0.Diagnostics[i])
+		if expected[i].TextEdits == nil {
+			assert.Equal(t, expected[i].Message, actual[i].Message)
+		} else {
+			assert.Equal(t, expected[i], actual[i])
+		}
 	}
 }
+
+func TestReceiverExpressionMethodCall(t *testing.T) {
+	t.Parallel()
+	const (
+		fooName = "foo.go"
+		fooFile = `
+//go:build js && wasm
+
+package foo
+
+import (
+	"syscall/js"
+)
+
+type holder struct {
+	field js.Value
+	ptr   *js.Value
+}
+
+func value() js.Value {
+	return js.Null()
+}
+
+func Foo(s holder, values []js.Value, m map[string]js.Value) {
+	js.Global().Get("document").Call("createElement", "div")
+	s.field.Get("a")
+	s.ptr.Int()
+	values[0].Int()
+	m["a"].Float()
+	value().String()
+	(s.field).Bool()
+	value().IsNull()
+}
+`
+	)
+	dir := makePackageDir(t, map[string]string{
+		fooName: fooFile,
+	})
+
+	result := analysistest.Run(ignoreTestingErrorf{}, dir, Analyzer)
+	if !assert.Equal(t, 1, len(result)) {
+		t.FailNow()
+	}
+	result0 := result[0]
+	assertDiagnostics(t, []analysis.Diagnostic{
+		{
+			Pos:            filePos(t, result0.Pass, fooName, strings.Index(fooFile, `js.Global().Get("document")`)),
+			Category:       "unsafe-method",
+			Message:        `unsafe method call on syscall/js.Value found: js.Global().Get("document").Call(...)`,
+			SuggestedFixes: rewriteFix,
+		},
+		{
+			Pos:      filePos(t, result0.Pass, fooName, strings.Index(fooFile, `js.Global().Get("document")`)),
+			Category: "unsafe-method",
+			Message:  "unsafe method call on syscall/js.Value found: js.Global().Get(...)",
+		},
+		{
+			Pos:            filePos(t, result0.Pass, fooName, strings.Index(fooFile, "s.field.Get(")),
+			Category:       "unsafe-method",
+			Message:        "unsafe method call on syscall/js.Value found: s.field.Get(...)",
+			SuggestedFixes: rewriteFix,
+		},
+		{
+			Pos:      filePos(t, result0.Pass, fooName, strings.Index(fooFile, "s.ptr.Int(")),
+			Category: "unsafe-method",
+			Message:  "unsafe method call on syscall/js.Value found: s.ptr.Int(...)",
+		},
+		{
+			Pos:            filePos(t, result0.Pass, fooName, strings.Index(fooFile, "values[0].Int(")),
+			Category:       "unsafe-method",
+			Message:        "unsafe method call on syscall/js.Value found: values[0].Int(...)",
+			SuggestedFixes: rewriteFix,
+		},
+		{
+			Pos:            filePos(t, result0.Pass, fooName, strings.Index(fooFile, `m["a"].Float(`)),
+			Category:       "unsafe-method",
+			Message:        `unsafe method call on syscall/js.Value found: m["a"].Float(...)`,
+			SuggestedFixes: rewriteFix,
+		},
+		{
+			Pos:            filePos(t, result0.Pass, fooName, strings.Index(fooFile, "value().String(")),
+			Category:       "unsafe-method",
+			Message:        "unsafe method call on syscall/js.Value found: value().String(...)",
+			SuggestedFixes: rewriteFix,
+		},
+		{
+			Pos:            filePos(t, result0.Pass, fooName, strings.Index(fooFile, "(s.field).Bool(")),
+			Category:       "unsafe-method",
+			Message:        "unsafe method call on syscall/js.Value found: (s.field).Bool(...)",
+			SuggestedFixes: rewriteFix,
+		},
+	}, result0.Diagnostics)
+}
+
+func TestMethodValuesAndPromotedMethods(t *testing.T) {
+	t.Parallel()
+	const (
+		fooName = "foo.go"
+		fooFile = `
+//go:build js && wasm
+
+package foo
+
+import (
+	"syscall/js"
+)
+
+type Elem struct {
+	js.Value
+}
+
+type Wrapper struct {
+	*Elem
+}
+
+func Foo(v js.Value, e Elem, w Wrapper, fn js.Func) {
+	get := v.Get
+	get("x")
+	valueOf := js.ValueOf
+	valueOf(nil)
+	getExpr := js.Value.Get
+	getExpr(v, "x")
+	e.Call("x")
+	e.Value.Int()
+	e.IsNull()
+	w.Truthy()
+	fn.Invoke()
+	fn.Release()
+	release := fn.Release
+	release()
+}
+`
+	)
+	dir := makePackageDir(t, map[string]string{
+		fooName: fooFile,
+	})
+
+	result := analysistest.Run(ignoreTestingErrorf{}, dir, Analyzer)
+	if !assert.Equal(t, 1, len(result)) {
+		t.FailNow()
+	}
+	result0 := result[0]
+	assertDiagnostics(t, []analysis.Diagnostic{
+		{
+			Pos:      filePos(t, result0.Pass, fooName, strings.Index(fooFile, "v.Get\n")),
+			Category: "unsafe-method-value",
+			Message:  "unsafe method value of syscall/js.Value found: v.Get",
+		},
+		{
+			Pos:      filePos(t, result0.Pass, fooName, strings.Index(fooFile, "js.ValueOf\n")),
+			Category: "unsafe-func-value",
+			Message:  "unsafe function value from syscall/js found: js.ValueOf",
+		},
+		{
+			Pos:      filePos(t, result0.Pass, fooName, strings.Index(fooFile, "js.Value.Get\n")),
+			Category: "unsafe-method-value",
+			Message:  "unsafe method value of syscall/js.Value found: js.Value.Get",
+		},
+		{
+			Pos:      filePos(t, result0.Pass, fooName, strings.Index(fooFile, "e.Call(")),
+			Category: "unsafe-method",
+			Message:  "unsafe method call on syscall/js.Value found: e.Call(...)",
+		},
+		{
+			Pos:            filePos(t, result0.Pass, fooName, strings.Index(fooFile, "e.Value.Int(")),
+			Category:       "unsafe-method",
+			Message:        "unsafe method call on syscall/js.Value found: e.Value.Int(...)",
+			SuggestedFixes: rewriteFix,
+		},
+		{
+			Pos:      filePos(t, result0.Pass, fooName, strings.Index(fooFile, "w.Truthy(")),
+			Category: "unsafe-method",
+			Message:  "unsafe method call on syscall/js.Value found: w.Truthy(...)",
+		},
+		{
+			Pos:      filePos(t, result0.Pass, fooName, strings.Index(fooFile, "fn.Invoke(")),
+			Category: "unsafe-method",
+			Message:  "unsafe method call on syscall/js.Value found: fn.Invoke(...)",
+		},
+	}, result0.Diagnostics)
+}
+
+func TestPromotedMethodFromOtherPackage(t *testing.T) {
+	t.Parallel()
+	const (
+		domName = "src/dom/dom.go"
+		domFile = `
+//go:build js && wasm
+
+package dom
+
+import (
+	"syscall/js"
+)
+
+type Elem struct {
+	js.Value
+}
+`
+		fooName = "src/foo/foo.go"
+		fooFile = `
+//go:build js && wasm
+
+package foo
+
+import (
+	"dom"
+)
+
+func Foo(e dom.Elem) {
+	e.Call("x")
+}
+`
+	)
+	dir := makePackageDir(t, map[string]string{
+		domName: domFile,
+		fooName: fooFile,
+	})
+
+	result := analysistest.Run(ignoreTestingErrorf{}, dir, Analyzer, "foo")
+	if !assert.Equal(t, 1, len(result)) {
+		t.FailNow()
+	}
+	result0 := result[0]
+	assertDiagnostics(t, []analysis.Diagnostic{
+		{
+			Pos:      filePos(t, result0.Pass, "foo.go", strings.Index(fooFile, "e.Call(")),
+			Category: "unsafe-method",
+			Message:  "unsafe method call on syscall/js.Value found: e.Call(...)",
+		},
+	}, result0.Diagnostics)
+}
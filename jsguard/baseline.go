@@ -0,0 +1,581 @@
+//go:build !js
+
+package jsguard
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+var (
+	baselinePath      string
+	writeBaselinePath string
+)
+
+// AddBaselineFlags adds the -baseline and -write-baseline flags to flags.
+// A baseline file covers all of jsguard's analyzers, so commands add these flags once instead of once per analyzer.
+func AddBaselineFlags(flags *flag.FlagSet) {
+	flags.StringVar(&baselinePath, "baseline", "", "path to a baseline file. Only findings missing from the baseline are reported.")
+	flags.StringVar(&writeBaselinePath, "write-baseline", "", "path to write a baseline file with all current findings, instead of reporting them")
+}
+
+const baselineVersion = 1
+
+// baselineFile is the JSON format of a baseline file
+type baselineFile struct {
+	Version int             `json:"version"`
+	Entries []baselineEntry `json:"entries"`
+}
+
+// baselineEntry identifies a finding independent of its line number, so it survives unrelated edits
+type baselineEntry struct {
+	baselineKey
+	Count int `json:"count"`
+}
+
+// baselineScope is an analyzer and the path of a package it analyzed
+type baselineScope struct {
+	Analyzer string
+	Package  string
+}
+
+type baselineKey struct {
+	Analyzer string `json:"analyzer"`
+	Package  string `json:"package"`
+	Function string `json:"function,omitempty"`
+	Message  string `json:"message"`
+	// Snippet is the diagnostic's source line with whitespace normalized
+	Snippet string `json:"snippet"`
+}
+
+func (k baselineKey) scope() baselineScope {
+	return baselineScope{Analyzer: k.Analyzer, Package: k.Package}
+}
+
+func (k baselineKey) String() string {
+	function := k.Function
+	if function == "" {
+		function = "(package scope)"
+	}
+	return fmt.Sprintf("%s: %s %s: %s: %s", k.Analyzer, k.Package, function, k.Message, k.Snippet)
+}
+
+var baselines struct {
+	sync.Mutex
+	read    map[string]map[baselineKey]int // keyed by path
+	written map[string]*baselineRecord     // keyed by path
+}
+
+// baselineRecord is every finding this process recorded for a baseline file
+type baselineRecord struct {
+	analyzed map[baselineScope]bool
+	counts   map[baselineKey]int
+}
+
+// applyBaseline filters pass's diagnostics with the -baseline file, or records them with -write-baseline.
+// The returned function must be called after all diagnostics are reported.
+func applyBaseline(pass *analysis.Pass) (finish func() error, err error) {
+	switch {
+	case !inAnalysedModule(pass):
+		// dependencies are only analyzed for facts, so their findings are never reported
+		return func() error { return nil }, nil
+	case writeBaselinePath != "":
+		return writeBaseline(pass, writeBaselinePath)
+	case baselinePath != "":
+		return filterBaseline(pass, baselinePath, logFixedBaselineEntry(baselinePath))
+	default:
+		return func() error { return nil }, nil
+	}
+}
+
+// logFixedBaselineEntry returns a function to log entries in the baseline file at path which were not found again
+func logFixedBaselineEntry(path string) func(baselineKey) {
+	return func(key baselineKey) {
+		log.Printf("Fixed finding is still in baseline %s, remove it or run again with -write-baseline: %s", path, key)
+	}
+}
+
+// analysedModule caches the root directory of the module containing the working directory, or "" if there is none
+var analysedModule struct {
+	once sync.Once
+	dir  string
+}
+
+// inAnalysedModule returns true if pass's package belongs to the module being analyzed, rather than the standard library or another module it depends on.
+// Packages outside of any module, like in GOPATH mode, belong to it unless they're in GOROOT.
+func inAnalysedModule(pass *analysis.Pass) bool {
+	if len(pass.Files) == 0 || inGOROOT(pass) {
+		return false
+	}
+	return inAnalysedModuleDir(filepath.Dir(pass.Fset.File(pass.Files[0].Pos()).Name()))
+}
+
+// inAnalysedModuleDir returns true if the package in dir belongs to the module being analyzed, like inAnalysedModule
+func inAnalysedModuleDir(dir string) bool {
+	analysedModule.once.Do(func() {
+		if wd, err := os.Getwd(); err == nil {
+			analysedModule.dir = moduleRoot(wd)
+		}
+	})
+	root := moduleRoot(dir)
+	if root == "" {
+		return true
+	}
+	rel, err := filepath.Rel(root, dir)
+	isVendored := err != nil || rel == "vendor" || strings.HasPrefix(rel, "vendor"+string(filepath.Separator))
+	return root == analysedModule.dir && !isVendored
+}
+
+// moduleRoot returns the nearest directory containing a go.mod file, starting with dir, or "" if there is none
+func moduleRoot(dir string) string {
+	for searchDir := dir; ; searchDir = filepath.Dir(searchDir) {
+		if _, err := os.Stat(filepath.Join(searchDir, "go.mod")); err == nil {
+			return searchDir
+		}
+		if filepath.Dir(searchDir) == searchDir {
+			return ""
+		}
+	}
+}
+
+// writeBaseline records pass's diagnostics instead of reporting them, then merges all diagnostics recorded so far into the baseline file at path.
+// Other processes may write the same file, like when go vet runs each package in its own process, so entries for packages this process didn't analyze are kept.
+func writeBaseline(pass *analysis.Pass, path string) (func() error, error) {
+	keyer := newBaselineKeyer(pass)
+	var keys []baselineKey
+	pass.Report = func(diagnostic analysis.Diagnostic) {
+		keys = append(keys, keyer.key(diagnostic))
+	}
+	return func() error {
+		baselines.Lock()
+		defer baselines.Unlock()
+		if baselines.written == nil {
+			baselines.written = make(map[string]*baselineRecord)
+		}
+		record := baselines.written[path]
+		if record == nil {
+			record = &baselineRecord{
+				analyzed: make(map[baselineScope]bool),
+				counts:   make(map[baselineKey]int),
+			}
+			baselines.written[path] = record
+		}
+		record.analyzed[baselineScope{Analyzer: pass.Analyzer.Name, Package: pass.Pkg.Path()}] = true
+		for _, key := range keys {
+			record.counts[key]++
+		}
+		// merge after each package, since analyzers don't know which package is last
+		return mergeBaselineFile(path, record)
+	}, nil
+}
+
+// mergeBaselineFile replaces the entries in the baseline file at path for each analyzer and package in record, keeping the rest.
+// The file is locked while it's updated, since other processes may update it at the same time.
+func mergeBaselineFile(path string, record *baselineRecord) error {
+	unlock, err := lockFile(path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	counts, err := readBaselineFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		counts = make(map[baselineKey]int)
+	case err != nil:
+		return err
+	}
+	for key := range counts {
+		if record.analyzed[key.scope()] {
+			delete(counts, key)
+		}
+	}
+	for key, count := range record.counts {
+		counts[key] += count
+	}
+	return writeBaselineFile(path, counts)
+}
+
+const (
+	lockRetryInterval = 10 * time.Millisecond
+	lockTimeout       = 30 * time.Second
+)
+
+// lockFile creates the file at path, waiting for another process to remove it first.
+// Returns a function to remove it.
+func lockFile(path string) (unlock func(), err error) {
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			return func() {
+				_ = file.Close()
+				_ = os.Remove(path)
+			}, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for lock file %s, remove it if jsguard is no longer running", path)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// filterBaseline drops pass's diagnostics found in the baseline file at path.
+// Calls reportFixed for each baseline entry of this analyzer and package which was not found again.
+func filterBaseline(pass *analysis.Pass, path string, reportFixed func(baselineKey)) (func() error, error) {
+	counts, err := readBaseline(path)
+	if err != nil {
+		return nil, err
+	}
+	remaining := make(map[baselineKey]int)
+	for key, count := range counts {
+		if key.Analyzer == pass.Analyzer.Name && key.Package == pass.Pkg.Path() {
+			remaining[key] = count
+		}
+	}
+	keyer := newBaselineKeyer(pass)
+	report := pass.Report
+	pass.Report = func(diagnostic analysis.Diagnostic) {
+		key := keyer.key(diagnostic)
+		if remaining[key] > 0 {
+			remaining[key]--
+			return
+		}
+		report(diagnostic)
+	}
+	return func() error {
+		for _, key := range fixedBaselineEntries(remaining) {
+			reportFixed(key)
+		}
+		return nil
+	}, nil
+}
+
+// fixedBaselineEntries returns the keys with remaining counts, which were not found again
+func fixedBaselineEntries(remaining map[baselineKey]int) []baselineKey {
+	var fixed []baselineKey
+	for key, count := range remaining {
+		for i := 0; i < count; i++ {
+			fixed = append(fixed, key)
+		}
+	}
+	sortBaselineKeys(fixed)
+	return fixed
+}
+
+// readBaseline returns the counts in the baseline file at path, reading it once per process
+func readBaseline(path string) (map[baselineKey]int, error) {
+	baselines.Lock()
+	defer baselines.Unlock()
+	if counts, ok := baselines.read[path]; ok {
+		return counts, nil
+	}
+	counts, err := readBaselineFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if baselines.read == nil {
+		baselines.read = make(map[string]map[baselineKey]int)
+	}
+	baselines.read[path] = counts
+	return counts, nil
+}
+
+func readBaselineFile(path string) (map[baselineKey]int, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file baselineFile
+	if err := json.Unmarshal(contents, &file); err != nil {
+		return nil, fmt.Errorf("invalid baseline file %s: %w", path, err)
+	}
+	if file.Version != baselineVersion {
+		return nil, fmt.Errorf("unsupported baseline file version %d in %s, expected %d", file.Version, path, baselineVersion)
+	}
+	counts := make(map[baselineKey]int)
+	for _, entry := range file.Entries {
+		counts[entry.baselineKey] += entry.Count
+	}
+	return counts, nil
+}
+
+func writeBaselineFile(path string, counts map[baselineKey]int) error {
+	keys := make([]baselineKey, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sortBaselineKeys(keys)
+	file := baselineFile{Version: baselineVersion, Entries: []baselineEntry{}}
+	for _, key := range keys {
+		file.Entries = append(file.Entries, baselineEntry{baselineKey: key, Count: counts[key]})
+	}
+	contents, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	// replace the file at once, so other processes never read it partly written
+	tempFile, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	_, err = tempFile.Write(append(contents, '\n'))
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tempFile.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tempFile.Name())
+	}
+	return err
+}
+
+func sortBaselineKeys(keys []baselineKey) {
+	sort.Slice(keys, func(a, b int) bool {
+		keyA, keyB := keys[a], keys[b]
+		switch {
+		case keyA.Analyzer != keyB.Analyzer:
+			return keyA.Analyzer < keyB.Analyzer
+		case keyA.Package != keyB.Package:
+			return keyA.Package < keyB.Package
+		case keyA.Function != keyB.Function:
+			return keyA.Function < keyB.Function
+		case keyA.Snippet != keyB.Snippet:
+			return keyA.Snippet < keyB.Snippet
+		default:
+			return keyA.Message < keyB.Message
+		}
+	})
+}
+
+// baselineKeyer creates baseline keys for a pass's diagnostics
+type baselineKeyer struct {
+	pass    *analysis.Pass
+	sources map[*token.File][]byte
+}
+
+func newBaselineKeyer(pass *analysis.Pass) *baselineKeyer {
+	return &baselineKeyer{
+		pass:    pass,
+		sources: make(map[*token.File][]byte),
+	}
+}
+
+func (b *baselineKeyer) key(diagnostic analysis.Diagnostic) baselineKey {
+	key := baselineKey{
+		Analyzer: b.pass.Analyzer.Name,
+		Package:  b.pass.Pkg.Path(),
+		Message:  diagnostic.Message,
+	}
+	for _, file := range b.pass.Files {
+		if file.Pos() <= diagnostic.Pos && diagnostic.Pos <= file.End() {
+			key.Function = enclosingFuncName(file, diagnostic.Pos)
+			key.Snippet = b.snippet(file, diagnostic.Pos)
+		}
+	}
+	return key
+}
+
+// snippet returns the source line containing pos, with whitespace normalized
+func (b *baselineKeyer) snippet(file *ast.File, pos token.Pos) string {
+	tokenFile := b.pass.Fset.File(pos)
+	src, ok := b.sources[tokenFile]
+	if !ok {
+		_, src, _ = readSource(b.pass, file)
+		b.sources[tokenFile] = src
+	}
+	return lineSnippet(tokenFile, src, pos)
+}
+
+// lineSnippet returns the line of src containing pos, with whitespace normalized, or "" if src is nil
+func lineSnippet(tokenFile *token.File, src []byte, pos token.Pos) string {
+	if src == nil {
+		return ""
+	}
+	start := tokenFile.Offset(tokenFile.LineStart(tokenFile.Line(pos)))
+	end := len(src)
+	if newline := bytes.IndexByte(src[start:], '\n'); newline != -1 {
+		end = start + newline
+	}
+	return strings.Join(strings.Fields(string(src[start:end])), " ")
+}
+
+// readSource returns the source for file, if it's unchanged since being parsed
+func readSource(pass *analysis.Pass, file *ast.File) (*token.File, []byte, bool) {
+	tokenFile := pass.Fset.File(file.Pos())
+	src, err := os.ReadFile(tokenFile.Name())
+	if err != nil || len(src) != tokenFile.Size() {
+		return nil, nil, false
+	}
+	return tokenFile, src, true
+}
+
+// enclosingFuncName returns the name of the function declaration containing pos, like "Foo" or "Value.Get", or "" if there isn't one.
+// Only file's syntax is used, so findings merged from several runs get the same names, see ApplyBaseline.
+func enclosingFuncName(file *ast.File, pos token.Pos) string {
+	for _, decl := range file.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok || pos < funcDecl.Pos() || pos >= funcDecl.End() {
+			continue
+		}
+		if funcDecl.Recv == nil || len(funcDecl.Recv.List) == 0 {
+			return funcDecl.Name.Name
+		}
+		return receiverExprName(funcDecl.Recv.List[0].Type) + "." + funcDecl.Name.Name
+	}
+	return ""
+}
+
+// receiverExprName returns the name of a method receiver's type, like "Value" for *Value or List[T]
+func receiverExprName(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		return expr.Name
+	case *ast.StarExpr:
+		return receiverExprName(expr.X)
+	case *ast.ParenExpr:
+		return receiverExprName(expr.X)
+	case *ast.IndexExpr:
+		return receiverExprName(expr.X)
+	case *ast.IndexListExpr:
+		return receiverExprName(expr.X)
+	default:
+		return ""
+	}
+}
+
+// BaselineFinding is a finding a command filters or records with a baseline, rather than an analyzer, like when it merges the findings of several runs
+type BaselineFinding struct {
+	Analyzer string
+	// Package is the path of the finding's package
+	Package  string
+	Message  string
+	Filename string
+	Line     int
+	Column   int
+}
+
+// ApplyBaseline filters findings with the baseline file at path, or writes all of them to the baseline file at writePath instead of reporting them.
+// Returns whether each finding should still be reported.
+//
+// Unlike the -write-baseline flag, writePath is replaced, since findings should include every analyzed package.
+// Findings' files must be unchanged since they were analyzed.
+func ApplyBaseline(path, writePath string, findings []BaselineFinding) ([]bool, error) {
+	reported := make([]bool, len(findings))
+	keys := make([]*baselineKey, len(findings))
+	keyer := newFindingKeyer()
+	for i, finding := range findings {
+		reported[i] = true
+		if !isGOROOTFile(finding.Filename) && inAnalysedModuleDir(filepath.Dir(finding.Filename)) {
+			key := keyer.key(finding)
+			keys[i] = &key
+		}
+	}
+
+	switch {
+	case writePath != "":
+		counts := make(map[baselineKey]int)
+		for i, key := range keys {
+			if key != nil {
+				counts[*key]++
+				reported[i] = false
+			}
+		}
+		return reported, writeBaselineFile(writePath, counts)
+	case path != "":
+		counts, err := readBaseline(path)
+		if err != nil {
+			return nil, err
+		}
+		// packages without findings aren't known, so only their entries can't be reported as fixed
+		analyzed := make(map[baselineScope]bool)
+		for _, key := range keys {
+			if key != nil {
+				analyzed[key.scope()] = true
+			}
+		}
+		remaining := make(map[baselineKey]int)
+		for key, count := range counts {
+			if analyzed[key.scope()] {
+				remaining[key] = count
+			}
+		}
+		for i, key := range keys {
+			if key != nil && remaining[*key] > 0 {
+				remaining[*key]--
+				reported[i] = false
+			}
+		}
+		logFixed := logFixedBaselineEntry(path)
+		for _, key := range fixedBaselineEntries(remaining) {
+			logFixed(key)
+		}
+	}
+	return reported, nil
+}
+
+// findingKeyer creates baseline keys for findings, parsing their files like a baselineKeyer's pass
+type findingKeyer struct {
+	fset  *token.FileSet
+	files map[string]*parsedFile // keyed by file name
+}
+
+type parsedFile struct {
+	file *ast.File // nil if the file could not be read
+	src  []byte
+}
+
+func newFindingKeyer() *findingKeyer {
+	return &findingKeyer{
+		fset:  token.NewFileSet(),
+		files: make(map[string]*parsedFile),
+	}
+}
+
+func (f *findingKeyer) key(finding BaselineFinding) baselineKey {
+	key := baselineKey{
+		Analyzer: finding.Analyzer,
+		Package:  finding.Package,
+		Message:  finding.Message,
+	}
+	parsed, ok := f.files[finding.Filename]
+	if !ok {
+		parsed = &parsedFile{}
+		if src, err := os.ReadFile(finding.Filename); err == nil {
+			parsed.file, _ = parser.ParseFile(f.fset, finding.Filename, src, parser.SkipObjectResolution)
+			parsed.src = src
+		}
+		f.files[finding.Filename] = parsed
+	}
+	if parsed.file == nil {
+		return key
+	}
+	tokenFile := f.fset.File(parsed.file.Pos())
+	if finding.Line < 1 || finding.Line > tokenFile.LineCount() {
+		return key
+	}
+	pos := tokenFile.LineStart(finding.Line) + token.Pos(finding.Column-1)
+	key.Function = enclosingFuncName(parsed.file, pos)
+	key.Snippet = lineSnippet(tokenFile, parsed.src, pos)
+	return key
+}
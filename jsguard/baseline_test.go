@@ -0,0 +1,242 @@
+//go:build !js
+
+package jsguard
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hack-pad/safejs/internal/assert"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+// baselineAnalyzer runs Analyzer with a baseline, like the -baseline and -write-baseline flags
+func baselineAnalyzer(apply func(*analysis.Pass) (func() error, error)) *analysis.Analyzer {
+	return &analysis.Analyzer{
+		Name: Analyzer.Name,
+		Doc:  Analyzer.Doc,
+		Run: func(pass *analysis.Pass) (interface{}, error) {
+			finish, err := apply(pass)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := run(pass); err != nil {
+				return nil, err
+			}
+			return nil, finish()
+		},
+	}
+}
+
+func TestBaseline(t *testing.T) {
+	t.Parallel()
+	const (
+		fooName = "src/foo/foo.go"
+		fooFile = `
+//go:build js && wasm
+
+package foo
+
+import (
+	"syscall/js"
+)
+
+func Foo(v js.Value) {
+	v.Get("a")
+	v.Get("b")
+	v.Get("b")
+}
+`
+		// lines shifted, one "b" fixed, and a new "c" added
+		fooFileUpdated = `
+//go:build js && wasm
+
+package foo
+
+import (
+	"syscall/js"
+)
+
+// Foo does foo things
+func Foo(v js.Value) {
+	v.Get("a")
+
+	  v.Get("b")
+	v.Get("c")
+}
+`
+	)
+	baselineFile := filepath.Join(t.TempDir(), "baseline.json")
+	dir := makePackageDir(t, map[string]string{
+		fooName: fooFile,
+	})
+	result := analysistest.Run(ignoreTestingErrorf{}, dir, baselineAnalyzer(func(pass *analysis.Pass) (func() error, error) {
+		return writeBaseline(pass, baselineFile)
+	}), "foo")
+	if !assert.Equal(t, 1, len(result)) {
+		t.FailNow()
+	}
+	assert.NoError(t, result[0].Err)
+	assert.Equal(t, 0, len(result[0].Diagnostics))
+	contents, err := os.ReadFile(baselineFile)
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+  "version": 1,
+  "entries": [
+    {
+      "analyzer": "jsguard",
+      "package": "foo",
+      "function": "Foo",
+      "message": "unsafe method call on syscall/js.Value found: v.Get(...)",
+      "snippet": "v.Get(\"a\")",
+      "count": 1
+    },
+    {
+      "analyzer": "jsguard",
+      "package": "foo",
+      "function": "Foo",
+      "message": "unsafe method call on syscall/js.Value found: v.Get(...)",
+      "snippet": "v.Get(\"b\")",
+      "count": 2
+    }
+  ]
+}
+`, string(contents))
+
+	dir = makePackageDir(t, map[string]string{
+		fooName: fooFileUpdated,
+	})
+	var fixed []string
+	result = analysistest.Run(ignoreTestingErrorf{}, dir, baselineAnalyzer(func(pass *analysis.Pass) (func() error, error) {
+		return filterBaseline(pass, baselineFile, func(key baselineKey) {
+			fixed = append(fixed, key.String())
+		})
+	}), "foo")
+	if !assert.Equal(t, 1, len(result)) {
+		t.FailNow()
+	}
+	result0 := result[0]
+	assert.NoError(t, result0.Err)
+	assertDiagnostics(t, []analysis.Diagnostic{
+		{
+			Pos:            filePos(t, result0.Pass, "foo", strings.Index(fooFileUpdated, `v.Get("c")`)),
+			Category:       "unsafe-method",
+			Message:        "unsafe method call on syscall/js.Value found: v.Get(...)",
+			SuggestedFixes: rewriteFix,
+		},
+	}, result0.Diagnostics)
+	assert.Equal(t, []string{
+		`jsguard: foo Foo: unsafe method call on syscall/js.Value found: v.Get(...): v.Get("b")`,
+	}, fixed)
+}
+
+func TestWriteBaselineKeepsOtherPackages(t *testing.T) {
+	t.Parallel()
+	const (
+		fooName = "src/foo/foo.go"
+		fooFile = `
+//go:build js && wasm
+
+package foo
+
+import (
+	"syscall/js"
+)
+
+func (v *Value) Foo() {
+	v.value.Get("a")
+}
+
+type Value struct {
+	value js.Value
+}
+`
+	)
+	baselineFile := filepath.Join(t.TempDir(), "baseline.json")
+	// like another process's entries when go vet runs each package separately
+	assert.NoError(t, writeBaselineFile(baselineFile, map[baselineKey]int{
+		{Analyzer: "jsguard", Package: "bar", Function: "Bar", Message: "bar message", Snippet: "bar()"}:        1,
+		{Analyzer: "jsguard", Package: "foo", Function: "Foo", Message: "fixed message", Snippet: "fixed()"}:    1,
+		{Analyzer: "funcleak", Package: "foo", Function: "Foo", Message: "funcleak message", Snippet: "leak()"}: 2,
+	}))
+	dir := makePackageDir(t, map[string]string{
+		fooName: fooFile,
+	})
+	result := analysistest.Run(ignoreTestingErrorf{}, dir, baselineAnalyzer(func(pass *analysis.Pass) (func() error, error) {
+		return writeBaseline(pass, baselineFile)
+	}), "foo")
+	if !assert.Equal(t, 1, len(result)) {
+		t.FailNow()
+	}
+	assert.NoError(t, result[0].Err)
+	counts, err := readBaselineFile(baselineFile)
+	assert.NoError(t, err)
+	assert.Equal(t, map[baselineKey]int{
+		{Analyzer: "jsguard", Package: "bar", Function: "Bar", Message: "bar message", Snippet: "bar()"}:        1,
+		{Analyzer: "funcleak", Package: "foo", Function: "Foo", Message: "funcleak message", Snippet: "leak()"}: 2,
+		{
+			Analyzer: "jsguard",
+			Package:  "foo",
+			Function: "Value.Foo",
+			Message:  "unsafe method call on syscall/js.Value found: v.value.Get(...)",
+			Snippet:  `v.value.Get("a")`,
+		}: 1,
+	}, counts)
+	_, err = os.Stat(baselineFile + ".lock")
+	assert.Equal(t, true, os.IsNotExist(err))
+}
+
+func TestBaselineSkipsDependencies(t *testing.T) { //nolint:paralleltest // Sets the baseline flags shared by all analyzers
+	const (
+		fooName = "src/foo/foo.go"
+		fooFile = `
+//go:build js && wasm
+
+package foo
+
+import (
+	"os"
+	"syscall/js"
+)
+
+func document() js.Value {
+	return js.Global().Get("document")
+}
+
+func Foo() {
+	document()
+	os.Getwd()
+}
+`
+	)
+	dir := makePackageDir(t, map[string]string{
+		fooName: fooFile,
+	})
+	baselineFile := filepath.Join(t.TempDir(), "baseline.json")
+	flags := flag.NewFlagSet("jsguard", flag.ContinueOnError)
+	AddBaselineFlags(flags)
+	assert.NoError(t, flags.Set("write-baseline", baselineFile))
+	t.Cleanup(func() {
+		assert.NoError(t, flags.Set("write-baseline", ""))
+	})
+
+	// UnsafeCallerAnalyzer exports facts, so it also runs on dependencies like os and syscall
+	analysistest.Run(ignoreTestingErrorf{}, dir, UnsafeCallerAnalyzer, "foo")
+	counts, err := readBaseline(baselineFile)
+	assert.NoError(t, err)
+	var keys []baselineKey
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	assert.Equal(t, []baselineKey{{
+		Analyzer: "unsafecaller",
+		Package:  "foo",
+		Function: "Foo",
+		Message:  "document(...) may panic through syscall/js: foo.document -> js.Value.Get",
+		Snippet:  "document()",
+	}}, keys)
+}
@@ -0,0 +1,272 @@
+//go:build !js
+
+package jsguard
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"math"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// CallbackBlockAnalyzer reports blocking operations inside js.FuncOf and safejs.FuncOf callbacks.
+//
+// Callbacks run on the JavaScript event loop, so blocking there deadlocks the program with "all goroutines are asleep".
+// Awaiting a Promise synchronously is reported as the channel receive it's built on.
+var CallbackBlockAnalyzer = &analysis.Analyzer{
+	Name: "callbackblock",
+	Doc:  "report blocking operations inside js.FuncOf and safejs.FuncOf callbacks",
+	Run:  runCallbackBlock,
+}
+
+// blockingFuncs are known blocking functions, keyed by package path or receiver type and then function name
+var blockingFuncs = map[string]map[string]bool{
+	"time":            {"Sleep": true},
+	"sync.WaitGroup":  {"Wait": true},
+	"sync.Cond":       {"Wait": true},
+	"net/http":        {"Get": true, "Head": true, "Post": true, "PostForm": true},
+	"net/http.Client": {"Do": true, "Get": true, "Head": true, "Post": true, "PostForm": true},
+	"net.Dialer":      {"Dial": true, "DialContext": true},
+	"net":             {"Dial": true, "DialTimeout": true},
+	"os/exec.Cmd":     {"CombinedOutput": true, "Output": true, "Run": true, "Wait": true},
+}
+
+const callbackBlockAdvice = "run blocking code in a new goroutine or return a Promise"
+
+func runCallbackBlock(pass *analysis.Pass) (interface{}, error) {
+	finishBaseline, err := applyBaseline(pass)
+	if err != nil {
+		return nil, err
+	}
+	honorIgnoreDirectives(pass)
+	decls := funcDecls(pass)
+	checker := &blockChecker{
+		pass:       pass,
+		decls:      decls,
+		summaries:  make(map[*types.Func]*callbackOp),
+		inProgress: make(map[*types.Func]int),
+		recursedTo: noRecursion,
+	}
+	for _, file := range pass.Files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok || !isFuncOfCall(pass, call) {
+				return true
+			}
+			funcOfName := formatNode(pass.Fset, call.Fun)
+			body := funcOfCallback(pass, decls, call)
+			if body == nil {
+				return true
+			}
+			for _, blocking := range checker.blockingOps(body) {
+				reportf(pass, ruleBlockingCallback, blocking.pos, "%s blocks inside %s(...) callback: %s", blocking.describe(), funcOfName, callbackBlockAdvice)
+			}
+			return true
+		})
+	}
+	return nil, finishBaseline()
+}
+
+// funcDecls returns the declarations of functions and methods with bodies in this package
+func funcDecls(pass *analysis.Pass) map[*types.Func]*ast.FuncDecl {
+	decls := make(map[*types.Func]*ast.FuncDecl)
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			if funcDecl, ok := decl.(*ast.FuncDecl); ok && funcDecl.Body != nil {
+				if fn, ok := pass.TypesInfo.Defs[funcDecl.Name].(*types.Func); ok {
+					decls[fn] = funcDecl
+				}
+			}
+		}
+	}
+	return decls
+}
+
+// funcOfCallback returns the body of the callback passed to funcOfCall, if it's a function literal or a function declared in this package
+func funcOfCallback(pass *analysis.Pass, decls map[*types.Func]*ast.FuncDecl, funcOfCall *ast.CallExpr) *ast.BlockStmt {
+	if len(funcOfCall.Args) != 1 {
+		return nil
+	}
+	switch callback := unparen(funcOfCall.Args[0]).(type) {
+	case *ast.FuncLit:
+		return callback.Body
+	case *ast.Ident, *ast.SelectorExpr:
+		if decl := decls[calledFunc(pass, callback)]; decl != nil {
+			return decl.Body
+		}
+	}
+	return nil
+}
+
+// calledFunc returns the function referred to by expr, if it's a function or method
+func calledFunc(pass *analysis.Pass, expr ast.Expr) *types.Func {
+	switch expr := unparen(expr).(type) {
+	case *ast.Ident:
+		fn, _ := pass.TypesInfo.Uses[expr].(*types.Func)
+		return fn
+	case *ast.SelectorExpr:
+		return selectedFunc(pass, expr)
+	default:
+		return nil
+	}
+}
+
+// callbackOp is an operation inside a callback, possibly reached through a chain of same-package calls
+type callbackOp struct {
+	pos   token.Pos
+	op    string
+	chain []string
+}
+
+func (o callbackOp) describe() string {
+	if len(o.chain) == 0 {
+		return o.op
+	}
+	return fmt.Sprintf("%s (via %s)", o.op, strings.Join(o.chain, " -> "))
+}
+
+// noRecursion is blockChecker.recursedTo's value when no recursive calls were found
+const noRecursion = math.MaxInt
+
+type blockChecker struct {
+	pass  *analysis.Pass
+	decls map[*types.Func]*ast.FuncDecl
+	// summaries caches the first blocking operation of each same-package function checked so far
+	summaries map[*types.Func]*callbackOp
+	// inProgress holds the depth of each function whose summary is being computed
+	inProgress map[*types.Func]int
+	// recursedTo is the lowest depth in inProgress reached by a recursive call in the current summary.
+	// Until that function's summary is done, summaries which don't block are incomplete and can't be cached.
+	recursedTo int
+}
+
+// blockingOps returns blocking operations which run synchronously in body
+func (b *blockChecker) blockingOps(body ast.Node) []callbackOp {
+	var ops []callbackOp
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.GoStmt:
+			// the call runs in a new goroutine, but its function and arguments are evaluated first
+			if _, isFuncLit := unparen(node.Call.Fun).(*ast.FuncLit); !isFuncLit {
+				ops = append(ops, b.blockingOps(node.Call.Fun)...)
+			}
+			for _, arg := range node.Call.Args {
+				ops = append(ops, b.blockingOps(arg)...)
+			}
+			return false
+		case *ast.FuncLit:
+			return false // only reached if not called immediately, see CallExpr below
+		case *ast.UnaryExpr:
+			if node.Op == token.ARROW {
+				ops = append(ops, callbackOp{pos: node.Pos(), op: "channel receive"})
+			}
+		case *ast.SendStmt:
+			ops = append(ops, callbackOp{pos: node.Pos(), op: "channel send"})
+		case *ast.SelectStmt:
+			if !hasDefaultCase(node) {
+				ops = append(ops, callbackOp{pos: node.Pos(), op: "select without default"})
+			}
+			for _, clause := range node.Body.List {
+				for _, stmt := range clause.(*ast.CommClause).Body {
+					ops = append(ops, b.blockingOps(stmt)...)
+				}
+			}
+			return false
+		case *ast.RangeStmt:
+			if _, isChan := b.pass.TypesInfo.TypeOf(node.X).Underlying().(*types.Chan); isChan {
+				ops = append(ops, callbackOp{pos: node.Pos(), op: "range over channel"})
+			}
+		case *ast.CallExpr:
+			if funcLit, ok := unparen(node.Fun).(*ast.FuncLit); ok {
+				ops = append(ops, b.blockingOps(funcLit.Body)...)
+				for _, arg := range node.Args {
+					ops = append(ops, b.blockingOps(arg)...)
+				}
+				return false
+			}
+			ops = append(ops, b.blockingCall(node)...)
+		}
+		return true
+	})
+	return ops
+}
+
+// blockingCall returns blocking operations from calling a known blocking function or a same-package function which blocks
+func (b *blockChecker) blockingCall(call *ast.CallExpr) []callbackOp {
+	fn := calledFunc(b.pass, call.Fun)
+	if fn == nil {
+		return nil
+	}
+	typeName, ok := funcTypeName(fn)
+	if !ok {
+		return nil
+	}
+	if blockingFuncs[typeName][fn.Name()] {
+		return []callbackOp{{pos: call.Pos(), op: formatCall(b.pass.Fset, call)}}
+	}
+	blocking := b.funcBlocks(fn)
+	if blocking == nil {
+		return nil
+	}
+	name := fn.Name()
+	if fn.Type().(*types.Signature).Recv() != nil {
+		name = fmt.Sprintf("%s.%s", strings.TrimPrefix(typeName, fn.Pkg().Path()+"."), fn.Name())
+	}
+	return []callbackOp{{pos: call.Pos(), op: blocking.op, chain: append([]string{name}, blocking.chain...)}}
+}
+
+// funcBlocks returns the first blocking operation in same-package function fn, or nil if it doesn't block.
+// Recursive calls are treated as not blocking while their function's summary is in progress,
+// so a summary which depends on one is only cached once it's known to block or the recursion is resolved.
+func (b *blockChecker) funcBlocks(fn *types.Func) *callbackOp {
+	if blocking, done := b.summaries[fn]; done {
+		return blocking
+	}
+	if depth, recursive := b.inProgress[fn]; recursive {
+		if depth < b.recursedTo {
+			b.recursedTo = depth
+		}
+		return nil
+	}
+	decl := b.decls[fn]
+	if decl == nil {
+		return nil
+	}
+
+	depth := len(b.inProgress)
+	b.inProgress[fn] = depth
+	outerRecursedTo := b.recursedTo
+	b.recursedTo = noRecursion
+	ops := b.blockingOps(decl.Body)
+	delete(b.inProgress, fn)
+	recursedTo := b.recursedTo
+	if recursedTo >= depth {
+		recursedTo = noRecursion // only recursion into fn or deeper, which is now resolved
+	}
+	b.recursedTo = outerRecursedTo
+	if recursedTo < b.recursedTo {
+		b.recursedTo = recursedTo
+	}
+
+	var blocking *callbackOp
+	if len(ops) > 0 {
+		blocking = &ops[0]
+	}
+	if blocking != nil || recursedTo == noRecursion {
+		b.summaries[fn] = blocking
+	}
+	return blocking
+}
+
+func hasDefaultCase(selectStmt *ast.SelectStmt) bool {
+	for _, clause := range selectStmt.Body.List {
+		if clause.(*ast.CommClause).Comm == nil {
+			return true
+		}
+	}
+	return false
+}
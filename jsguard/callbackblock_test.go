@@ -0,0 +1,198 @@
+//go:build !js
+
+package jsguard
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hack-pad/safejs/internal/assert"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestCallbackBlock(t *testing.T) {
+	t.Parallel()
+	const (
+		fooName = "src/foo/foo.go"
+		fooFile = `
+//go:build js && wasm
+
+package foo
+
+import (
+	"net/http"
+	"sync"
+	"syscall/js"
+	"time"
+
+	"github.com/hack-pad/safejs"
+)
+
+type fetcher struct {
+	results chan string
+}
+
+func (f *fetcher) wait() string {
+	return <-f.results
+}
+
+func fetch(url string) {
+	http.Get(url)
+}
+
+func fetchAll(urls []string) {
+	for _, url := range urls {
+		fetch(url)
+	}
+}
+
+func handler(this js.Value, args []js.Value) any {
+	time.Sleep(time.Second)
+	return nil
+}
+
+func Register(f *fetcher) {
+	js.FuncOf(func(this js.Value, args []js.Value) any {
+		var wg sync.WaitGroup
+		wg.Wait()
+		fetchAll(nil)
+		return f.wait()
+	})
+	js.FuncOf(handler)
+	safejs.FuncOf(func(this safejs.Value, args []safejs.Value) any {
+		select {
+		case result := <-f.results:
+			return result
+		default:
+		}
+		go fetch("")
+		go func() {
+			f.results <- f.wait()
+		}()
+		later := func() { f.wait() }
+		_ = later
+		return nil
+	})
+	safejs.FuncOf(func(this safejs.Value, args []safejs.Value) any {
+		func() {
+			for range f.results {
+			}
+		}()
+		return nil
+	})
+}
+`
+	)
+	dir := makePackageDir(t, map[string]string{
+		safejsStubName: safejsStubFile,
+		fooName:        fooFile,
+	})
+
+	result := analysistest.Run(ignoreTestingErrorf{}, dir, CallbackBlockAnalyzer, "foo")
+	if !assert.Equal(t, 1, len(result)) {
+		t.FailNow()
+	}
+	result0 := result[0]
+	pass := result0.Pass
+	assertDiagnostics(t, []analysis.Diagnostic{
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, "wg.Wait()")),
+			Category: "blocking-callback",
+			Message:  "wg.Wait(...) blocks inside js.FuncOf(...) callback: run blocking code in a new goroutine or return a Promise",
+		},
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, "fetchAll(nil)")),
+			Category: "blocking-callback",
+			Message:  "http.Get(...) (via fetchAll -> fetch) blocks inside js.FuncOf(...) callback: run blocking code in a new goroutine or return a Promise",
+		},
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, "f.wait()\n\t})")),
+			Category: "blocking-callback",
+			Message:  "channel receive (via fetcher.wait) blocks inside js.FuncOf(...) callback: run blocking code in a new goroutine or return a Promise",
+		},
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, "time.Sleep(")),
+			Category: "blocking-callback",
+			Message:  "time.Sleep(...) blocks inside js.FuncOf(...) callback: run blocking code in a new goroutine or return a Promise",
+		},
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, "for range f.results")),
+			Category: "blocking-callback",
+			Message:  "range over channel blocks inside safejs.FuncOf(...) callback: run blocking code in a new goroutine or return a Promise",
+		},
+	}, result0.Diagnostics)
+}
+
+func TestCallbackBlockRecursion(t *testing.T) {
+	t.Parallel()
+	const (
+		fooName = "src/foo/foo.go"
+		fooFile = `
+//go:build js && wasm
+
+package foo
+
+import (
+	"syscall/js"
+)
+
+var results chan string
+
+func a(n int) {
+	if n > 0 {
+		b(n - 1)
+	}
+	<-results
+}
+
+func b(n int) {
+	a(n)
+}
+
+func process(string) {}
+
+func Register() {
+	js.FuncOf(func(this js.Value, args []js.Value) any {
+		a(1)
+		return nil
+	})
+	js.FuncOf(func(this js.Value, args []js.Value) any {
+		b(1)
+		return nil
+	})
+	js.FuncOf(func(this js.Value, args []js.Value) any {
+		go process(<-results)
+		return nil
+	})
+}
+`
+	)
+	dir := makePackageDir(t, map[string]string{
+		fooName: fooFile,
+	})
+
+	result := analysistest.Run(ignoreTestingErrorf{}, dir, CallbackBlockAnalyzer, "foo")
+	if !assert.Equal(t, 1, len(result)) {
+		t.FailNow()
+	}
+	result0 := result[0]
+	pass := result0.Pass
+	assertDiagnostics(t, []analysis.Diagnostic{
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, "a(1)")),
+			Category: "blocking-callback",
+			Message:  "channel receive (via a) blocks inside js.FuncOf(...) callback: run blocking code in a new goroutine or return a Promise",
+		},
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, "b(1)")),
+			Category: "blocking-callback",
+			Message:  "channel receive (via b -> a) blocks inside js.FuncOf(...) callback: run blocking code in a new goroutine or return a Promise",
+		},
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, "<-results)")),
+			Category: "blocking-callback",
+			Message:  "channel receive blocks inside js.FuncOf(...) callback: run blocking code in a new goroutine or return a Promise",
+		},
+	}, result0.Diagnostics)
+}
@@ -0,0 +1,172 @@
+//go:build !js
+
+package jsguard
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// CallbackPanicAnalyzer reports panics and program exits inside js.FuncOf and safejs.FuncOf callbacks.
+//
+// Callbacks run on the JavaScript event loop, so a panic there crashes the whole Go program instead of failing the one call.
+// Panics are not reported in functions which recover from them, but exits like log.Fatal are always reported.
+var CallbackPanicAnalyzer = &analysis.Analyzer{
+	Name:      "callbackpanic",
+	Doc:       "report panics and log.Fatal inside js.FuncOf and safejs.FuncOf callbacks",
+	Run:       runCallbackPanic,
+	FactTypes: []analysis.Fact{new(callbackRecoversFact)},
+}
+
+// panicFuncs are known panicking functions, keyed by package path or receiver type and then function name
+var panicFuncs = map[string]map[string]bool{
+	"log":        {"Panic": true, "Panicf": true, "Panicln": true},
+	"log.Logger": {"Panic": true, "Panicf": true, "Panicln": true},
+}
+
+// exitFuncs are known functions which exit the program, keyed by package path or receiver type and then function name
+var exitFuncs = map[string]map[string]bool{
+	"log":        {"Fatal": true, "Fatalf": true, "Fatalln": true},
+	"log.Logger": {"Fatal": true, "Fatalf": true, "Fatalln": true},
+	"os":         {"Exit": true},
+}
+
+// callbackRecoversFact marks functions which call recover(), like recoversFact does for UnsafeCallerAnalyzer
+type callbackRecoversFact struct{}
+
+func (*callbackRecoversFact) AFact() {}
+
+func (*callbackRecoversFact) String() string {
+	return "recovers"
+}
+
+func newCallbackRecoversFact() analysis.Fact {
+	return &callbackRecoversFact{}
+}
+
+const callbackPanicAdvice = "return an error to JavaScript instead, like a rejected Promise"
+
+func runCallbackPanic(pass *analysis.Pass) (interface{}, error) {
+	finishBaseline, err := applyBaseline(pass)
+	if err != nil {
+		return nil, err
+	}
+	honorIgnoreDirectives(pass)
+	decls := funcDecls(pass)
+	exportRecoversFacts(pass, decls, newCallbackRecoversFact)
+	checker := panicChecker{pass: pass, decls: decls, summaries: make(map[panicSummaryKey]*callbackOp)}
+	for _, file := range pass.Files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok || !isFuncOfCall(pass, call) {
+				return true
+			}
+			funcOfName := formatNode(pass.Fset, call.Fun)
+			body := funcOfCallback(pass, decls, call)
+			if body == nil {
+				return true
+			}
+			for _, panicking := range checker.bodyPanicOps(body, false) {
+				reportf(pass, rulePanickingCallback, panicking.pos, "%s crashes the program inside %s(...) callback: %s", panicking.describe(), funcOfName, callbackPanicAdvice)
+			}
+			return true
+		})
+	}
+	return nil, finishBaseline()
+}
+
+// panicSummaryKey identifies a same-package function, checked either on its own or beneath a function which recovers
+type panicSummaryKey struct {
+	fn        *types.Func
+	recovered bool
+}
+
+type panicChecker struct {
+	pass  *analysis.Pass
+	decls map[*types.Func]*ast.FuncDecl
+	// summaries caches the first panic or exit of each same-package function checked so far
+	summaries map[panicSummaryKey]*callbackOp
+}
+
+// bodyPanicOps returns panics and exits which run synchronously in a function body.
+// If recovered is true, a caller recovers from panics, so only exits are returned.
+func (p panicChecker) bodyPanicOps(body *ast.BlockStmt, recovered bool) []callbackOp {
+	return p.panicOps(body, recovered || recovers(p.pass, p.decls, body, newCallbackRecoversFact))
+}
+
+// panicOps returns panics and exits which run synchronously in node
+func (p panicChecker) panicOps(node ast.Node, recovered bool) []callbackOp {
+	var ops []callbackOp
+	ast.Inspect(node, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.GoStmt:
+			return false
+		case *ast.FuncLit:
+			return false // only reached if not called immediately, see CallExpr below
+		case *ast.CallExpr:
+			if funcLit, ok := unparen(node.Fun).(*ast.FuncLit); ok {
+				ops = append(ops, p.bodyPanicOps(funcLit.Body, recovered)...)
+				for _, arg := range node.Args {
+					ops = append(ops, p.panicOps(arg, recovered)...)
+				}
+				return false
+			}
+			ops = append(ops, p.panicCall(node, recovered)...)
+		}
+		return true
+	})
+	return ops
+}
+
+// panicCall returns panics and exits from calling panic, a known panicking or exiting function, or a same-package function which does either
+func (p panicChecker) panicCall(call *ast.CallExpr, recovered bool) []callbackOp {
+	if isBuiltin(p.pass, call, "panic") {
+		if recovered {
+			return nil
+		}
+		return []callbackOp{{pos: call.Pos(), op: formatCall(p.pass.Fset, call)}}
+	}
+	fn := calledFunc(p.pass, call.Fun)
+	if fn == nil {
+		return nil
+	}
+	typeName, ok := funcTypeName(fn)
+	if !ok {
+		return nil
+	}
+	if exitFuncs[typeName][fn.Name()] || (!recovered && panicFuncs[typeName][fn.Name()]) {
+		return []callbackOp{{pos: call.Pos(), op: formatCall(p.pass.Fset, call)}}
+	}
+	panicking := p.funcPanics(fn, recovered)
+	if panicking == nil {
+		return nil
+	}
+	name := fn.Name()
+	if fn.Type().(*types.Signature).Recv() != nil {
+		name = fmt.Sprintf("%s.%s", strings.TrimPrefix(typeName, fn.Pkg().Path()+"."), fn.Name())
+	}
+	return []callbackOp{{pos: call.Pos(), op: panicking.op, chain: append([]string{name}, panicking.chain...)}}
+}
+
+// funcPanics returns the first panic or exit in same-package function fn, or nil if it doesn't do either
+func (p panicChecker) funcPanics(fn *types.Func, recovered bool) *callbackOp {
+	key := panicSummaryKey{fn: fn, recovered: recovered}
+	if panicking, visited := p.summaries[key]; visited {
+		return panicking
+	}
+	decl := p.decls[fn]
+	if decl == nil {
+		return nil
+	}
+	p.summaries[key] = nil // break recursive calls
+	ops := p.bodyPanicOps(decl.Body, recovered)
+	if len(ops) == 0 {
+		return nil
+	}
+	p.summaries[key] = &ops[0]
+	return &ops[0]
+}
@@ -0,0 +1,143 @@
+//go:build !js
+
+package jsguard
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hack-pad/safejs/internal/assert"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestCallbackPanic(t *testing.T) {
+	t.Parallel()
+	const (
+		fooName = "src/foo/foo.go"
+		fooFile = `
+//go:build js && wasm
+
+package foo
+
+import (
+	"errors"
+	"log"
+	"os"
+	"syscall/js"
+
+	"github.com/hack-pad/safejs"
+)
+
+type app struct {
+	logger *log.Logger
+}
+
+func (a *app) mustLoad() {
+	a.logger.Fatalf("failed to load")
+}
+
+func check(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+func guarded(err error) {
+	defer func() {
+		recover()
+	}()
+	check(err)
+	os.Exit(1)
+}
+
+func logRecovered() {
+	if r := recover(); r != nil {
+		log.Println(r)
+	}
+}
+
+func handler(this js.Value, args []js.Value) any {
+	log.Fatal("oops")
+	return nil
+}
+
+func Register(a *app) {
+	js.FuncOf(func(this js.Value, args []js.Value) any {
+		panic("not implemented")
+	})
+	js.FuncOf(handler)
+	safejs.FuncOf(func(this safejs.Value, args []safejs.Value) any {
+		check(errors.New("failed"))
+		a.mustLoad()
+		guarded(nil)
+		go check(nil)
+		later := func() { panic("later") }
+		_ = later
+		return nil
+	})
+	safejs.FuncOf(func(this safejs.Value, args []safejs.Value) any {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Println(r)
+			}
+		}()
+		log.Panicf("recovered")
+		func() {
+			log.Fatalln("not recovered")
+		}()
+		return nil
+	})
+	js.FuncOf(func(this js.Value, args []js.Value) any {
+		defer logRecovered()
+		check(errors.New("recovered by a named function"))
+		return nil
+	})
+	panic("outside callback")
+}
+`
+	)
+	dir := makePackageDir(t, map[string]string{
+		safejsStubName: safejsStubFile,
+		fooName:        fooFile,
+	})
+
+	result := analysistest.Run(ignoreTestingErrorf{}, dir, CallbackPanicAnalyzer, "foo")
+	if !assert.Equal(t, 1, len(result)) {
+		t.FailNow()
+	}
+	result0 := result[0]
+	pass := result0.Pass
+	assertDiagnostics(t, []analysis.Diagnostic{
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, `panic("not implemented")`)),
+			Category: "panicking-callback",
+			Message:  "panic(...) crashes the program inside js.FuncOf(...) callback: return an error to JavaScript instead, like a rejected Promise",
+		},
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, `log.Fatal("oops")`)),
+			Category: "panicking-callback",
+			Message:  "log.Fatal(...) crashes the program inside js.FuncOf(...) callback: return an error to JavaScript instead, like a rejected Promise",
+		},
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, `check(errors.New`)),
+			Category: "panicking-callback",
+			Message:  "panic(...) (via check) crashes the program inside safejs.FuncOf(...) callback: return an error to JavaScript instead, like a rejected Promise",
+		},
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, `a.mustLoad()`)),
+			Category: "panicking-callback",
+			Message:  "a.logger.Fatalf(...) (via app.mustLoad) crashes the program inside safejs.FuncOf(...) callback: return an error to JavaScript instead, like a rejected Promise",
+		},
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, `guarded(nil)`)),
+			Category: "panicking-callback",
+			Message:  "os.Exit(...) (via guarded) crashes the program inside safejs.FuncOf(...) callback: return an error to JavaScript instead, like a rejected Promise",
+		},
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, `log.Fatalln`)),
+			Category: "panicking-callback",
+			Message:  "log.Fatalln(...) crashes the program inside safejs.FuncOf(...) callback: return an error to JavaScript instead, like a rejected Promise",
+		},
+	}, result0.Diagnostics)
+}
@@ -0,0 +1,14 @@
+//go:build !js
+
+// Command jsguard-all runs all of jsguard's analyzers.
+// Select analyzers with flags like -funcleak or -ignorederror=false.
+package main
+
+import (
+	"github.com/hack-pad/safejs/jsguard"
+	"github.com/hack-pad/safejs/jsguard/internal/report"
+)
+
+func main() {
+	report.MultiMain("jsguard-all", jsguard.Analyzers()...)
+}
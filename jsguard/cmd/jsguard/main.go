@@ -5,9 +5,9 @@ package main
 
 import (
 	"github.com/hack-pad/safejs/jsguard"
-	"golang.org/x/tools/go/analysis/singlechecker"
+	"github.com/hack-pad/safejs/jsguard/internal/report"
 )
 
 func main() {
-	singlechecker.Main(jsguard.Analyzer)
+	report.Main(jsguard.Analyzer)
 }
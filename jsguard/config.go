@@ -0,0 +1,272 @@
+//go:build !js
+
+package jsguard
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// configFileName is the config file found in a package's directory or any of its parents, unless the -config flag is set.
+//
+// The file is a small subset of YAML, with lists of extra unsafe and trusted names:
+//
+//	# Third-party bindings which panic like syscall/js
+//	unsafe:
+//	  - github.com/example/dom
+//	  - github.com/example/canvas.Context.Draw
+//	safe: [syscall/js.Value.Truthy]
+//
+// Names may be a package path, a type like "syscall/js.Value", or a function or method like "syscall/js.Value.Get".
+// The most specific name wins, and unsafe wins between names of the same kind.
+const configFileName = ".jsguard.yaml"
+
+var (
+	configPath       string
+	configUnsafeFlag string
+	configSafeFlag   string
+)
+
+// AddConfigFlags adds the -config, -unsafe, and -safe flags to flags.
+// jsguard-all runs several analyzers with one config, so it takes one -unsafe flag rather than one per analyzer.
+func AddConfigFlags(flags *flag.FlagSet) {
+	flags.StringVar(&configPath, "config", "", "path to a config file, defaults to the nearest "+configFileName)
+	flags.StringVar(&configUnsafeFlag, "unsafe", "", "comma-separated packages, types, and functions to consider unsafe, e.g. 'github.com/example/dom'")
+	flags.StringVar(&configSafeFlag, "safe", "", "comma-separated packages, types, and functions to trust, e.g. 'syscall/js.Value.Truthy'")
+}
+
+// config lists extra unsafe and trusted packages, types, and functions
+type config struct {
+	unsafe map[string]bool
+	safe   map[string]bool
+}
+
+var configCache struct {
+	sync.Mutex
+	files map[string]*config // keyed by file path, or "" if none was found
+	dirs  map[string]string  // maps directories to the nearest config file path
+}
+
+// loadConfig returns the config for pass's package, merged with any names from the config flags
+func loadConfig(pass *analysis.Pass) (*config, error) {
+	path := configPath
+	if path == "" && len(pass.Files) > 0 {
+		dir := filepath.Dir(pass.Fset.File(pass.Files[0].Pos()).Name())
+		path = findConfig(dir)
+	}
+	fileConfig, err := readConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &config{
+		unsafe: parseNameList(configUnsafeFlag),
+		safe:   parseNameList(configSafeFlag),
+	}
+	for name := range fileConfig.unsafe {
+		cfg.unsafe[name] = true
+	}
+	for name := range fileConfig.safe {
+		cfg.safe[name] = true
+	}
+	return cfg, nil
+}
+
+// findConfig returns the path of the config file in dir or its nearest parent, or "" if there isn't one
+func findConfig(dir string) string {
+	configCache.Lock()
+	defer configCache.Unlock()
+	if path, ok := configCache.dirs[dir]; ok {
+		return path
+	}
+	path := ""
+	for searchDir := dir; ; searchDir = filepath.Dir(searchDir) {
+		candidate := filepath.Join(searchDir, configFileName)
+		if _, err := os.Stat(candidate); err == nil {
+			path = candidate
+			break
+		}
+		if filepath.Dir(searchDir) == searchDir {
+			break
+		}
+	}
+	if configCache.dirs == nil {
+		configCache.dirs = make(map[string]string)
+	}
+	configCache.dirs[dir] = path
+	return path
+}
+
+func readConfigFile(path string) (*config, error) {
+	configCache.Lock()
+	defer configCache.Unlock()
+	if cfg, ok := configCache.files[path]; ok {
+		return cfg, nil
+	}
+	cfg := &config{unsafe: make(map[string]bool), safe: make(map[string]bool)}
+	if path != "" {
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		cfg, err = parseConfig(path, contents)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if configCache.files == nil {
+		configCache.files = make(map[string]*config)
+	}
+	configCache.files[path] = cfg
+	return cfg, nil
+}
+
+// parseConfig parses a config file's contents. See configFileName for the format.
+func parseConfig(path string, contents []byte) (*config, error) {
+	cfg := &config{unsafe: make(map[string]bool), safe: make(map[string]bool)}
+	var list map[string]bool
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := stripYAMLComment(scanner.Text())
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		if item := strings.TrimPrefix(trimmed, "-"); item != trimmed && line != trimmed {
+			if list == nil {
+				return nil, fmt.Errorf("%s:%d: list item without a key", path, lineNumber)
+			}
+			name, err := parseYAMLString(strings.TrimSpace(item))
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, lineNumber, err)
+			}
+			list[name] = true
+			continue
+		}
+
+		key, value, found := strings.Cut(trimmed, ":")
+		if !found || line != trimmed {
+			return nil, fmt.Errorf("%s:%d: expected 'unsafe:' or 'safe:' followed by a list", path, lineNumber)
+		}
+		switch strings.TrimSpace(key) {
+		case "unsafe":
+			list = cfg.unsafe
+		case "safe":
+			list = cfg.safe
+		default:
+			return nil, fmt.Errorf("%s:%d: unknown key %q", path, lineNumber, strings.TrimSpace(key))
+		}
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		if !strings.HasPrefix(value, "[") || !strings.HasSuffix(value, "]") {
+			return nil, fmt.Errorf("%s:%d: expected a list, found %q", path, lineNumber, value)
+		}
+		for _, item := range strings.Split(strings.TrimSuffix(strings.TrimPrefix(value, "["), "]"), ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			name, err := parseYAMLString(item)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, lineNumber, err)
+			}
+			list[name] = true
+		}
+		list = nil // inline lists can't continue on following lines
+	}
+	return cfg, scanner.Err()
+}
+
+// stripYAMLComment removes a '# comment' from line.
+// Like YAML, a comment starts the line or follows whitespace, and '#' inside a quoted string isn't a comment.
+func stripYAMLComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++ // skip the escaped character
+		case quote == '\'' && c == '\'' && strings.HasPrefix(line[i+1:], "'"):
+			i++ // skip the escaped quote
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && (i == 0 || strings.IndexByte(" \t[,", line[i-1]) != -1):
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return strings.TrimRight(line[:i], " \t")
+		}
+	}
+	return line
+}
+
+func parseYAMLString(s string) (string, error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		return strconv.Unquote(s)
+	case strings.HasPrefix(s, "'"):
+		if len(s) < 2 || !strings.HasSuffix(s, "'") {
+			return "", fmt.Errorf("invalid quoted string: %s", s)
+		}
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	default:
+		return s, nil
+	}
+}
+
+func parseNameList(list string) map[string]bool {
+	names := make(map[string]bool)
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// isSafeCall returns true if fn is safe to call according to the config, falling back to the built-in syscall/js list.
+// A nil config only uses the built-in list.
+func (c *config) isSafeCall(fn *types.Func) bool {
+	typeName, ok := funcTypeName(fn)
+	if !ok {
+		return true
+	}
+	if safe, found := c.lookup(fn); found {
+		return safe
+	}
+	return isSafeCall(typeName, fn.Name())
+}
+
+// isTrusted returns true if fn is explicitly trusted by the config
+func (c *config) isTrusted(fn *types.Func) bool {
+	safe, found := c.lookup(fn)
+	return found && safe
+}
+
+// lookup returns whether fn is safe according to its most specific name in the config, and whether any name was found
+func (c *config) lookup(fn *types.Func) (safe, found bool) {
+	typeName, ok := funcTypeName(fn)
+	if c == nil || !ok {
+		return false, false
+	}
+	for _, name := range []string{typeName + "." + fn.Name(), typeName, fn.Pkg().Path()} {
+		if c.unsafe[name] {
+			return false, true
+		}
+		if c.safe[name] {
+			return true, true
+		}
+	}
+	return false, false
+}
@@ -0,0 +1,226 @@
+//go:build !js
+
+package jsguard
+
+import (
+	"flag"
+	"strings"
+	"testing"
+
+	"github.com/hack-pad/safejs/internal/assert"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestParseConfig(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		description string
+		contents    string
+		expect      *config
+		expectErr   string
+	}{
+		{
+			description: "empty",
+			contents:    "# nothing here\n",
+			expect:      &config{unsafe: map[string]bool{}, safe: map[string]bool{}},
+		},
+		{
+			description: "block and inline lists",
+			contents: `
+# Third-party bindings
+unsafe:
+  - github.com/example/dom # all of it
+  - "github.com/example/canvas.Context.Draw"
+
+safe: ['syscall/js.Value.Truthy', syscall/js.Value.Bool]
+`,
+			expect: &config{
+				unsafe: map[string]bool{
+					"github.com/example/dom":                 true,
+					"github.com/example/canvas.Context.Draw": true,
+				},
+				safe: map[string]bool{
+					"syscall/js.Value.Truthy": true,
+					"syscall/js.Value.Bool":   true,
+				},
+			},
+		},
+		{
+			description: "comment characters in quotes",
+			contents: `
+unsafe:
+  - "github.com/example/dom#Query" # not part of the name
+  - 'github.com/example/canvas #2'
+  - 'it''s #3'
+  - don't # a comment
+safe: ["it's \"#safe\"", 'syscall/js.Value.Truthy'] # a comment
+`,
+			expect: &config{
+				unsafe: map[string]bool{
+					"github.com/example/dom#Query": true,
+					"github.com/example/canvas #2": true,
+					"it's #3":                      true,
+					"don't":                        true,
+				},
+				safe: map[string]bool{
+					`it's "#safe"`:            true,
+					"syscall/js.Value.Truthy": true,
+				},
+			},
+		},
+		{
+			description: "unknown key",
+			contents:    "trusted:\n  - foo\n",
+			expectErr:   `.jsguard.yaml:1: unknown key "trusted"`,
+		},
+		{
+			description: "item without key",
+			contents:    "  - foo\n",
+			expectErr:   ".jsguard.yaml:1: list item without a key",
+		},
+		{
+			description: "items after inline list",
+			contents:    "safe: []\n  - foo\n",
+			expectErr:   ".jsguard.yaml:2: list item without a key",
+		},
+		{
+			description: "not a list",
+			contents:    "unsafe: foo\n",
+			expectErr:   `.jsguard.yaml:1: expected a list, found "foo"`,
+		},
+		{
+			description: "bad quotes",
+			contents:    "unsafe:\n  - \"foo\n",
+			expectErr:   ".jsguard.yaml:2: invalid syntax",
+		},
+	} {
+		tc := tc
+		t.Run(tc.description, func(t *testing.T) {
+			t.Parallel()
+			cfg, err := parseConfig(".jsguard.yaml", []byte(tc.contents))
+			if tc.expectErr != "" {
+				assert.EqualError(t, err, tc.expectErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expect, cfg)
+		})
+	}
+}
+
+func TestConfigUnsafeAndSafeNames(t *testing.T) {
+	t.Parallel()
+	const (
+		configName = "src/.jsguard.yaml"
+		configFile = `
+unsafe: [dom]
+safe:
+  - syscall/js.Value.Truthy
+  - dom.Element.IsMissing
+`
+		domName = "src/dom/dom.go"
+		domFile = `
+package dom
+
+type Element struct{}
+
+func Query(selector string) Element { return Element{} }
+
+func (e Element) IsMissing() bool { return false }
+`
+		fooName = "src/foo/foo.go"
+		fooFile = `
+//go:build js && wasm
+
+package foo
+
+import (
+	"dom"
+	"syscall/js"
+)
+
+func Foo(v js.Value) {
+	el := dom.Query("button")
+	el.IsMissing()
+	v.Truthy()
+	v.Get("x")
+	query := dom.Query
+	_ = query
+}
+`
+	)
+	dir := makePackageDir(t, map[string]string{
+		configName: configFile,
+		domName:    domFile,
+		fooName:    fooFile,
+	})
+
+	result := analysistest.Run(ignoreTestingErrorf{}, dir, Analyzer, "foo")
+	if !assert.Equal(t, 1, len(result)) {
+		t.FailNow()
+	}
+	result0 := result[0]
+	assertDiagnostics(t, []analysis.Diagnostic{
+		{
+			Pos:      filePos(t, result0.Pass, "foo", strings.Index(fooFile, `dom.Query("button")`)),
+			Category: "unsafe-call",
+			Message:  "unsafe call to dom found: dom.Query(...)",
+		},
+		{
+			Pos:            filePos(t, result0.Pass, "foo", strings.Index(fooFile, `v.Get("x")`)),
+			Category:       "unsafe-method",
+			Message:        "unsafe method call on syscall/js.Value found: v.Get(...)",
+			SuggestedFixes: rewriteFix,
+		},
+		{
+			Pos:      filePos(t, result0.Pass, "foo", strings.Index(fooFile, `dom.Query`+"\n")),
+			Category: "unsafe-func-value",
+			Message:  "unsafe function value from dom found: dom.Query",
+		},
+	}, result0.Diagnostics)
+}
+
+func TestConfigFlags(t *testing.T) {
+	const (
+		domName = "src/dom/dom.go"
+		domFile = `
+package dom
+
+func Query(selector string) {}
+`
+		fooName = "src/foo/foo.go"
+		fooFile = `
+package foo
+
+import "dom"
+
+func Foo() {
+	dom.Query("button")
+}
+`
+	)
+	dir := makePackageDir(t, map[string]string{
+		domName: domFile,
+		fooName: fooFile,
+	})
+	flags := flag.NewFlagSet("jsguard", flag.ContinueOnError)
+	AddConfigFlags(flags)
+	assert.NoError(t, flags.Set("unsafe", "dom"))
+	t.Cleanup(func() {
+		assert.NoError(t, flags.Set("unsafe", ""))
+	})
+
+	result := analysistest.Run(ignoreTestingErrorf{}, dir, Analyzer, "foo")
+	if !assert.Equal(t, 1, len(result)) {
+		t.FailNow()
+	}
+	result0 := result[0]
+	assertDiagnostics(t, []analysis.Diagnostic{
+		{
+			Pos:      filePos(t, result0.Pass, "foo", strings.Index(fooFile, `dom.Query("button")`)),
+			Category: "unsafe-call",
+			Message:  "unsafe call to dom found: dom.Query(...)",
+		},
+	}, result0.Diagnostics)
+}
@@ -0,0 +1,255 @@
+//go:build !js
+
+package jsguard
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// ExportedJSAnalyzer reports exported functions, methods, struct fields, and interface methods whose types mention syscall/js types.
+// It is opt-in: it only reports anything when its -enable flag is set.
+var ExportedJSAnalyzer = &analysis.Analyzer{
+	Name: "exportedjs",
+	Doc:  "report exported APIs whose signatures mention syscall/js types instead of safejs types (opt-in with -enable)",
+	Run:  runExportedJS,
+}
+
+var (
+	exportedJSEnabled   bool
+	exportedJSAllowlist string
+)
+
+func init() {
+	ExportedJSAnalyzer.Flags.BoolVar(&exportedJSEnabled, "enable", false, "report exported APIs whose signatures mention syscall/js types")
+	ExportedJSAnalyzer.Flags.StringVar(&exportedJSAllowlist, "allow", "",
+		"comma-separated packages, types, and functions, methods, or fields which may expose syscall/js types, e.g. 'github.com/example/dom/compat'")
+}
+
+// safejsEquivalents maps syscall/js type names to their safejs replacements
+var safejsEquivalents = map[string]string{
+	"Error":      "safejs.Error",
+	"Func":       "safejs.Func",
+	"Type":       "safejs.Type",
+	"Value":      "safejs.Value",
+	"ValueError": "error",
+}
+
+func runExportedJS(pass *analysis.Pass) (interface{}, error) {
+	finishBaseline, err := applyBaseline(pass)
+	if err != nil {
+		return nil, err
+	}
+	honorIgnoreDirectives(pass)
+	if !exportedJSEnabled || !isLibraryPackage(pass.Pkg) {
+		return nil, finishBaseline()
+	}
+	checker := exportedJSChecker{
+		pass:      pass,
+		allowlist: parseNameList(exportedJSAllowlist),
+	}
+	for _, file := range pass.Files {
+		if strings.HasSuffix(pass.Fset.File(file.Pos()).Name(), "_test.go") {
+			continue
+		}
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				checker.inspectFunc(decl)
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					if typeSpec, ok := spec.(*ast.TypeSpec); ok {
+						checker.inspectType(typeSpec)
+					}
+				}
+			}
+		}
+	}
+	return nil, finishBaseline()
+}
+
+// isLibraryPackage returns true if pkg can be imported by other modules, so it isn't a main or internal package
+func isLibraryPackage(pkg *types.Package) bool {
+	if pkg.Name() == "main" {
+		return false
+	}
+	for _, element := range strings.Split(pkg.Path(), "/") {
+		if element == "internal" {
+			return false
+		}
+	}
+	return true
+}
+
+type exportedJSChecker struct {
+	pass      *analysis.Pass
+	allowlist map[string]bool
+}
+
+func (e exportedJSChecker) inspectFunc(decl *ast.FuncDecl) {
+	fn, ok := e.pass.TypesInfo.Defs[decl.Name].(*types.Func)
+	if !ok || !fn.Exported() {
+		return
+	}
+	kind := "function"
+	if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+		typeName, ok := receiverTypeName(recv.Type())
+		if !ok || !typeName.Exported() {
+			return
+		}
+		kind = "method"
+	}
+	name := strings.TrimPrefix(funcDisplayName(fn), fn.Pkg().Name()+".")
+	e.report(decl.Name.Pos(), kind, name, fn.Type())
+}
+
+func (e exportedJSChecker) inspectType(spec *ast.TypeSpec) {
+	if !spec.Name.IsExported() {
+		return
+	}
+	switch typ := spec.Type.(type) {
+	case *ast.StructType:
+		for _, field := range typ.Fields.List {
+			e.inspectField(spec.Name.Name, field)
+		}
+	case *ast.InterfaceType:
+		for _, method := range typ.Methods.List {
+			for _, name := range method.Names {
+				if name.IsExported() {
+					e.report(name.Pos(), "interface method", spec.Name.Name+"."+name.Name, e.pass.TypesInfo.TypeOf(method.Type))
+				}
+			}
+		}
+	}
+}
+
+func (e exportedJSChecker) inspectField(typeName string, field *ast.Field) {
+	typ := e.pass.TypesInfo.TypeOf(field.Type)
+	if len(field.Names) == 0 {
+		// embedded fields are named after their type
+		embedded := typ
+		if pointer, ok := embedded.(*types.Pointer); ok {
+			embedded = pointer.Elem()
+		}
+		if named, ok := embedded.(*types.Named); ok && named.Obj().Exported() {
+			e.report(field.Type.Pos(), "embedded field", typeName+"."+named.Obj().Name(), typ)
+		}
+		return
+	}
+	for _, name := range field.Names {
+		if name.IsExported() {
+			e.report(name.Pos(), "field", typeName+"."+name.Name, typ)
+		}
+	}
+}
+
+// report reports the exported API with kind and name, like "method" and "Type.Method", at pos if typ mentions any syscall/js types
+func (e exportedJSChecker) report(pos token.Pos, kind, name string, typ types.Type) {
+	if typ == nil || e.allowed(name) {
+		return
+	}
+	var jsTypes []string
+	findJSTypes(typ, make(map[types.Type]bool), &jsTypes)
+	if len(jsTypes) == 0 {
+		return
+	}
+	var exposed, replacements []string
+	for _, jsType := range jsTypes {
+		exposed = append(exposed, "js."+jsType)
+		replacements = append(replacements, safejsEquivalents[jsType])
+	}
+	reportf(e.pass, ruleExportedJSType, pos, "exported %s %s exposes %s: use %s instead",
+		kind, name, joinWords(exposed), joinWords(replacements))
+}
+
+// allowed returns true if the API name, its type, or its package is in the allowlist
+func (e exportedJSChecker) allowed(name string) bool {
+	pkgPath := e.pass.Pkg.Path()
+	if e.allowlist[pkgPath] || e.allowlist[pkgPath+"."+name] {
+		return true
+	}
+	typeName, _, isMember := strings.Cut(name, ".")
+	return isMember && e.allowlist[pkgPath+"."+typeName]
+}
+
+// receiverTypeName returns the type name of a method receiver, dereferencing pointers
+func receiverTypeName(recv types.Type) (*types.TypeName, bool) {
+	if pointer, ok := recv.(*types.Pointer); ok {
+		recv = pointer.Elem()
+	}
+	named, ok := recv.(*types.Named)
+	if !ok {
+		return nil, false
+	}
+	return named.Obj(), true
+}
+
+// findJSTypes appends the names of syscall/js types mentioned by typ to jsTypes, skipping duplicates.
+// Named types from other packages are not expanded, since they are reported where they're declared.
+func findJSTypes(typ types.Type, seen map[types.Type]bool, jsTypes *[]string) {
+	if seen[typ] {
+		return
+	}
+	seen[typ] = true
+	switch typ := typ.(type) {
+	case *types.Named:
+		obj := typ.Obj()
+		if obj.Pkg() != nil && obj.Pkg().Path() == jsPackagePath {
+			for _, name := range *jsTypes {
+				if name == obj.Name() {
+					return
+				}
+			}
+			*jsTypes = append(*jsTypes, obj.Name())
+			return
+		}
+		typeArgs := typ.TypeArgs()
+		for i := 0; i < typeArgs.Len(); i++ {
+			findJSTypes(typeArgs.At(i), seen, jsTypes)
+		}
+	case *types.Pointer:
+		findJSTypes(typ.Elem(), seen, jsTypes)
+	case *types.Slice:
+		findJSTypes(typ.Elem(), seen, jsTypes)
+	case *types.Array:
+		findJSTypes(typ.Elem(), seen, jsTypes)
+	case *types.Chan:
+		findJSTypes(typ.Elem(), seen, jsTypes)
+	case *types.Map:
+		findJSTypes(typ.Key(), seen, jsTypes)
+		findJSTypes(typ.Elem(), seen, jsTypes)
+	case *types.Signature:
+		findJSTypes(typ.Params(), seen, jsTypes)
+		findJSTypes(typ.Results(), seen, jsTypes)
+	case *types.Tuple:
+		for i := 0; i < typ.Len(); i++ {
+			findJSTypes(typ.At(i).Type(), seen, jsTypes)
+		}
+	case *types.Struct:
+		for i := 0; i < typ.NumFields(); i++ {
+			findJSTypes(typ.Field(i).Type(), seen, jsTypes)
+		}
+	case *types.Interface:
+		for i := 0; i < typ.NumExplicitMethods(); i++ {
+			findJSTypes(typ.ExplicitMethod(i).Type(), seen, jsTypes)
+		}
+	}
+}
+
+// joinWords joins words into a list like "a", "a and b", or "a, b, and c"
+func joinWords(words []string) string {
+	switch len(words) {
+	case 0:
+		return ""
+	case 1:
+		return words[0]
+	case 2:
+		return words[0] + " and " + words[1]
+	default:
+		return strings.Join(words[:len(words)-1], ", ") + ", and " + words[len(words)-1]
+	}
+}
@@ -0,0 +1,153 @@
+//go:build !js
+
+package jsguard
+
+import (
+	"go/types"
+	"strings"
+	"testing"
+
+	"github.com/hack-pad/safejs/internal/assert"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestExportedJS(t *testing.T) {
+	t.Parallel()
+	const (
+		fooName = "src/foo/foo.go"
+		fooFile = `
+//go:build js && wasm
+
+package foo
+
+import (
+	"syscall/js"
+
+	"github.com/hack-pad/safejs"
+)
+
+type Handle struct {
+	js.Value
+	Callback js.Func
+	Values   map[string][]js.Value
+	Safe     safejs.Value
+	raw      js.Value
+}
+
+type Element interface {
+	Node() js.Value
+	Text() string
+	value() js.Value
+}
+
+type Compat struct {
+	Raw js.Value
+}
+
+type wrapper struct {
+	Raw js.Value
+}
+
+func New(v js.Value) (*Handle, error) {
+	return nil, nil
+}
+
+func Wrap(v safejs.Value) safejs.Value {
+	return v
+}
+
+func Call(fn func(this js.Value, args []js.Value) any) (js.Func, js.Type) {
+	return js.Func{}, js.TypeUndefined
+}
+
+func (h *Handle) Raw() js.Value {
+	return h.raw
+}
+
+func (w wrapper) Get() js.Value {
+	return w.Raw
+}
+
+func unexported(v js.Value) {}
+
+func Shim(v js.Value) {}
+`
+	)
+	dir := makePackageDir(t, map[string]string{
+		safejsStubName: safejsStubFile,
+		fooName:        fooFile,
+	})
+
+	result := analysistest.Run(ignoreTestingErrorf{}, dir, ExportedJSAnalyzer, "foo")
+	if !assert.Equal(t, 1, len(result)) {
+		t.FailNow()
+	}
+	assertDiagnostics(t, nil, result[0].Diagnostics)
+
+	assert.NoError(t, ExportedJSAnalyzer.Flags.Set("enable", "true"))
+	assert.NoError(t, ExportedJSAnalyzer.Flags.Set("allow", "foo.Shim, foo.Compat"))
+	t.Cleanup(func() {
+		assert.NoError(t, ExportedJSAnalyzer.Flags.Set("enable", "false"))
+		assert.NoError(t, ExportedJSAnalyzer.Flags.Set("allow", ""))
+	})
+	result = analysistest.Run(ignoreTestingErrorf{}, dir, ExportedJSAnalyzer, "foo")
+	if !assert.Equal(t, 1, len(result)) {
+		t.FailNow()
+	}
+	result0 := result[0]
+	pass := result0.Pass
+	assertDiagnostics(t, []analysis.Diagnostic{
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, "js.Value\n")),
+			Category: "exported-js-type",
+			Message:  "exported embedded field Handle.Value exposes js.Value: use safejs.Value instead",
+		},
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, "Callback")),
+			Category: "exported-js-type",
+			Message:  "exported field Handle.Callback exposes js.Func: use safejs.Func instead",
+		},
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, "Values")),
+			Category: "exported-js-type",
+			Message:  "exported field Handle.Values exposes js.Value: use safejs.Value instead",
+		},
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, "Node()")),
+			Category: "exported-js-type",
+			Message:  "exported interface method Element.Node exposes js.Value: use safejs.Value instead",
+		},
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, "New(")),
+			Category: "exported-js-type",
+			Message:  "exported function New exposes js.Value: use safejs.Value instead",
+		},
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, "Call(")),
+			Category: "exported-js-type",
+			Message:  "exported function Call exposes js.Value, js.Func, and js.Type: use safejs.Value, safejs.Func, and safejs.Type instead",
+		},
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, "Raw() js.Value")),
+			Category: "exported-js-type",
+			Message:  "exported method Handle.Raw exposes js.Value: use safejs.Value instead",
+		},
+	}, result0.Diagnostics)
+}
+
+func TestIsLibraryPackage(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		path, name string
+		expect     bool
+	}{
+		{path: "github.com/example/dom", name: "dom", expect: true},
+		{path: "github.com/example/cmd/app", name: "main", expect: false},
+		{path: "github.com/example/internal", name: "internal", expect: false},
+		{path: "github.com/example/internal/dom", name: "dom", expect: false},
+		{path: "github.com/example/internalize", name: "internalize", expect: true},
+	} {
+		assert.Equal(t, tc.expect, isLibraryPackage(types.NewPackage(tc.path, tc.name)))
+	}
+}
@@ -0,0 +1,986 @@
+//go:build !js
+
+package jsguard
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+const (
+	jsPackagePath     = "syscall/js"
+	safejsPackagePath = "github.com/hack-pad/safejs"
+	fixMessage        = "Rewrite syscall/js calls to safejs"
+)
+
+// linkResult is the kind of result returned by a safejs method, in addition to an error
+type linkResult int
+
+const (
+	resultNone  linkResult = iota // only returns an error, like Set
+	resultValue                   // returns a safejs.Value, like Get
+	resultOther                   // returns the same type as syscall/js, like Int
+)
+
+// fixableMethods maps syscall/js.Value methods to their safejs.Value counterpart's result
+var fixableMethods = map[string]linkResult{
+	"Bool":       resultOther,
+	"Call":       resultValue,
+	"Delete":     resultNone,
+	"Float":      resultOther,
+	"Get":        resultValue,
+	"Index":      resultValue,
+	"InstanceOf": resultOther,
+	"Int":        resultOther,
+	"Invoke":     resultValue,
+	"Length":     resultOther,
+	"New":        resultValue,
+	"Set":        resultNone,
+	"SetIndex":   resultNone,
+	"String":     resultOther,
+	"Truthy":     resultOther,
+}
+
+// chainLink is one call in a chain of syscall/js calls, like Get("document") in js.Global().Get("document")
+type chainLink struct {
+	method string
+	args   []ast.Expr
+	result linkResult
+}
+
+// callChain is an expression built from syscall/js calls, which safejs can return errors for at each link.
+// For example, js.Global().Get("document").Call("createElement", "div").
+type callChain struct {
+	expr     ast.Expr    // the whole chain expression
+	root     ast.Expr    // the js.Value receiver at the base of the chain, nil if rootFunc is set
+	rootFunc string      // a safe package function the chain starts with, like Global
+	links    []chainLink // calls which return errors in safejs, in call order
+	final    *chainLink  // a safe method ending the chain, like IsUndefined. nil if not present
+}
+
+// fixStmt is a statement which can be rewritten into safejs calls with errors returned to the caller
+type fixStmt struct {
+	stmt        ast.Stmt
+	chain       *callChain
+	call        *ast.CallExpr // the chain's outermost unsafe call, whose diagnostic gets the fix
+	lhs         ast.Expr      // the assigned or defined expression, nil for expression statements
+	define      bool          // true for "lhs := chain"
+	returnIndex int           // the index of chain in a return statement's results, -1 otherwise
+	zeros       []string      // zero values for the function's results before the error
+	resultTypes []types.Type
+	panics      bool // true if errors panic like syscall/js, since the function has no error result
+	fix         *analysis.SuggestedFix
+}
+
+// fileFix suggests fixes for a file which rewrite syscall/js call chains to safejs, one fix per statement.
+// Errors are plumbed through functions which already return an error. Functions without an error result panic with the error instead,
+// just like syscall/js did, so the error is visible for plumbing later.
+// safejs.Safe or safejs.Unsafe conversions are inserted where values cross into code still using syscall/js types.
+type fileFix struct {
+	pass       *analysis.Pass
+	file       *ast.File
+	tokenFile  *token.File
+	safejsName string
+	jsImport   *ast.ImportSpec
+
+	stmts       []*fixStmt
+	converted   map[*types.Var]bool // local variables which become safejs.Values
+	safeUses    map[*ast.Ident]bool // uses of converted variables which accept a safejs.Value
+	usedNames   map[*types.Scope]map[string]bool
+	generatedJS bool // true if generated code references the syscall/js package
+}
+
+// newFileFix prepares a suggested fix for file. Returns nil if file has nothing to fix.
+func newFileFix(pass *analysis.Pass, file *ast.File) *fileFix {
+	var jsImport *ast.ImportSpec
+	safejsName := ""
+	for _, imprt := range file.Imports {
+		path, err := strconv.Unquote(imprt.Path.Value)
+		if err != nil {
+			continue
+		}
+		switch path {
+		case jsPackagePath:
+			jsImport = imprt
+		case safejsPackagePath:
+			safejsName = "safejs"
+			if imprt.Name != nil {
+				safejsName = imprt.Name.Name
+			}
+		}
+	}
+	if jsImport == nil {
+		return nil
+	}
+	f := &fileFix{
+		pass:       pass,
+		file:       file,
+		tokenFile:  pass.Fset.File(file.Pos()),
+		jsImport:   jsImport,
+		safejsName: safejsName,
+		converted:  make(map[*types.Var]bool),
+		safeUses:   make(map[*ast.Ident]bool),
+		usedNames:  make(map[*types.Scope]map[string]bool),
+	}
+	if f.safejsName == "" {
+		f.safejsName = "safejs"
+	}
+	f.collectStmts()
+	if len(f.stmts) == 0 {
+		return nil
+	}
+	f.collectConverted()
+	f.buildFixes()
+	return f
+}
+
+// SuggestedFixes returns the fix for the statement rewritten from call, if call is a statement's outermost unsafe call.
+// Only one diagnostic per statement gets its fix, so applying all fixes rewrites each statement once.
+func (f *fileFix) SuggestedFixes(call *ast.CallExpr) []analysis.SuggestedFix {
+	if f == nil {
+		return nil
+	}
+	for _, stmt := range f.stmts {
+		if stmt.call == call {
+			return []analysis.SuggestedFix{*stmt.fix}
+		}
+	}
+	return nil
+}
+
+// collectStmts finds all fixable statements in function declarations and literals
+func (f *fileFix) collectStmts() {
+	for _, decl := range f.file.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok || funcDecl.Body == nil {
+			continue
+		}
+		fn, ok := f.pass.TypesInfo.Defs[funcDecl.Name].(*types.Func)
+		if !ok {
+			continue
+		}
+		f.collectFuncStmts(fn.Type().(*types.Signature), funcDecl.Body)
+	}
+}
+
+func (f *fileFix) collectFuncStmts(signature *types.Signature, body *ast.BlockStmt) {
+	zeros, ok := f.errorReturnZeros(signature)
+	panics := !returnsError(signature)
+	canFix := ok || panics // false if the function returns an error, but not all zero values can be written here
+	var resultTypes []types.Type
+	for i := 0; i < signature.Results().Len(); i++ {
+		resultTypes = append(resultTypes, signature.Results().At(i).Type())
+	}
+	ast.Inspect(body, func(node ast.Node) bool {
+		var stmts []ast.Stmt
+		switch node := node.(type) {
+		case *ast.FuncLit:
+			if litSignature, isSignature := f.pass.TypesInfo.TypeOf(node).(*types.Signature); isSignature {
+				f.collectFuncStmts(litSignature, node.Body)
+			}
+			return false
+		case *ast.BlockStmt:
+			stmts = node.List
+		case *ast.CaseClause:
+			stmts = node.Body
+		case *ast.CommClause:
+			stmts = node.Body
+		}
+		for _, stmt := range stmts {
+			if !canFix {
+				break
+			}
+			if fixable := f.fixableStmt(stmt, panics); fixable != nil {
+				fixable.zeros = zeros
+				fixable.resultTypes = resultTypes
+				fixable.panics = panics
+				fixable.call = fixable.chain.outerCall()
+				f.stmts = append(f.stmts, fixable)
+			}
+		}
+		return true
+	})
+}
+
+// fixableStmt returns a fixStmt if stmt is a supported statement containing a call chain.
+// panics is true if errors will panic instead of returning.
+func (f *fileFix) fixableStmt(stmt ast.Stmt, panics bool) *fixStmt {
+	switch stmt := stmt.(type) {
+	case *ast.AssignStmt:
+		if len(stmt.Lhs) != 1 || len(stmt.Rhs) != 1 || (stmt.Tok != token.DEFINE && stmt.Tok != token.ASSIGN) {
+			return nil
+		}
+		if ident, isIdent := stmt.Lhs[0].(*ast.Ident); isIdent && ident.Name == "_" {
+			return nil
+		}
+		chain := f.parseChain(stmt.Rhs[0])
+		if chain == nil || chain.result() == resultNone || f.containsUnsafeJS(stmt.Lhs[0]) {
+			return nil
+		}
+		return &fixStmt{stmt: stmt, chain: chain, lhs: stmt.Lhs[0], define: stmt.Tok == token.DEFINE, returnIndex: -1}
+	case *ast.ExprStmt:
+		chain := f.parseChain(stmt.X)
+		if chain == nil || chain.final != nil {
+			return nil
+		}
+		return &fixStmt{stmt: stmt, chain: chain, returnIndex: -1}
+	case *ast.ReturnStmt:
+		var fixable *fixStmt
+		for i, result := range stmt.Results {
+			if !f.containsUnsafeJS(result) {
+				continue
+			}
+			chain := f.parseChain(result)
+			if fixable != nil || chain == nil || chain.result() == resultNone {
+				return nil
+			}
+			fixable = &fixStmt{stmt: stmt, chain: chain, returnIndex: i}
+		}
+		if fixable != nil && !panics && len(stmt.Results) < 2 {
+			return nil // the last result is an error, so a chain result must have company
+		}
+		return fixable
+	default:
+		return nil
+	}
+}
+
+// result returns the chain's final result kind
+func (c *callChain) result() linkResult {
+	if c.final != nil {
+		return resultOther
+	}
+	return c.links[len(c.links)-1].result
+}
+
+// outerCall returns the outermost unsafe call in the chain, which is wrapped by the final safe method if present
+func (c *callChain) outerCall() *ast.CallExpr {
+	call := unparen(c.expr).(*ast.CallExpr)
+	if c.final != nil {
+		call = unparen(unparen(call.Fun).(*ast.SelectorExpr).X).(*ast.CallExpr)
+	}
+	return call
+}
+
+// parseChain returns the call chain for expr, or nil if expr can't be fully rewritten to safejs
+func (f *fileFix) parseChain(expr ast.Expr) *callChain {
+	chain := f.parseChainLinks(expr)
+	if chain == nil || len(chain.links) == 0 {
+		return nil
+	}
+	chain.expr = expr
+	return chain
+}
+
+func (f *fileFix) parseChainLinks(expr ast.Expr) *callChain {
+	call, ok := unparen(expr).(*ast.CallExpr)
+	if !ok || call.Ellipsis.IsValid() {
+		return nil
+	}
+	selector, ok := unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return nil
+	}
+	fn := selectedFunc(f.pass, selector)
+	if fn == nil || fn.Pkg() == nil || fn.Pkg().Path() != jsPackagePath {
+		return nil
+	}
+	for _, arg := range call.Args {
+		if f.containsUnsafeJS(arg) {
+			return nil
+		}
+	}
+
+	signature := fn.Type().(*types.Signature)
+	if signature.Recv() == nil {
+		switch fn.Name() {
+		case "Global", "Null", "Undefined":
+			return &callChain{rootFunc: fn.Name()}
+		case "ValueOf":
+			return &callChain{rootFunc: "", links: []chainLink{{method: "ValueOf", args: call.Args, result: resultValue}}}
+		default:
+			return nil
+		}
+	}
+
+	selection := f.pass.TypesInfo.Selections[selector]
+	if selection == nil || selection.Kind() != types.MethodVal || len(selection.Index()) != 1 || !isJSType(selection.Recv(), "Value") {
+		return nil
+	}
+	var chain *callChain
+	if f.containsUnsafeJS(selector.X) {
+		chain = f.parseChainLinks(selector.X)
+		if chain == nil || chain.final != nil || (len(chain.links) > 0 && chain.links[len(chain.links)-1].result != resultValue) {
+			return nil
+		}
+	} else if _, isCall := unparen(selector.X).(*ast.CallExpr); isCall {
+		chain = f.parseChainLinks(selector.X) // safe roots like js.Global()
+		if chain == nil {
+			chain = &callChain{root: selector.X}
+		}
+	} else {
+		chain = &callChain{root: selector.X}
+	}
+
+	link := chainLink{method: fn.Name(), args: call.Args}
+	switch fn.Name() {
+	case "IsNaN", "IsNull", "IsUndefined", "Equal":
+		if len(chain.links) == 0 {
+			return nil // nothing unsafe to fix
+		}
+		chain.final = &link
+		return chain
+	}
+	result, isFixable := fixableMethods[fn.Name()]
+	if !isFixable {
+		return nil
+	}
+	link.result = result
+	chain.links = append(chain.links, link)
+	return chain
+}
+
+// containsUnsafeJS returns true if node references any unsafe syscall/js functions or methods
+func (f *fileFix) containsUnsafeJS(node ast.Node) bool {
+	found := false
+	ast.Inspect(node, func(node ast.Node) bool {
+		selector, ok := node.(*ast.SelectorExpr)
+		if !ok || found {
+			return !found
+		}
+		fn := selectedFunc(f.pass, selector)
+		if fn == nil {
+			return true
+		}
+		typeName, ok := funcTypeName(fn)
+		if ok && !isSafeCall(typeName, fn.Name()) {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// isJSType returns true if typ is the syscall/js type with the given name
+func isJSType(typ types.Type, name string) bool {
+	named, ok := typ.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == jsPackagePath && obj.Name() == name
+}
+
+// collectConverted marks variables defined by fixable statements which can safely change type to safejs.Value
+func (f *fileFix) collectConverted() {
+	uses := make(map[*types.Var][]*ast.Ident)
+	for ident, obj := range f.pass.TypesInfo.Uses {
+		if variable, ok := obj.(*types.Var); ok {
+			uses[variable] = append(uses[variable], ident)
+		}
+	}
+	unconvertible := make(map[*ast.Ident]bool)
+	ast.Inspect(f.file, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.AssignStmt:
+			for _, lhs := range node.Lhs {
+				if ident, ok := unparen(lhs).(*ast.Ident); ok {
+					unconvertible[ident] = true
+				}
+			}
+		case *ast.UnaryExpr:
+			if ident, ok := unparen(node.X).(*ast.Ident); ok && node.Op == token.AND {
+				unconvertible[ident] = true
+			}
+		}
+		return true
+	})
+
+	for _, stmt := range f.stmts {
+		if !stmt.define || stmt.chain.final != nil || stmt.chain.result() != resultValue {
+			continue
+		}
+		ident := stmt.lhs.(*ast.Ident)
+		variable, ok := f.pass.TypesInfo.Defs[ident].(*types.Var)
+		if !ok {
+			continue
+		}
+		convertible := true
+		for _, use := range uses[variable] {
+			if unconvertible[use] {
+				convertible = false
+				break
+			}
+		}
+		if convertible {
+			f.converted[variable] = true
+		}
+	}
+
+	for _, stmt := range f.stmts {
+		chain := stmt.chain
+		if chain.root != nil {
+			f.markSafeUse(chain.root)
+		}
+		for _, link := range chain.links {
+			f.markSafeArgs(link)
+		}
+		if chain.final != nil {
+			f.markSafeArgs(*chain.final)
+		}
+	}
+}
+
+func (f *fileFix) markSafeArgs(link chainLink) {
+	switch link.method {
+	case "Call", "Invoke", "New", "Set", "SetIndex", "InstanceOf", "Equal":
+		for _, arg := range link.args {
+			f.markSafeUse(arg)
+		}
+	}
+}
+
+func (f *fileFix) markSafeUse(expr ast.Expr) {
+	if ident, ok := unparen(expr).(*ast.Ident); ok && f.isConverted(ident) {
+		f.safeUses[ident] = true
+	}
+}
+
+func (f *fileFix) isConverted(ident *ast.Ident) bool {
+	variable, ok := f.pass.TypesInfo.Uses[ident].(*types.Var)
+	return ok && f.converted[variable]
+}
+
+// buildFixes generates each statement's fix.
+// Import edits are needed by every statement, so each fix repeats them. They only replace existing text,
+// which lets 'jsguard -fix' apply the identical edits once.
+func (f *fileFix) buildFixes() {
+	// Convert uses of converted variables outside of rewritten statements back into js.Values
+	conversions := make(map[*types.Var][]analysis.TextEdit)
+	for ident, obj := range f.pass.TypesInfo.Uses {
+		if f.isConverted(ident) && !f.inFixedStmt(ident.Pos()) && f.tokenFile == f.pass.Fset.File(ident.Pos()) {
+			variable := obj.(*types.Var)
+			conversions[variable] = append(conversions[variable], analysis.TextEdit{
+				Pos:     ident.Pos(),
+				End:     ident.End(),
+				NewText: []byte(f.unsafeConversion(ident.Name)),
+			})
+		}
+	}
+
+	importEdits := f.importEdits()
+	for _, stmt := range f.stmts {
+		edits := []analysis.TextEdit{{
+			Pos:     stmt.stmt.Pos(),
+			End:     stmt.stmt.End(),
+			NewText: []byte(f.renderStmt(stmt)),
+		}}
+		if stmt.define {
+			if variable, ok := f.pass.TypesInfo.Defs[stmt.lhs.(*ast.Ident)].(*types.Var); ok {
+				edits = append(edits, conversions[variable]...)
+			}
+		}
+		edits = append(edits, importEdits...)
+		sort.Slice(edits, func(a, b int) bool {
+			return edits[a].Pos < edits[b].Pos
+		})
+		stmt.fix = &analysis.SuggestedFix{
+			Message:   fixMessage,
+			TextEdits: edits,
+		}
+	}
+}
+
+func (f *fileFix) inFixedStmt(pos token.Pos) bool {
+	for _, stmt := range f.stmts {
+		if stmt.stmt.Pos() <= pos && pos < stmt.stmt.End() {
+			return true
+		}
+	}
+	return false
+}
+
+// importEdits adds a safejs import to the third-party imports, and removes the syscall/js import if it's no longer used
+func (f *fileFix) importEdits() []analysis.TextEdit {
+	jsStillUsed := f.generatedJS
+	for ident, obj := range f.pass.TypesInfo.Uses {
+		pkgName, ok := obj.(*types.PkgName)
+		if ok && pkgName.Imported().Path() == jsPackagePath && f.tokenFile == f.pass.Fset.File(ident.Pos()) && !f.inFixedStmt(ident.Pos()) {
+			jsStillUsed = true
+			break
+		}
+	}
+	safejsImported := false
+	for _, imprt := range f.file.Imports {
+		if importPath(imprt) == safejsPackagePath {
+			safejsImported = true
+		}
+	}
+	safejsPath := strconv.Quote(safejsPackagePath)
+
+	genDecl := f.importDecl()
+	if genDecl == nil || !genDecl.Lparen.IsValid() {
+		jsImportEnd := importSpecEnd(f.jsImport)
+		switch {
+		case safejsImported && jsStillUsed:
+			return nil
+		case safejsImported:
+			return []analysis.TextEdit{{Pos: f.lineStart(f.line(f.jsImport.Pos())), End: f.lineStart(f.line(jsImportEnd) + 1)}}
+		case jsStillUsed:
+			// group the imports, with safejs after the standard library like goimports
+			return []analysis.TextEdit{{Pos: f.jsImport.Pos(), End: jsImportEnd, NewText: []byte("(\n\t" + importSpecText(f.jsImport) + "\n\n\t" + safejsPath + "\n)")}}
+		default:
+			return []analysis.TextEdit{{Pos: f.jsImport.Pos(), End: f.jsImport.End(), NewText: []byte(safejsPath)}}
+		}
+	}
+
+	runs := f.importRuns(genDecl)
+	var jsRun, thirdParty []*ast.ImportSpec
+	for _, run := range runs {
+		if containsSpec(run, f.jsImport) {
+			jsRun = run
+		}
+		if thirdParty == nil && !containsStandardImport(run) {
+			thirdParty = run
+		}
+	}
+	var edits []analysis.TextEdit
+	if !jsStillUsed {
+		isLastRun := containsSpec(runs[len(runs)-1], f.jsImport)
+		if !safejsImported && thirdParty == nil && len(jsRun) == 1 && isLastRun {
+			// syscall/js is alone in the last group, so safejs takes its place
+			return []analysis.TextEdit{{Pos: f.jsImport.Pos(), End: f.jsImport.End(), NewText: []byte(safejsPath)}}
+		}
+		edits = append(edits, f.removeJSImport(runs, jsRun))
+	}
+	if safejsImported {
+		return edits
+	}
+
+	indent := lineIndent(f.pass.Fset, f.jsImport.Pos())
+	if thirdParty == nil {
+		// start a new group after the standard library
+		return append(edits, analysis.TextEdit{Pos: genDecl.Rparen, End: genDecl.Rparen + 1, NewText: []byte("\n" + indent + safejsPath + "\n)")})
+	}
+	for _, imprt := range thirdParty {
+		if importPath(imprt) > safejsPackagePath {
+			return append(edits, analysis.TextEdit{Pos: imprt.Pos(), End: importSpecEnd(imprt), NewText: []byte(safejsPath + "\n" + indent + importSpecText(imprt))})
+		}
+	}
+	last := thirdParty[len(thirdParty)-1]
+	return append(edits, analysis.TextEdit{Pos: last.Pos(), End: importSpecEnd(last), NewText: []byte(importSpecText(last) + "\n" + indent + safejsPath)})
+}
+
+// removeJSImport deletes the lines of the syscall/js import, which is in jsRun.
+// If it's alone in its group, the blank line separating it from the other runs goes too.
+func (f *fileFix) removeJSImport(runs [][]*ast.ImportSpec, jsRun []*ast.ImportSpec) analysis.TextEdit {
+	startLine, endLine := f.line(importSpecStart(f.jsImport)), f.line(importSpecEnd(f.jsImport))
+	if len(jsRun) == 1 && len(runs) > 1 {
+		if containsSpec(runs[len(runs)-1], f.jsImport) {
+			startLine--
+		} else {
+			endLine++
+		}
+	}
+	return analysis.TextEdit{Pos: f.lineStart(startLine), End: f.lineStart(endLine + 1)}
+}
+
+// importDecl returns the import declaration containing the syscall/js import
+func (f *fileFix) importDecl() *ast.GenDecl {
+	for _, decl := range f.file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.IMPORT {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			if spec == f.jsImport {
+				return genDecl
+			}
+		}
+	}
+	return nil
+}
+
+// importRuns splits genDecl's imports into runs on consecutive lines, which gofmt sorts separately
+func (f *fileFix) importRuns(genDecl *ast.GenDecl) [][]*ast.ImportSpec {
+	var runs [][]*ast.ImportSpec
+	for _, spec := range genDecl.Specs {
+		imprt := spec.(*ast.ImportSpec)
+		if len(runs) > 0 {
+			run := runs[len(runs)-1]
+			if f.line(importSpecStart(imprt)) <= f.line(importSpecEnd(run[len(run)-1]))+1 {
+				runs[len(runs)-1] = append(run, imprt)
+				continue
+			}
+		}
+		runs = append(runs, []*ast.ImportSpec{imprt})
+	}
+	return runs
+}
+
+func containsSpec(specs []*ast.ImportSpec, spec *ast.ImportSpec) bool {
+	for _, s := range specs {
+		if s == spec {
+			return true
+		}
+	}
+	return false
+}
+
+// containsStandardImport returns true if any of specs imports a standard library package, i.e. one without a domain name like goimports assumes
+func containsStandardImport(specs []*ast.ImportSpec) bool {
+	for _, imprt := range specs {
+		firstElem := strings.SplitN(importPath(imprt), "/", 2)[0]
+		if !strings.Contains(firstElem, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// importPath returns the unquoted path of imprt
+func importPath(imprt *ast.ImportSpec) string {
+	path, err := strconv.Unquote(imprt.Path.Value)
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+// importSpecStart returns the start of imprt, including its doc comment
+func importSpecStart(imprt *ast.ImportSpec) token.Pos {
+	if imprt.Doc != nil {
+		return imprt.Doc.Pos()
+	}
+	return imprt.Pos()
+}
+
+// importSpecEnd returns the end of imprt, including its line comment
+func importSpecEnd(imprt *ast.ImportSpec) token.Pos {
+	if imprt.Comment != nil {
+		return imprt.Comment.End()
+	}
+	return imprt.End()
+}
+
+// importSpecText returns the source from imprt's start to importSpecEnd(imprt)
+func importSpecText(imprt *ast.ImportSpec) string {
+	text := imprt.Path.Value
+	if imprt.Name != nil {
+		text = imprt.Name.Name + " " + text
+	}
+	if imprt.Comment != nil {
+		for _, comment := range imprt.Comment.List {
+			text += " " + comment.Text
+		}
+	}
+	return text
+}
+
+// line returns the line number of pos
+func (f *fileFix) line(pos token.Pos) int {
+	return f.tokenFile.Line(pos)
+}
+
+// lineStart returns the start of the given line, or the end of the file if there is no such line
+func (f *fileFix) lineStart(line int) token.Pos {
+	if line > f.tokenFile.LineCount() {
+		return f.tokenFile.Pos(f.tokenFile.Size())
+	}
+	return f.tokenFile.LineStart(line)
+}
+
+// lineIndent returns the indentation before pos, which starts its line and is indented with tabs like gofmt
+func lineIndent(fset *token.FileSet, pos token.Pos) string {
+	return strings.Repeat("\t", fset.PositionFor(pos, false).Column-1)
+}
+
+func (f *fileFix) unsafeConversion(expr string) string {
+	return fmt.Sprintf("%s.Unsafe(%s)", f.safejsName, expr)
+}
+
+func (f *fileFix) safeConversion(expr string) string {
+	return fmt.Sprintf("%s.Safe(%s)", f.safejsName, expr)
+}
+
+// render returns the source for expr, with any converted variables wrapped in safejs.Unsafe() unless they accept safejs.Values.
+// expr is printed from its syntax tree, then parsed again to find the variables in the printed source.
+func (f *fileFix) render(expr ast.Expr) string {
+	source := formatNode(f.pass.Fset, expr)
+	var unsafeIdents []bool
+	hasUnsafe := false
+	ast.Inspect(expr, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Ident); ok {
+			isUnsafe := f.isConverted(ident) && !f.safeUses[ident]
+			unsafeIdents = append(unsafeIdents, isUnsafe)
+			hasUnsafe = hasUnsafe || isUnsafe
+		}
+		return true
+	})
+	if !hasUnsafe {
+		return source
+	}
+
+	fset := token.NewFileSet()
+	printed, err := parser.ParseExprFrom(fset, "", source, 0)
+	if err != nil {
+		return source
+	}
+	var printedIdents []*ast.Ident
+	ast.Inspect(printed, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Ident); ok {
+			printedIdents = append(printedIdents, ident)
+		}
+		return true
+	})
+	if len(printedIdents) != len(unsafeIdents) {
+		return source
+	}
+	var sb strings.Builder
+	start := 0
+	for i, ident := range printedIdents {
+		if !unsafeIdents[i] {
+			continue
+		}
+		offset := fset.Position(ident.Pos()).Offset
+		sb.WriteString(source[start:offset])
+		sb.WriteString(f.unsafeConversion(ident.Name))
+		start = offset + len(ident.Name)
+	}
+	sb.WriteString(source[start:])
+	return sb.String()
+}
+
+func (f *fileFix) renderArgs(args []ast.Expr) string {
+	rendered := make([]string, len(args))
+	for i, arg := range args {
+		rendered[i] = f.render(arg)
+	}
+	return strings.Join(rendered, ", ")
+}
+
+// renderValueArg renders an argument which must be a safejs.Value
+func (f *fileFix) renderValueArg(arg ast.Expr) string {
+	if ident, ok := unparen(arg).(*ast.Ident); ok && f.isConverted(ident) {
+		return ident.Name
+	}
+	return f.safeConversion(f.render(arg))
+}
+
+// renderLink renders a call of link on the receiver expression recv
+func (f *fileFix) renderLink(recv string, link chainLink) string {
+	var args string
+	switch link.method {
+	case "ValueOf":
+		return fmt.Sprintf("%s.ValueOf(%s)", f.safejsName, f.renderArgs(link.args))
+	case "InstanceOf", "Equal":
+		args = f.renderValueArg(link.args[0])
+	default:
+		args = f.renderArgs(link.args)
+	}
+	return fmt.Sprintf("%s.%s(%s)", recv, link.method, args)
+}
+
+// renderRoot renders the safejs.Value expression at the base of chain
+func (f *fileFix) renderRoot(chain *callChain) string {
+	switch {
+	case chain.rootFunc != "":
+		return fmt.Sprintf("%s.%s()", f.safejsName, chain.rootFunc)
+	case chain.root != nil:
+		return f.renderValueArg(chain.root)
+	default:
+		return ""
+	}
+}
+
+// renderStmt renders the rewritten statement, one line per step with error checks in between
+func (f *fileFix) renderStmt(stmt *fixStmt) string {
+	indent := lineIndent(f.pass.Fset, stmt.stmt.Pos())
+	var lines []string
+	returnErr := "\treturn " + strings.Join(append(append([]string(nil), stmt.zeros...), "err"), ", ")
+	if stmt.panics {
+		returnErr = "\tpanic(err)"
+	}
+	errCheck := func(declare string) {
+		lines = append(lines, declare+" {", returnErr, "}")
+	}
+
+	chain := stmt.chain
+	current := f.renderRoot(chain)
+	convertedLHS := ""
+	if stmt.define && chain.final == nil {
+		if variable, ok := f.pass.TypesInfo.Defs[stmt.lhs.(*ast.Ident)].(*types.Var); ok && f.converted[variable] {
+			convertedLHS = stmt.lhs.(*ast.Ident).Name
+		}
+	}
+
+	for i, link := range chain.links {
+		call := f.renderLink(current, link)
+		isLast := i == len(chain.links)-1 && chain.final == nil
+		switch {
+		case !isLast, stmt.returnIndex >= 0, stmt.lhs != nil && !stmt.define:
+			// store the result for the next link or statement
+			name := f.freshName(stmt.stmt, linkName(link))
+			lines = append(lines, fmt.Sprintf("%s, err := %s", name, call))
+			errCheck("if err != nil")
+			current = name
+		case stmt.define && (convertedLHS != "" || link.result != resultValue):
+			lines = append(lines, fmt.Sprintf("%s, err := %s", f.render(stmt.lhs), call))
+			errCheck("if err != nil")
+		case stmt.define:
+			name := f.freshName(stmt.stmt, linkName(link))
+			lines = append(lines, fmt.Sprintf("%s, err := %s", name, call))
+			errCheck("if err != nil")
+			lines = append(lines, fmt.Sprintf("%s := %s", f.render(stmt.lhs), f.unsafeConversion(name)))
+		case link.result == resultNone:
+			errCheck(fmt.Sprintf("if err := %s; err != nil", call))
+		default:
+			errCheck(fmt.Sprintf("if _, err := %s; err != nil", call))
+		}
+	}
+
+	result := current
+	isValue := chain.result() == resultValue
+	if chain.final != nil {
+		result = f.renderLink(current, *chain.final)
+		isValue = false
+	}
+	switch {
+	case stmt.define && chain.final != nil:
+		lines = append(lines, fmt.Sprintf("%s := %s", f.render(stmt.lhs), result))
+	case stmt.lhs != nil && !stmt.define:
+		if isValue {
+			result = f.unsafeConversion(result)
+		}
+		lines = append(lines, fmt.Sprintf("%s = %s", f.render(stmt.lhs), result))
+	case stmt.returnIndex >= 0:
+		returnStmt := stmt.stmt.(*ast.ReturnStmt)
+		results := make([]string, len(returnStmt.Results))
+		for i, expr := range returnStmt.Results {
+			results[i] = f.render(expr)
+		}
+		if isValue && !isSafejsType(stmt.resultTypes[stmt.returnIndex]) {
+			result = f.unsafeConversion(result)
+		}
+		results[stmt.returnIndex] = result
+		lines = append(lines, "return "+strings.Join(results, ", "))
+	}
+	return strings.Join(lines, "\n"+indent)
+}
+
+// linkName returns a variable name base for link's result
+func linkName(link chainLink) string {
+	if link.result != resultValue {
+		return "result"
+	}
+	if link.method == "Get" && len(link.args) == 1 {
+		if lit, ok := link.args[0].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+			name, err := strconv.Unquote(lit.Value)
+			if err == nil && token.IsIdentifier(name) && !token.IsKeyword(name) {
+				return name
+			}
+		}
+	}
+	return "value"
+}
+
+// freshName returns an identifier based on name which doesn't conflict with anything in scope at stmt
+func (f *fileFix) freshName(stmt ast.Stmt, name string) string {
+	scope := f.pass.Pkg.Scope().Innermost(stmt.Pos())
+	if scope == nil {
+		scope = f.pass.Pkg.Scope()
+	}
+	if f.usedNames[scope] == nil {
+		f.usedNames[scope] = make(map[string]bool)
+	}
+	candidate := name
+	for i := 2; ; i++ {
+		_, obj := scope.LookupParent(candidate, token.NoPos)
+		if obj == nil && scope.Lookup(candidate) == nil && !f.usedNames[scope][candidate] && candidate != "err" {
+			break
+		}
+		candidate = fmt.Sprintf("%s%d", name, i)
+	}
+	f.usedNames[scope][candidate] = true
+	return candidate
+}
+
+// returnsError returns true if signature's last result is an error
+func returnsError(signature *types.Signature) bool {
+	results := signature.Results()
+	return results.Len() > 0 && types.Identical(results.At(results.Len()-1).Type(), types.Universe.Lookup("error").Type())
+}
+
+// errorReturnZeros returns the zero values for all results before the final error, if signature ends with an error
+func (f *fileFix) errorReturnZeros(signature *types.Signature) ([]string, bool) {
+	if !returnsError(signature) {
+		return nil, false
+	}
+	results := signature.Results()
+	var zeros []string
+	for i := 0; i < results.Len()-1; i++ {
+		zero, ok := f.zeroValue(results.At(i).Type())
+		if !ok {
+			return nil, false
+		}
+		zeros = append(zeros, zero)
+	}
+	return zeros, true
+}
+
+// zeroValue returns source for the zero value of typ
+func (f *fileFix) zeroValue(typ types.Type) (string, bool) {
+	if typeParam, ok := typ.(*types.TypeParam); ok {
+		return fmt.Sprintf("*new(%s)", typeParam.Obj().Name()), true
+	}
+	switch underlying := typ.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case underlying.Info()&types.IsBoolean != 0:
+			return "false", true
+		case underlying.Info()&types.IsString != 0:
+			return `""`, true
+		case underlying.Info()&types.IsNumeric != 0:
+			return "0", true
+		default:
+			return "nil", true
+		}
+	case *types.Pointer, *types.Slice, *types.Map, *types.Chan, *types.Signature, *types.Interface:
+		return "nil", true
+	}
+	qualifiedOK := true
+	typeStr := types.TypeString(typ, func(pkg *types.Package) string {
+		if pkg == f.pass.Pkg {
+			return ""
+		}
+		for _, imprt := range f.file.Imports {
+			if imprt.Path.Value != strconv.Quote(pkg.Path()) {
+				continue
+			}
+			if pkg.Path() == jsPackagePath {
+				f.generatedJS = true
+			}
+			if imprt.Name != nil {
+				return imprt.Name.Name
+			}
+			return pkg.Name()
+		}
+		qualifiedOK = false
+		return pkg.Name()
+	})
+	return typeStr + "{}", qualifiedOK
+}
+
+// isSafejsType returns true if typ is safejs.Value
+func isSafejsType(typ types.Type) bool {
+	named, ok := typ.(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == safejsPackagePath && named.Obj().Name() == "Value"
+}
@@ -0,0 +1,483 @@
+//go:build !js
+
+package jsguard
+
+import (
+	"bytes"
+	"go/format"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hack-pad/safejs/internal/assert"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+// applyFixes applies all suggested fixes like 'jsguard -fix', skipping identical edits
+func applyFixes(t *testing.T, pass *analysis.Pass, src string, diagnostics []analysis.Diagnostic) string {
+	t.Helper()
+	type offsetEdit struct {
+		start, end int
+		newText    string
+	}
+	seen := make(map[offsetEdit]bool)
+	var edits []offsetEdit
+	for _, diagnostic := range diagnostics {
+		for _, fix := range diagnostic.SuggestedFixes {
+			for _, edit := range fix.TextEdits {
+				file := pass.Fset.File(edit.Pos)
+				e := offsetEdit{file.Offset(edit.Pos), file.Offset(edit.End), string(edit.NewText)}
+				if !seen[e] {
+					seen[e] = true
+					edits = append(edits, e)
+				}
+			}
+		}
+	}
+	sort.Slice(edits, func(a, b int) bool {
+		return edits[a].start < edits[b].start
+	})
+	var buf bytes.Buffer
+	last := 0
+	for _, edit := range edits {
+		if edit.start < last {
+			t.Fatalf("Overlapping edits at offset %d: %q", edit.start, edit.newText)
+		}
+		buf.WriteString(src[last:edit.start])
+		buf.WriteString(edit.newText)
+		last = edit.end
+	}
+	buf.WriteString(src[last:])
+	return buf.String()
+}
+
+// runFix runs Analyzer on package foo with src, then applies the fixes. Third-party packages example.com/bar and rsc.io/bar can be imported.
+func runFix(t *testing.T, src string) (fixed string, diagnostics []analysis.Diagnostic) {
+	t.Helper()
+	const barFile = "package bar\n\nfunc Bar() {}\n"
+	dir := makePackageDir(t, map[string]string{
+		"src/foo/foo.go":             src,
+		"src/example.com/bar/bar.go": barFile,
+		"src/rsc.io/bar/bar.go":      barFile,
+	})
+	result := analysistest.Run(ignoreTestingErrorf{}, dir, Analyzer, "foo")
+	if !assert.Equal(t, 1, len(result)) {
+		t.FailNow()
+	}
+	return applyFixes(t, result[0].Pass, src, result[0].Diagnostics), result[0].Diagnostics
+}
+
+func TestFixPlumbsErrors(t *testing.T) {
+	t.Parallel()
+	const (
+		fooFile = `
+//go:build js && wasm
+
+package foo
+
+import (
+	"syscall/js"
+)
+
+func InsertButton(container js.Value) (js.Value, error) {
+	dom := js.Global().Get("document")
+	button := dom.Call("createElement", "button")
+	container.Call("appendChild", button)
+	return button, nil
+}
+`
+		expected = `
+//go:build js && wasm
+
+package foo
+
+import (
+	"syscall/js"
+
+	"github.com/hack-pad/safejs"
+)
+
+func InsertButton(container js.Value) (js.Value, error) {
+	dom, err := safejs.Global().Get("document")
+	if err != nil {
+		return js.Value{}, err
+	}
+	button, err := dom.Call("createElement", "button")
+	if err != nil {
+		return js.Value{}, err
+	}
+	if _, err := safejs.Safe(container).Call("appendChild", button); err != nil {
+		return js.Value{}, err
+	}
+	return safejs.Unsafe(button), nil
+}
+`
+	)
+	fixed, diagnostics := runFix(t, fooFile)
+	assert.Equal(t, expected, fixed)
+	assert.Equal(t, 3, len(diagnostics))
+	for _, diagnostic := range diagnostics {
+		if assert.Equal(t, 1, len(diagnostic.SuggestedFixes)) {
+			assert.Equal(t, fixMessage, diagnostic.SuggestedFixes[0].Message)
+		}
+	}
+}
+
+func TestFixReplacesImport(t *testing.T) {
+	t.Parallel()
+	const (
+		fooFile = `
+//go:build js && wasm
+
+package foo
+
+import "syscall/js"
+
+func Title() (string, error) {
+	return js.Global().Get("document").Get("title").String(), nil
+}
+
+func SetTitle(title string) error {
+	js.Global().Get("document").Set("title", title)
+	return nil
+}
+
+func IsMissing(name string) (bool, error) {
+	missing := js.Global().Get(name).IsUndefined()
+	return missing, nil
+}
+`
+		expected = `
+//go:build js && wasm
+
+package foo
+
+import "github.com/hack-pad/safejs"
+
+func Title() (string, error) {
+	document, err := safejs.Global().Get("document")
+	if err != nil {
+		return "", err
+	}
+	title, err := document.Get("title")
+	if err != nil {
+		return "", err
+	}
+	result, err := title.String()
+	if err != nil {
+		return "", err
+	}
+	return result, nil
+}
+
+func SetTitle(title string) error {
+	document, err := safejs.Global().Get("document")
+	if err != nil {
+		return err
+	}
+	if err := document.Set("title", title); err != nil {
+		return err
+	}
+	return nil
+}
+
+func IsMissing(name string) (bool, error) {
+	value, err := safejs.Global().Get(name)
+	if err != nil {
+		return false, err
+	}
+	missing := value.IsUndefined()
+	return missing, nil
+}
+`
+	)
+	fixed, _ := runFix(t, fooFile)
+	assert.Equal(t, expected, fixed)
+}
+
+func TestFixBoundaryConversions(t *testing.T) {
+	t.Parallel()
+	const (
+		fooFile = `
+//go:build js && wasm
+
+package foo
+
+import (
+	"syscall/js"
+)
+
+type holder struct {
+	value js.Value
+}
+
+func use(js.Value) {}
+
+func wrap(v js.Value) js.Value { return v }
+
+func Foo(h *holder, v js.Value) error {
+	h.value = v.Get("x")
+	elem := v.Index(0)
+	use(elem)
+	v.Call("f", wrap(elem))
+	count := elem.Length()
+	_ = count
+	func() error {
+		elem.Set("y", v)
+		return nil
+	}()
+	return nil
+}
+
+func NoError(v js.Value) {
+	v.Get("x")
+}
+`
+		expected = `
+//go:build js && wasm
+
+package foo
+
+import (
+	"syscall/js"
+
+	"github.com/hack-pad/safejs"
+)
+
+type holder struct {
+	value js.Value
+}
+
+func use(js.Value) {}
+
+func wrap(v js.Value) js.Value { return v }
+
+func Foo(h *holder, v js.Value) error {
+	x, err := safejs.Safe(v).Get("x")
+	if err != nil {
+		return err
+	}
+	h.value = safejs.Unsafe(x)
+	elem, err := safejs.Safe(v).Index(0)
+	if err != nil {
+		return err
+	}
+	use(safejs.Unsafe(elem))
+	if _, err := safejs.Safe(v).Call("f", wrap(safejs.Unsafe(elem))); err != nil {
+		return err
+	}
+	count, err := elem.Length()
+	if err != nil {
+		return err
+	}
+	_ = count
+	func() error {
+		if err := elem.Set("y", v); err != nil {
+			return err
+		}
+		return nil
+	}()
+	return nil
+}
+
+func NoError(v js.Value) {
+	if _, err := safejs.Safe(v).Get("x"); err != nil {
+		panic(err)
+	}
+}
+`
+	)
+	fixed, _ := runFix(t, fooFile)
+	assert.Equal(t, expected, fixed)
+}
+
+func TestFixPanicsWithoutErrorResult(t *testing.T) {
+	t.Parallel()
+	const (
+		fooFile = `
+//go:build js && wasm
+
+package foo
+
+import (
+	"syscall/js"
+)
+
+func Title() string {
+	return js.Global().Get("document").Get("title").String()
+}
+
+func SetTitle(title string) {
+	js.Global().Get("document").Set("title", title)
+	js.CopyBytesToJS(js.Null(), nil)
+}
+`
+		expected = `
+//go:build js && wasm
+
+package foo
+
+import (
+	"syscall/js"
+
+	"github.com/hack-pad/safejs"
+)
+
+func Title() string {
+	document, err := safejs.Global().Get("document")
+	if err != nil {
+		panic(err)
+	}
+	title, err := document.Get("title")
+	if err != nil {
+		panic(err)
+	}
+	result, err := title.String()
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
+func SetTitle(title string) {
+	document, err := safejs.Global().Get("document")
+	if err != nil {
+		panic(err)
+	}
+	if err := document.Set("title", title); err != nil {
+		panic(err)
+	}
+	js.CopyBytesToJS(js.Null(), nil)
+}
+`
+	)
+	fixed, diagnostics := runFix(t, fooFile)
+	assert.Equal(t, expected, fixed)
+	var fixCounts []int
+	for _, diagnostic := range diagnostics {
+		fixCounts = append(fixCounts, len(diagnostic.SuggestedFixes))
+	}
+	assert.Equal(t, []int{1, 0, 0, 1, 0, 0}, fixCounts) // only each statement's outermost call gets its fix
+}
+
+func TestFixImportsAreSorted(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		name    string
+		imports string
+		uses    string
+		expect  string
+	}{
+		{
+			name:    "add to group",
+			imports: "import (\n\t\"fmt\"\n\t\"strings\"\n\t\"syscall/js\"\n)",
+			uses:    "var _ = fmt.Sprint\nvar _ = strings.Title\nvar null = js.Null()",
+			expect:  "import (\n\t\"fmt\"\n\t\"strings\"\n\t\"syscall/js\"\n\n\t\"github.com/hack-pad/safejs\"\n)",
+		},
+		{
+			name:    "add to single import",
+			imports: "import \"syscall/js\"",
+			uses:    "var null = js.Null()",
+			expect:  "import (\n\t\"syscall/js\"\n\n\t\"github.com/hack-pad/safejs\"\n)",
+		},
+		{
+			name:    "replace in group",
+			imports: "import (\n\t\"strings\"\n\t\"syscall/js\"\n)",
+			uses:    "var _ = strings.Title",
+			expect:  "import (\n\t\"strings\"\n\n\t\"github.com/hack-pad/safejs\"\n)",
+		},
+		{
+			name:    "replace in place",
+			imports: "import (\n\t\"fmt\"\n\n\t\"syscall/js\"\n)",
+			uses:    "var _ = fmt.Sprint",
+			expect:  "import (\n\t\"fmt\"\n\n\t\"github.com/hack-pad/safejs\"\n)",
+		},
+		{
+			name:    "add to end of third-party group",
+			imports: "import (\n\t\"fmt\"\n\t\"syscall/js\"\n\n\t\"example.com/bar\"\n)",
+			uses:    "var _ = fmt.Sprint\nvar _ = bar.Bar\nvar null = js.Null()",
+			expect:  "import (\n\t\"fmt\"\n\t\"syscall/js\"\n\n\t\"example.com/bar\"\n\t\"github.com/hack-pad/safejs\"\n)",
+		},
+		{
+			name:    "add to start of third-party group",
+			imports: "import (\n\t\"fmt\"\n\t\"syscall/js\"\n\n\t\"rsc.io/bar\" // bar\n)",
+			uses:    "var _ = fmt.Sprint\nvar _ = bar.Bar",
+			expect:  "import (\n\t\"fmt\"\n\n\t\"github.com/hack-pad/safejs\"\n\t\"rsc.io/bar\" // bar\n)",
+		},
+		{
+			name:    "remove standard library group",
+			imports: "import (\n\t\"syscall/js\"\n\n\t\"example.com/bar\"\n)",
+			uses:    "var _ = bar.Bar",
+			expect:  "import (\n\t\"example.com/bar\"\n\t\"github.com/hack-pad/safejs\"\n)",
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			const body = `
+
+func Foo() error {
+	js.Global().Get("x")
+	return nil
+}
+`
+			fooFile := "package foo\n\n" + tc.imports + "\n\n" + tc.uses + body
+			fixed, _ := runFix(t, fooFile)
+			formatted, err := format.Source([]byte(fixed))
+			assert.NoError(t, err)
+			assert.Equal(t, string(formatted), fixed)
+			if !strings.HasPrefix(fixed, "package foo\n\n"+tc.expect+"\n") {
+				t.Errorf("Unexpected imports in fixed file:\n%s", fixed)
+			}
+		})
+	}
+}
+
+func TestFixUnconvertibleVariable(t *testing.T) {
+	t.Parallel()
+	const (
+		fooFile = `
+//go:build js && wasm
+
+package foo
+
+import (
+	"syscall/js"
+)
+
+func Foo(v js.Value) (int, error) {
+	value := v.Get("x")
+	value = js.Null()
+	return value.Int(), nil
+}
+`
+		expected = `
+//go:build js && wasm
+
+package foo
+
+import (
+	"syscall/js"
+
+	"github.com/hack-pad/safejs"
+)
+
+func Foo(v js.Value) (int, error) {
+	x, err := safejs.Safe(v).Get("x")
+	if err != nil {
+		return 0, err
+	}
+	value := safejs.Unsafe(x)
+	value = js.Null()
+	result, err := safejs.Safe(value).Int()
+	if err != nil {
+		return 0, err
+	}
+	return result, nil
+}
+`
+	)
+	fixed, _ := runFix(t, fooFile)
+	assert.Equal(t, expected, fixed)
+}
@@ -0,0 +1,290 @@
+//go:build !js
+
+package jsguard
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// FuncLeakAnalyzer reports Funcs from js.FuncOf and safejs.FuncOf which are never released and don't escape the function creating them
+var FuncLeakAnalyzer = &analysis.Analyzer{
+	Name:      "funcleak",
+	Doc:       "report js.FuncOf and safejs.FuncOf results which are never released",
+	Run:       runFuncLeak,
+	FactTypes: []analysis.Fact{new(takesOwnershipFact)},
+}
+
+// takesOwnershipDirective marks a function as taking ownership of any Funcs passed to it, making it responsible for releasing them.
+//
+// For example:
+//
+//	// AddListener calls fn on every event until the returned function is called.
+//	//
+//	//jsguard:takes-ownership
+//	func AddListener(target js.Value, fn js.Func) (remove func())
+const takesOwnershipDirective = "//jsguard:takes-ownership"
+
+// takesOwnershipFact marks functions documented with takesOwnershipDirective
+type takesOwnershipFact struct{}
+
+func (*takesOwnershipFact) AFact() {}
+
+func (*takesOwnershipFact) String() string {
+	return "takesOwnership"
+}
+
+// hasDirective returns true if doc has a line starting with directive
+func hasDirective(doc *ast.CommentGroup, directive string) bool {
+	if doc == nil {
+		return false
+	}
+	for _, comment := range doc.List {
+		if comment.Text == directive || strings.HasPrefix(comment.Text, directive+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+func runFuncLeak(pass *analysis.Pass) (interface{}, error) {
+	finishBaseline, err := applyBaseline(pass)
+	if err != nil {
+		return nil, err
+	}
+	honorIgnoreDirectives(pass)
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			funcDecl, ok := decl.(*ast.FuncDecl)
+			if ok && hasDirective(funcDecl.Doc, takesOwnershipDirective) {
+				if fn := pass.TypesInfo.Defs[funcDecl.Name]; fn != nil {
+					pass.ExportObjectFact(fn, &takesOwnershipFact{})
+				}
+			}
+		}
+	}
+
+	for _, file := range pass.Files {
+		parents := parentMap(file)
+		ast.Inspect(file, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if ok && isFuncOfCall(pass, call) {
+				inspectFuncOf(pass, parents, call)
+			}
+			return true
+		})
+	}
+	return nil, finishBaseline()
+}
+
+// isFuncOfCall returns true if call is a call to js.FuncOf or safejs.FuncOf
+func isFuncOfCall(pass *analysis.Pass, call *ast.CallExpr) bool {
+	selector, ok := unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	fn := selectedFunc(pass, selector)
+	if fn == nil || fn.Pkg() == nil || fn.Name() != "FuncOf" {
+		return false
+	}
+	path := fn.Pkg().Path()
+	return path == jsPackagePath || path == safejsPackagePath
+}
+
+// takesOwnership returns true if call's function is documented to take ownership of Funcs passed to it
+func takesOwnership(pass *analysis.Pass, call *ast.CallExpr) bool {
+	var obj types.Object
+	switch fun := unparen(call.Fun).(type) {
+	case *ast.Ident:
+		obj = pass.TypesInfo.Uses[fun]
+	case *ast.SelectorExpr:
+		obj = selectedFunc(pass, fun)
+	}
+	fn, ok := obj.(*types.Func)
+	return ok && pass.ImportObjectFact(fn, &takesOwnershipFact{})
+}
+
+func isBuiltin(pass *analysis.Pass, call *ast.CallExpr, name string) bool {
+	ident, ok := unparen(call.Fun).(*ast.Ident)
+	if !ok {
+		return false
+	}
+	builtin, ok := pass.TypesInfo.Uses[ident].(*types.Builtin)
+	return ok && builtin.Name() == name
+}
+
+func inspectFuncOf(pass *analysis.Pass, parents map[ast.Node]ast.Node, call *ast.CallExpr) {
+	funcOfName := formatNode(pass.Fset, call.Fun)
+	var lhs ast.Expr
+	var stmt ast.Stmt
+	switch parent := parentOf(parents, call).(type) {
+	case *ast.AssignStmt:
+		if len(parent.Rhs) != 1 {
+			return
+		}
+		lhs, stmt = parent.Lhs[0], parent
+	case *ast.ValueSpec:
+		if len(parent.Values) != 1 {
+			return
+		}
+		lhs = parent.Names[0]
+		if declStmt, ok := parents[parents[parent]].(*ast.DeclStmt); ok {
+			stmt = declStmt
+		}
+	case *ast.CallExpr:
+		if takesOwnership(pass, parent) || isBuiltin(pass, parent, "append") {
+			return
+		}
+		reportf(pass, ruleUnreleasedFunc, call.Pos(), "Func from %s(...) is never released", funcOfName)
+		return
+	case *ast.ReturnStmt, *ast.CompositeLit, *ast.KeyValueExpr, *ast.SendStmt:
+		return // escapes
+	default:
+		reportf(pass, ruleUnreleasedFunc, call.Pos(), "Func from %s(...) is never released", funcOfName)
+		return
+	}
+
+	ident, ok := lhs.(*ast.Ident)
+	if !ok {
+		return // stored in a field, index, or pointer
+	}
+	if ident.Name == "_" {
+		reportf(pass, ruleUnreleasedFunc, call.Pos(), "Func from %s(...) is never released", funcOfName)
+		return
+	}
+	variable, ok := pass.TypesInfo.ObjectOf(ident).(*types.Var)
+	if !ok || variable.Parent() == pass.Pkg.Scope() {
+		return // package-level variables live forever
+	}
+	if funcReleasedOrEscapes(pass, parents, variable) {
+		return
+	}
+	pass.Report(analysis.Diagnostic{
+		Pos:            call.Pos(),
+		Category:       ruleUnreleasedFunc,
+		Message:        fmt.Sprintf("Func %s from %s(...) is never released", ident.Name, funcOfName),
+		SuggestedFixes: deferReleaseFix(pass, parents, stmt, ident.Name),
+	})
+}
+
+// funcReleasedOrEscapes returns true if variable is released, or escapes by being returned, stored, or passed to an owner.
+// Local aliases like 'g := fn' are followed, while blank assignments like '_ = fn' neither release nor store it.
+func funcReleasedOrEscapes(pass *analysis.Pass, parents map[ast.Node]ast.Node, variable *types.Var) bool {
+	return funcAliasReleasedOrEscapes(pass, parents, variable, make(map[*types.Var]bool))
+}
+
+func funcAliasReleasedOrEscapes(pass *analysis.Pass, parents map[ast.Node]ast.Node, variable *types.Var, visited map[*types.Var]bool) bool {
+	visited[variable] = true
+	// assignedTo returns true if assigning the Func to lhs releases it through an alias, or lets it escape
+	assignedTo := func(lhs *ast.Ident) bool {
+		if lhs.Name == "_" {
+			return false
+		}
+		alias, ok := pass.TypesInfo.ObjectOf(lhs).(*types.Var)
+		if !ok || alias.Parent() == pass.Pkg.Scope() {
+			return true // stored in a package-level variable
+		}
+		return !visited[alias] && funcAliasReleasedOrEscapes(pass, parents, alias, visited)
+	}
+
+	for ident, obj := range pass.TypesInfo.Uses {
+		if obj != variable {
+			continue
+		}
+		switch parent := parentOf(parents, ident).(type) {
+		case *ast.SelectorExpr:
+			call, isCall := parentOf(parents, parent).(*ast.CallExpr)
+			if parent.Sel.Name == "Release" && isCall && unparen(call.Fun) == parent {
+				return true
+			}
+		case *ast.CallExpr:
+			if parent.Fun != ident && (takesOwnership(pass, parent) || isBuiltin(pass, parent, "append")) {
+				return true
+			}
+		case *ast.AssignStmt:
+			for i, rhs := range parent.Rhs {
+				if unparen(rhs) != ident || i >= len(parent.Lhs) {
+					continue
+				}
+				lhs, isIdent := unparen(parent.Lhs[i]).(*ast.Ident)
+				if !isIdent || assignedTo(lhs) {
+					return true // stored in a field, index, or pointer
+				}
+			}
+		case *ast.ValueSpec:
+			for i, value := range parent.Values {
+				if unparen(value) == ident && i < len(parent.Names) && assignedTo(parent.Names[i]) {
+					return true
+				}
+			}
+		case *ast.ReturnStmt, *ast.CompositeLit, *ast.KeyValueExpr, *ast.SendStmt:
+			return true
+		case *ast.UnaryExpr:
+			if parent.Op == token.AND {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// deferReleaseFix suggests adding 'defer name.Release()' after stmt, or after stmt's error check if present
+func deferReleaseFix(pass *analysis.Pass, parents map[ast.Node]ast.Node, stmt ast.Stmt, name string) []analysis.SuggestedFix {
+	if stmt == nil {
+		return nil
+	}
+	var siblings []ast.Stmt
+	switch block := parents[stmt].(type) {
+	case *ast.BlockStmt:
+		siblings = block.List
+	case *ast.CaseClause:
+		siblings = block.Body
+	case *ast.CommClause:
+		siblings = block.Body
+	default:
+		return nil
+	}
+
+	insertAfter := stmt
+	for i, sibling := range siblings {
+		if sibling == stmt && i+1 < len(siblings) && isErrorCheck(pass, stmt, siblings[i+1]) {
+			insertAfter = siblings[i+1]
+		}
+	}
+	indent := lineIndent(pass.Fset, stmt.Pos())
+	return []analysis.SuggestedFix{{
+		Message: fmt.Sprintf("Add 'defer %s.Release()'", name),
+		TextEdits: []analysis.TextEdit{{
+			Pos:     insertAfter.End(),
+			End:     insertAfter.End(),
+			NewText: []byte(fmt.Sprintf("\n%sdefer %s.Release()", indent, name)),
+		}},
+	}}
+}
+
+// isErrorCheck returns true if next is an if statement checking the error assigned by stmt, like 'fn, err := safejs.FuncOf(...)'
+func isErrorCheck(pass *analysis.Pass, stmt, next ast.Stmt) bool {
+	assign, ok := stmt.(*ast.AssignStmt)
+	ifStmt, isIf := next.(*ast.IfStmt)
+	if !ok || !isIf || len(assign.Lhs) != 2 {
+		return false
+	}
+	errIdent, ok := assign.Lhs[1].(*ast.Ident)
+	if !ok {
+		return false
+	}
+	errObj := pass.TypesInfo.ObjectOf(errIdent)
+	found := false
+	ast.Inspect(ifStmt.Cond, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Ident); ok && errObj != nil && pass.TypesInfo.Uses[ident] == errObj {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
@@ -0,0 +1,210 @@
+//go:build !js
+
+package jsguard
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hack-pad/safejs/internal/assert"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestFuncLeak(t *testing.T) {
+	t.Parallel()
+	const (
+		domName = "src/dom/dom.go"
+		domFile = `
+//go:build js && wasm
+
+package dom
+
+import "syscall/js"
+
+// AddListener releases fn when the listener is removed
+//
+//jsguard:takes-ownership
+func AddListener(target js.Value, fn js.Func) (remove func()) {
+	return fn.Release
+}
+`
+		fooName = "src/foo/foo.go"
+		fooFile = `
+//go:build js && wasm
+
+package foo
+
+import (
+	"dom"
+	"syscall/js"
+
+	"github.com/hack-pad/safejs"
+)
+
+type handler struct {
+	fn js.Func
+}
+
+var global = js.FuncOf(nil)
+
+func use(js.Func) {}
+
+func Released() {
+	fn := js.FuncOf(nil)
+	fn.Release()
+}
+
+func Deferred() error {
+	fn, err := safejs.FuncOf(nil)
+	if err != nil {
+		return err
+	}
+	defer fn.Release()
+	return nil
+}
+
+func Returned() js.Func {
+	fn := js.FuncOf(nil)
+	return fn
+}
+
+func Stored(h *handler) {
+	h.fn = js.FuncOf(nil)
+	fn := js.FuncOf(nil)
+	h2 := handler{fn: fn}
+	_ = h2
+}
+
+func Owned(target js.Value) {
+	dom.AddListener(target, js.FuncOf(nil))
+	fn := js.FuncOf(nil)
+	dom.AddListener(target, fn)
+}
+
+func Leaked(target js.Value) error {
+	use(js.FuncOf(nil))
+	target.Set("onclick", js.FuncOf(nil))
+	_ = js.FuncOf(nil)
+	fn := js.FuncOf(nil)
+	use(fn)
+	safeFn, err := safejs.FuncOf(nil)
+	if err != nil {
+		return err
+	}
+	target.Set("onload", safejs.Unsafe(safeFn.Value()))
+	return nil
+}
+
+func Aliased(h *handler) {
+	fn := js.FuncOf(nil)
+	g := fn
+	g.Release()
+	fn2 := js.FuncOf(nil)
+	var g2 = fn2
+	h.fn = g2
+}
+
+func Blank() {
+	fn := js.FuncOf(nil)
+	_ = fn
+}
+
+func ReleaseValue() func() {
+	fn := js.FuncOf(nil)
+	release := fn.Release
+	_ = release
+	return nil
+}
+`
+	)
+	dir := makePackageDir(t, map[string]string{
+		safejsStubName: safejsStubFile,
+		domName:        domFile,
+		fooName:        fooFile,
+	})
+
+	result := analysistest.Run(ignoreTestingErrorf{}, dir, FuncLeakAnalyzer, "foo")
+	if !assert.Equal(t, 1, len(result)) {
+		t.FailNow()
+	}
+	result0 := result[0]
+	pass := result0.Pass
+	leakedIndex := strings.Index(fooFile, "func Leaked")
+	indexAfterLeaked := func(substr string) int {
+		return leakedIndex + strings.Index(fooFile[leakedIndex:], substr)
+	}
+	fnDefine := indexAfterLeaked("fn := js.FuncOf(nil)")
+	safeFnCheck := indexAfterLeaked("if err != nil {\n\t\treturn err\n\t}")
+	blankFnDefine := strings.Index(fooFile, "fn := js.FuncOf(nil)\n\t_ = fn")
+	releaseValueFnDefine := strings.Index(fooFile, "fn := js.FuncOf(nil)\n\trelease")
+	assertDiagnostics(t, []analysis.Diagnostic{
+		{
+			Pos:      filePos(t, pass, "foo", indexAfterLeaked("js.FuncOf(nil))")),
+			Category: "unreleased-func",
+			Message:  "Func from js.FuncOf(...) is never released",
+		},
+		{
+			Pos:      filePos(t, pass, "foo", indexAfterLeaked(`js.FuncOf(nil))`+"\n\t_")),
+			Category: "unreleased-func",
+			Message:  "Func from js.FuncOf(...) is never released",
+		},
+		{
+			Pos:      filePos(t, pass, "foo", indexAfterLeaked("js.FuncOf(nil)\n\tfn")),
+			Category: "unreleased-func",
+			Message:  "Func from js.FuncOf(...) is never released",
+		},
+		{
+			Pos:      filePos(t, pass, "foo", fnDefine+len("fn := ")),
+			Category: "unreleased-func",
+			Message:  "Func fn from js.FuncOf(...) is never released",
+			SuggestedFixes: []analysis.SuggestedFix{{
+				Message: "Add 'defer fn.Release()'",
+				TextEdits: []analysis.TextEdit{{
+					Pos:     filePos(t, pass, "foo", fnDefine+len("fn := js.FuncOf(nil)")),
+					End:     filePos(t, pass, "foo", fnDefine+len("fn := js.FuncOf(nil)")),
+					NewText: []byte("\n\tdefer fn.Release()"),
+				}},
+			}},
+		},
+		{
+			Pos:      filePos(t, pass, "foo", indexAfterLeaked("safejs.FuncOf(nil)")),
+			Category: "unreleased-func",
+			Message:  "Func safeFn from safejs.FuncOf(...) is never released",
+			SuggestedFixes: []analysis.SuggestedFix{{
+				Message: "Add 'defer safeFn.Release()'",
+				TextEdits: []analysis.TextEdit{{
+					Pos:     filePos(t, pass, "foo", safeFnCheck+len("if err != nil {\n\t\treturn err\n\t}")),
+					End:     filePos(t, pass, "foo", safeFnCheck+len("if err != nil {\n\t\treturn err\n\t}")),
+					NewText: []byte("\n\tdefer safeFn.Release()"),
+				}},
+			}},
+		},
+		{
+			Pos:      filePos(t, pass, "foo", blankFnDefine+len("fn := ")),
+			Category: "unreleased-func",
+			Message:  "Func fn from js.FuncOf(...) is never released",
+			SuggestedFixes: []analysis.SuggestedFix{{
+				Message: "Add 'defer fn.Release()'",
+				TextEdits: []analysis.TextEdit{{
+					Pos:     filePos(t, pass, "foo", blankFnDefine+len("fn := js.FuncOf(nil)")),
+					End:     filePos(t, pass, "foo", blankFnDefine+len("fn := js.FuncOf(nil)")),
+					NewText: []byte("\n\tdefer fn.Release()"),
+				}},
+			}},
+		},
+		{
+			Pos:      filePos(t, pass, "foo", releaseValueFnDefine+len("fn := ")),
+			Category: "unreleased-func",
+			Message:  "Func fn from js.FuncOf(...) is never released",
+			SuggestedFixes: []analysis.SuggestedFix{{
+				Message: "Add 'defer fn.Release()'",
+				TextEdits: []analysis.TextEdit{{
+					Pos:     filePos(t, pass, "foo", releaseValueFnDefine+len("fn := js.FuncOf(nil)")),
+					End:     filePos(t, pass, "foo", releaseValueFnDefine+len("fn := js.FuncOf(nil)")),
+					NewText: []byte("\n\tdefer fn.Release()"),
+				}},
+			}},
+		},
+	}, result0.Diagnostics)
+}
@@ -0,0 +1,315 @@
+//go:build !js
+
+package jsguard
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/cfg"
+)
+
+// FuncReleaseAnalyzer reports js.Func and safejs.Func variables used after Release on all paths, and Funcs released twice
+var FuncReleaseAnalyzer = &analysis.Analyzer{
+	Name: "funcrelease",
+	Doc:  "report js.Func and safejs.Func values used or released again after Release",
+	Run:  runFuncRelease,
+}
+
+func runFuncRelease(pass *analysis.Pass) (interface{}, error) {
+	finishBaseline, err := applyBaseline(pass)
+	if err != nil {
+		return nil, err
+	}
+	honorIgnoreDirectives(pass)
+	for _, file := range pass.Files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.FuncDecl:
+				if node.Body != nil {
+					inspectFuncRelease(pass, node.Type, node.Body)
+				}
+			case *ast.FuncLit:
+				inspectFuncRelease(pass, node.Type, node.Body)
+			}
+			return true
+		})
+	}
+	return nil, finishBaseline()
+}
+
+// releasedFuncs is the set of Func variables released on all paths to a point in a function
+type releasedFuncs map[*types.Var]bool
+
+func (r releasedFuncs) clone() releasedFuncs {
+	clone := make(releasedFuncs, len(r))
+	for variable := range r {
+		clone[variable] = true
+	}
+	return clone
+}
+
+// intersect removes variables missing from other
+func (r releasedFuncs) intersect(other releasedFuncs) {
+	for variable := range r {
+		if !other[variable] {
+			delete(r, variable)
+		}
+	}
+}
+
+func (r releasedFuncs) equal(other releasedFuncs) bool {
+	if len(r) != len(other) {
+		return false
+	}
+	for variable := range r {
+		if !other[variable] {
+			return false
+		}
+	}
+	return true
+}
+
+type funcReleaseChecker struct {
+	pass *analysis.Pass
+	// tracked are the Func variables declared in the function, excluding any which may change behind its back
+	tracked     map[*types.Var]bool
+	diagnostics []analysis.Diagnostic
+}
+
+// inspectFuncRelease runs a forward data flow analysis over the function's control flow graph, tracking which Func variables must be released
+func inspectFuncRelease(pass *analysis.Pass, funcType *ast.FuncType, body *ast.BlockStmt) {
+	checker := &funcReleaseChecker{
+		pass:    pass,
+		tracked: trackedFuncVars(pass, funcType, body),
+	}
+	if len(checker.tracked) == 0 {
+		return
+	}
+	graph := cfg.New(body, func(call *ast.CallExpr) bool {
+		return !isBuiltin(pass, call, "panic")
+	})
+
+	// entry states start as the set of all variables (unvisited), then shrink with each visit until reaching a fixed point
+	entries := make(map[*cfg.Block]releasedFuncs)
+	entries[graph.Blocks[0]] = make(releasedFuncs)
+	worklist := []*cfg.Block{graph.Blocks[0]}
+	for len(worklist) > 0 {
+		block := worklist[0]
+		worklist = worklist[1:]
+		state := entries[block].clone()
+		for _, node := range block.Nodes {
+			checker.transfer(node, state, false)
+		}
+		for _, succ := range block.Succs {
+			entry, visited := entries[succ]
+			if !visited {
+				entries[succ] = state.clone()
+				worklist = append(worklist, succ)
+				continue
+			}
+			before := entry.clone()
+			entry.intersect(state)
+			if !entry.equal(before) {
+				worklist = append(worklist, succ)
+			}
+		}
+	}
+
+	for _, block := range graph.Blocks {
+		entry, visited := entries[block]
+		if !visited {
+			continue
+		}
+		state := entry.clone()
+		for _, node := range block.Nodes {
+			checker.transfer(node, state, true)
+		}
+	}
+	sort.SliceStable(checker.diagnostics, func(a, b int) bool {
+		return checker.diagnostics[a].Pos < checker.diagnostics[b].Pos
+	})
+	for _, diagnostic := range checker.diagnostics {
+		pass.Report(diagnostic)
+	}
+}
+
+// trackedFuncVars returns the Func variables declared in a function's parameters or body.
+// Skips variables whose address is taken or which are assigned inside nested function literals, since they may change at any time.
+func trackedFuncVars(pass *analysis.Pass, funcType *ast.FuncType, body *ast.BlockStmt) map[*types.Var]bool {
+	tracked := make(map[*types.Var]bool)
+	addDefs := func(node ast.Node) {
+		ast.Inspect(node, func(node ast.Node) bool {
+			if _, ok := node.(*ast.FuncLit); ok {
+				return false
+			}
+			if ident, ok := node.(*ast.Ident); ok {
+				if variable, ok := pass.TypesInfo.Defs[ident].(*types.Var); ok && isFuncType(variable.Type()) {
+					tracked[variable] = true
+				}
+			}
+			return true
+		})
+	}
+	addDefs(funcType)
+	addDefs(body)
+
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.UnaryExpr:
+			if node.Op == token.AND {
+				delete(tracked, identVar(pass, node.X))
+			}
+		case *ast.FuncLit:
+			ast.Inspect(node.Body, func(node ast.Node) bool {
+				if assign, ok := node.(*ast.AssignStmt); ok {
+					for _, lhs := range assign.Lhs {
+						delete(tracked, identVar(pass, lhs))
+					}
+				}
+				return true
+			})
+		}
+		return true
+	})
+	return tracked
+}
+
+// isFuncType returns true if typ is js.Func or safejs.Func
+func isFuncType(typ types.Type) bool {
+	return isJSType(typ, "Func") || isNamedType(typ, safejsPackagePath, "Func")
+}
+
+// identVar returns the variable expr refers to, or nil if it isn't an identifier
+func identVar(pass *analysis.Pass, expr ast.Expr) *types.Var {
+	ident, ok := unparen(expr).(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	variable, _ := pass.TypesInfo.ObjectOf(ident).(*types.Var)
+	return variable
+}
+
+// transfer updates state with the effects of a control flow graph node, in evaluation order.
+// Diagnostics are only recorded if report is true.
+func (c *funcReleaseChecker) transfer(node ast.Node, state releasedFuncs, report bool) {
+	switch node := node.(type) {
+	case *ast.AssignStmt:
+		for _, rhs := range node.Rhs {
+			c.uses(rhs, state, report)
+		}
+		for _, lhs := range node.Lhs {
+			if variable := identVar(c.pass, lhs); variable != nil {
+				delete(state, variable)
+			} else {
+				c.uses(lhs, state, report)
+			}
+		}
+	case *ast.ValueSpec:
+		for _, value := range node.Values {
+			c.uses(value, state, report)
+		}
+		for _, name := range node.Names {
+			delete(state, identVar(c.pass, name))
+		}
+	case *ast.Ident:
+		// range keys and values, and select receive variables, are assigned
+		delete(state, identVar(c.pass, node))
+	case *ast.DeferStmt:
+		// deferred calls evaluate their receiver and arguments now, but run later
+		if variable := c.releasedVar(node.Call); variable != nil {
+			if state[variable] {
+				c.reportDoubleRelease(node.Call, report)
+			}
+			return
+		}
+		c.uses(node.Call, state, report)
+	default:
+		c.uses(node, state, report)
+	}
+}
+
+// uses checks uses of tracked variables in node, and records Release calls in state
+func (c *funcReleaseChecker) uses(node ast.Node, state releasedFuncs, report bool) {
+	ast.Inspect(node, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.FuncLit:
+			// calls inside function literals may run any time later, so only report uses
+			c.closureUses(node.Body, state, report)
+			return false
+		case *ast.CallExpr:
+			variable := c.releasedVar(node)
+			if variable == nil {
+				return true
+			}
+			if state[variable] {
+				c.reportDoubleRelease(node, report)
+			}
+			state[variable] = true
+			return false
+		case *ast.Ident:
+			if variable := identVar(c.pass, node); variable != nil && state[variable] && report {
+				c.reportUse(node, variable)
+			}
+		}
+		return true
+	})
+}
+
+// closureUses reports uses of released variables inside a function literal created after they were released
+func (c *funcReleaseChecker) closureUses(body *ast.BlockStmt, state releasedFuncs, report bool) {
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.CallExpr:
+			if variable := c.releasedVar(node); variable != nil && state[variable] {
+				c.reportDoubleRelease(node, report)
+				return false
+			}
+		case *ast.Ident:
+			if variable := identVar(c.pass, node); variable != nil && state[variable] && report {
+				c.reportUse(node, variable)
+			}
+		}
+		return true
+	})
+}
+
+// releasedVar returns the tracked variable released by call, if it's a call to js.Func.Release or safejs.Func.Release
+func (c *funcReleaseChecker) releasedVar(call *ast.CallExpr) *types.Var {
+	selector, ok := unparen(call.Fun).(*ast.SelectorExpr)
+	if !ok || selector.Sel.Name != "Release" {
+		return nil
+	}
+	variable := identVar(c.pass, selector.X)
+	if variable == nil || !c.tracked[variable] {
+		return nil
+	}
+	return variable
+}
+
+func (c *funcReleaseChecker) reportUse(ident *ast.Ident, variable *types.Var) {
+	if !c.tracked[variable] {
+		return
+	}
+	c.diagnostics = append(c.diagnostics, analysis.Diagnostic{
+		Pos:      ident.Pos(),
+		Category: ruleFuncUseAfterRelease,
+		Message:  fmt.Sprintf("Func %s is used after %s.Release(): calling a released Func panics", ident.Name, ident.Name),
+	})
+}
+
+func (c *funcReleaseChecker) reportDoubleRelease(call *ast.CallExpr, report bool) {
+	if !report {
+		return
+	}
+	name := formatNode(c.pass.Fset, call.Fun.(*ast.SelectorExpr).X)
+	c.diagnostics = append(c.diagnostics, analysis.Diagnostic{
+		Pos:      call.Pos(),
+		Category: ruleFuncDoubleRelease,
+		Message:  fmt.Sprintf("Func %s is released twice: %s.Release() was already called", name, name),
+	})
+}
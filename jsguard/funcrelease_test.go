@@ -0,0 +1,170 @@
+//go:build !js
+
+package jsguard
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hack-pad/safejs/internal/assert"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestFuncRelease(t *testing.T) {
+	t.Parallel()
+	const (
+		fooName = "src/foo/foo.go"
+		fooFile = `
+//go:build js && wasm
+
+package foo
+
+import (
+	"syscall/js"
+
+	"github.com/hack-pad/safejs"
+)
+
+func handler(this js.Value, args []js.Value) any {
+	return nil
+}
+
+func UseAfterRelease(target js.Value) {
+	cb := js.FuncOf(handler)
+	cb.Release()
+	target.Set("onclick", cb)
+	target.Call("addEventListener", "click", cb)
+	cb.Value.Call("call")
+}
+
+func DoubleRelease() {
+	cb := js.FuncOf(handler)
+	cb.Release()
+	cb.Release()
+}
+
+func Reassigned(target js.Value) {
+	cb := js.FuncOf(handler)
+	cb.Release()
+	cb = js.FuncOf(handler)
+	target.Set("onclick", cb)
+	defer cb.Release()
+}
+
+func SomePaths(target js.Value, release bool) {
+	cb := js.FuncOf(handler)
+	if release {
+		cb.Release()
+	}
+	target.Set("onclick", cb)
+}
+
+func AllPaths(target js.Value, release bool) {
+	cb := js.FuncOf(handler)
+	if release {
+		cb.Release()
+	} else {
+		cb.Release()
+	}
+	target.Set("onclick", cb)
+}
+
+func Loop(target js.Value, values []js.Value) {
+	for _, value := range values {
+		cb := js.FuncOf(handler)
+		value.Set("onclick", cb)
+		cb.Release()
+	}
+	var cb js.Func
+	for range values {
+		cb.Release()
+	}
+}
+
+func Safe(target safejs.Value) error {
+	cb, err := safejs.FuncOf(func(this safejs.Value, args []safejs.Value) any {
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	cb.Release()
+	later := func() {
+		target.Set("onclick", cb.Value())
+	}
+	later()
+	defer cb.Release()
+	return nil
+}
+
+func Closure(target js.Value) {
+	cb := js.FuncOf(handler)
+	release := func() {
+		cb.Release()
+	}
+	release()
+	target.Set("onclick", cb)
+}
+
+func Escaped(target js.Value) {
+	cb := js.FuncOf(handler)
+	cb.Release()
+	reset(&cb)
+	target.Set("onclick", cb)
+}
+
+func reset(cb *js.Func) {
+	*cb = js.FuncOf(handler)
+}
+`
+	)
+	dir := makePackageDir(t, map[string]string{
+		safejsStubName: safejsStubFile,
+		fooName:        fooFile,
+	})
+
+	result := analysistest.Run(ignoreTestingErrorf{}, dir, FuncReleaseAnalyzer, "foo")
+	if !assert.Equal(t, 1, len(result)) {
+		t.FailNow()
+	}
+	result0 := result[0]
+	pass := result0.Pass
+	assertDiagnostics(t, []analysis.Diagnostic{
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, `cb)`+"\n\ttarget.Call")),
+			Category: "func-use-after-release",
+			Message:  "Func cb is used after cb.Release(): calling a released Func panics",
+		},
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, `cb)`+"\n\tcb.Value")),
+			Category: "func-use-after-release",
+			Message:  "Func cb is used after cb.Release(): calling a released Func panics",
+		},
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, `cb.Value.Call`)),
+			Category: "func-use-after-release",
+			Message:  "Func cb is used after cb.Release(): calling a released Func panics",
+		},
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, "cb.Release()\n}\n\nfunc Reassigned")),
+			Category: "func-double-release",
+			Message:  "Func cb is released twice: cb.Release() was already called",
+		},
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, "cb)\n}\n\nfunc Loop")),
+			Category: "func-use-after-release",
+			Message:  "Func cb is used after cb.Release(): calling a released Func panics",
+		},
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, "cb.Value())")),
+			Category: "func-use-after-release",
+			Message:  "Func cb is used after cb.Release(): calling a released Func panics",
+		},
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, "cb.Release()\n\treturn nil")),
+			Category: "func-double-release",
+			Message:  "Func cb is released twice: cb.Release() was already called",
+		},
+	}, result0.Diagnostics)
+}
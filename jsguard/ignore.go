@@ -0,0 +1,91 @@
+//go:build !js
+
+package jsguard
+
+import (
+	"go/ast"
+	"go/token"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// ignoreDirective suppresses diagnostics on its own line, or the next line if the directive is on a line by itself.
+// A reason is required, and directives without one are reported instead.
+//
+// For example:
+//
+//	//jsguard:ignore document is always defined in browsers
+//	document := js.Global().Get("document")
+const ignoreDirective = "//jsguard:ignore"
+
+// ignoredLine is a file name and line number
+type ignoredLine struct {
+	fileName string
+	line     int
+}
+
+// ignoreDirectives returns the lines suppressed by ignore directives, and the positions of directives missing a reason
+func ignoreDirectives(pass *analysis.Pass) (ignored map[ignoredLine]bool, missingReasons []token.Pos) {
+	ignored = make(map[ignoredLine]bool)
+	for _, file := range pass.Files {
+		for _, group := range file.Comments {
+			for _, comment := range group.List {
+				if comment.Text != ignoreDirective && !strings.HasPrefix(comment.Text, ignoreDirective+" ") {
+					continue
+				}
+				if strings.TrimSpace(strings.TrimPrefix(comment.Text, ignoreDirective)) == "" {
+					missingReasons = append(missingReasons, comment.Pos())
+					continue
+				}
+				position := pass.Fset.Position(comment.Pos())
+				line := position.Line
+				if onOwnLine(pass.Fset, file, comment) {
+					line++
+				}
+				ignored[ignoredLine{position.Filename, line}] = true
+			}
+		}
+	}
+	return ignored, missingReasons
+}
+
+// onOwnLine returns true if no code precedes comment on its line
+func onOwnLine(fset *token.FileSet, file *ast.File, comment *ast.Comment) bool {
+	line := fset.Position(comment.Pos()).Line
+	ownLine := true
+	ast.Inspect(file, func(node ast.Node) bool {
+		if node == nil || !ownLine || node.Pos() >= comment.Pos() {
+			return false
+		}
+		if _, isComment := node.(*ast.CommentGroup); !isComment && node != file && fset.Position(node.Pos()).Line == line {
+			ownLine = false
+		}
+		return true
+	})
+	return ownLine
+}
+
+// honorIgnoreDirectives drops diagnostics from pass on lines suppressed by ignore directives.
+// Returns the positions of directives missing a reason.
+func honorIgnoreDirectives(pass *analysis.Pass) []token.Pos {
+	ignored, missingReasons := ignoreDirectives(pass)
+	if len(ignored) == 0 {
+		return missingReasons
+	}
+	report := pass.Report
+	pass.Report = func(diagnostic analysis.Diagnostic) {
+		position := pass.Fset.Position(diagnostic.Pos)
+		if !ignored[ignoredLine{position.Filename, position.Line}] {
+			report(diagnostic)
+		}
+	}
+	return missingReasons
+}
+
+// reportMissingReasons reports ignore directives without a reason
+func reportMissingReasons(pass *analysis.Pass, missingReasons []token.Pos) {
+	for _, pos := range missingReasons {
+		reportf(pass, ruleIgnoreMissingReason, pos, "%s directive requires a reason, like '%s <reason>'", ignoreDirective, ignoreDirective)
+	}
+}
@@ -0,0 +1,65 @@
+//go:build !js
+
+package jsguard
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hack-pad/safejs/internal/assert"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestIgnoreDirective(t *testing.T) {
+	t.Parallel()
+	const (
+		fooName = "foo.go"
+		fooFile = `
+//go:build js && wasm
+
+package foo
+
+import (
+	"syscall/js"
+)
+
+func Foo(v js.Value) {
+	//jsguard:ignore v is always an object
+	v.Get("a")
+	v.Get("b") //jsguard:ignore checked by the caller
+	v.Get("c")
+	//jsguard:ignore
+	v.Get("d")
+}
+`
+	)
+	dir := makePackageDir(t, map[string]string{
+		fooName: fooFile,
+	})
+
+	result := analysistest.Run(ignoreTestingErrorf{}, dir, Analyzer)
+	if !assert.Equal(t, 1, len(result)) {
+		t.FailNow()
+	}
+	result0 := result[0]
+	assertDiagnostics(t, []analysis.Diagnostic{
+		{
+			Pos:      filePos(t, result0.Pass, fooName, strings.Index(fooFile, "//jsguard:ignore\n")),
+			Category: "ignore-missing-reason",
+			Message:  "//jsguard:ignore directive requires a reason, like '//jsguard:ignore <reason>'",
+		},
+		{
+			Pos:            filePos(t, result0.Pass, fooName, strings.Index(fooFile, `v.Get("c")`)),
+			Category:       "unsafe-method",
+			Message:        "unsafe method call on syscall/js.Value found: v.Get(...)",
+			SuggestedFixes: rewriteFix,
+		},
+		{
+			Pos:            filePos(t, result0.Pass, fooName, strings.Index(fooFile, `v.Get("d")`)),
+			Category:       "unsafe-method",
+			Message:        "unsafe method call on syscall/js.Value found: v.Get(...)",
+			SuggestedFixes: rewriteFix,
+		},
+	}, result0.Diagnostics)
+}
@@ -0,0 +1,211 @@
+//go:build !js
+
+package jsguard
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// IgnoredErrorAnalyzer reports errors returned by safejs functions and methods which are discarded or assigned to the blank identifier
+var IgnoredErrorAnalyzer = &analysis.Analyzer{
+	Name: "ignorederror",
+	Doc:  "report ignored errors from github.com/hack-pad/safejs calls",
+	Run:  runIgnoredError,
+}
+
+var ignoredErrorAllowlist string
+
+func init() {
+	IgnoredErrorAnalyzer.Flags.StringVar(&ignoredErrorAllowlist, "allow", "",
+		"comma-separated safejs functions and methods whose errors may be ignored, e.g. 'Value.Delete,CopyBytesToJS'")
+}
+
+func runIgnoredError(pass *analysis.Pass) (interface{}, error) {
+	finishBaseline, err := applyBaseline(pass)
+	if err != nil {
+		return nil, err
+	}
+	honorIgnoreDirectives(pass)
+	checker := &ignoredErrorChecker{
+		pass:      pass,
+		allowlist: parseSafejsAllowlist(ignoredErrorAllowlist),
+		aliases:   funcAliases(pass),
+	}
+	for _, file := range pass.Files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			checker.inspect(node)
+			return true
+		})
+	}
+	return nil, finishBaseline()
+}
+
+// parseSafejsAllowlist parses a comma-separated list of safejs function names, with or without the package path prefix
+func parseSafejsAllowlist(list string) map[string]bool {
+	allowlist := make(map[string]bool)
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		name = strings.TrimPrefix(name, safejsPackagePath+".")
+		name = strings.TrimPrefix(name, "safejs.")
+		if name != "" {
+			allowlist[name] = true
+		}
+	}
+	return allowlist
+}
+
+// funcAliases maps local variables to the function or method they hold, like f in 'f := v.Get'.
+// Variables assigned more than once, assigned anything else, or whose address is taken are left out.
+func funcAliases(pass *analysis.Pass) map[*types.Var]*types.Func {
+	assigned := make(map[*types.Var][]ast.Expr) // a nil expression is an unknown value
+	record := func(ident *ast.Ident, value ast.Expr) {
+		variable, ok := pass.TypesInfo.ObjectOf(ident).(*types.Var)
+		if ok && variable.Parent() != pass.Pkg.Scope() {
+			assigned[variable] = append(assigned[variable], value)
+		}
+	}
+	for _, file := range pass.Files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.AssignStmt:
+				for i, lhs := range node.Lhs {
+					if ident, ok := unparen(lhs).(*ast.Ident); ok {
+						var value ast.Expr
+						if len(node.Lhs) == len(node.Rhs) {
+							value = node.Rhs[i]
+						}
+						record(ident, value)
+					}
+				}
+			case *ast.ValueSpec:
+				for i, name := range node.Names {
+					var value ast.Expr
+					if len(node.Names) == len(node.Values) {
+						value = node.Values[i]
+					}
+					record(name, value)
+				}
+			case *ast.UnaryExpr:
+				if ident, ok := unparen(node.X).(*ast.Ident); ok && node.Op == token.AND {
+					record(ident, nil)
+				}
+			}
+			return true
+		})
+	}
+
+	aliases := make(map[*types.Var]*types.Func)
+	for variable, values := range assigned {
+		if len(values) != 1 || values[0] == nil {
+			continue
+		}
+		if fn := calledFunc(pass, values[0]); fn != nil {
+			aliases[variable] = fn
+		}
+	}
+	return aliases
+}
+
+// ignoredErrorChecker reports ignored errors from calls to safejs functions, methods, and their aliases
+type ignoredErrorChecker struct {
+	pass      *analysis.Pass
+	allowlist map[string]bool
+	aliases   map[*types.Var]*types.Func
+}
+
+func (c *ignoredErrorChecker) inspect(node ast.Node) {
+	switch node := node.(type) {
+	case *ast.ExprStmt:
+		c.reportUncheckedError(node.X)
+	case *ast.GoStmt:
+		c.reportUncheckedError(node.Call)
+	case *ast.DeferStmt:
+		c.reportUncheckedError(node.Call)
+	case *ast.AssignStmt:
+		if len(node.Rhs) != 1 {
+			for i, rhs := range node.Rhs {
+				if i < len(node.Lhs) && isBlank(node.Lhs[i]) {
+					c.reportBlankError(rhs, 0)
+				}
+			}
+			return
+		}
+		for i, lhs := range node.Lhs {
+			if isBlank(lhs) {
+				c.reportBlankError(node.Rhs[0], i)
+			}
+		}
+	case *ast.ValueSpec:
+		if len(node.Values) == 1 {
+			for i, name := range node.Names {
+				if name.Name == "_" {
+					c.reportBlankError(node.Values[0], i)
+				}
+			}
+		}
+	}
+}
+
+func isBlank(expr ast.Expr) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == "_"
+}
+
+// safejsErrorCall returns the safejs function called by expr and its name, relative to the safejs package, if it returns an error as its last result.
+// The function may be called directly, through a dot import, or through a local alias like 'f := v.Get'.
+func (c *ignoredErrorChecker) safejsErrorCall(expr ast.Expr) (*ast.CallExpr, *types.Signature, string, bool) {
+	call, ok := unparen(expr).(*ast.CallExpr)
+	if !ok {
+		return nil, nil, "", false
+	}
+	fn := calledFunc(c.pass, call.Fun)
+	if ident, isIdent := unparen(call.Fun).(*ast.Ident); fn == nil && isIdent {
+		if variable, isVar := c.pass.TypesInfo.Uses[ident].(*types.Var); isVar {
+			fn = c.aliases[variable]
+		}
+	}
+	if fn == nil || fn.Pkg() == nil || fn.Pkg().Path() != safejsPackagePath {
+		return nil, nil, "", false
+	}
+	signature := fn.Type().(*types.Signature)
+	results := signature.Results()
+	if results.Len() == 0 || !types.Identical(results.At(results.Len()-1).Type(), types.Universe.Lookup("error").Type()) {
+		return nil, nil, "", false
+	}
+	typeName, _ := funcTypeName(fn)
+	name := strings.TrimPrefix(strings.TrimPrefix(typeName, safejsPackagePath), ".")
+	if name == "" {
+		name = fn.Name()
+	} else {
+		name += "." + fn.Name()
+	}
+	return call, signature, name, true
+}
+
+func (c *ignoredErrorChecker) reportUncheckedError(expr ast.Expr) {
+	call, _, name, ok := c.safejsErrorCall(expr)
+	if !ok || c.allowlist[name] {
+		return
+	}
+	reportf(c.pass, ruleIgnoredError, call.Pos(), "unchecked error from safejs.%s call: %s", name, formatCall(c.pass.Fset, call))
+}
+
+// reportBlankError reports expr if it's a safejs call and resultIndex is its error result
+func (c *ignoredErrorChecker) reportBlankError(expr ast.Expr, resultIndex int) {
+	call, signature, name, ok := c.safejsErrorCall(expr)
+	if !ok || c.allowlist[name] || resultIndex != signature.Results().Len()-1 {
+		return
+	}
+	reportf(c.pass, ruleIgnoredError, call.Pos(), "error from safejs.%s call assigned to blank identifier: %s", name, formatCall(c.pass.Fset, call))
+}
+
+// formatCall formats call's function with the arguments elided, like "value.Get(...)"
+func formatCall(fset *token.FileSet, call *ast.CallExpr) string {
+	return fmt.Sprintf("%s(...)", formatNode(fset, call.Fun))
+}
@@ -0,0 +1,200 @@
+//go:build !js
+
+package jsguard
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hack-pad/safejs/internal/assert"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+// safejsStub is a minimal copy of safejs's API, used to build test packages in a temporary GOPATH
+const (
+	safejsStubName = "src/github.com/hack-pad/safejs/safejs.go"
+	safejsStubFile = `
+package safejs
+
+import "syscall/js"
+
+type Value struct{}
+
+type Func struct{}
+
+type Error struct{}
+
+func Global() Value                                                  { return Value{} }
+func Safe(js.Value) Value                                            { return Value{} }
+func Unsafe(Value) js.Value                                          { return js.Value{} }
+func MustGetGlobal(string) Value                                     { return Value{} }
+func ValueOf(any) (Value, error)                                     { return Value{}, nil }
+func CopyBytesToJS(Value, []byte) (int, error)                       { return 0, nil }
+func FuncOf(func(this Value, args []Value) any) (Func, error)        { return Func{}, nil }
+func (e Error) Error() string                                        { return "" }
+func (f Func) Release()                                              {}
+func (f Func) Value() Value                                          { return Value{} }
+func (v Value) Call(string, ...any) (Value, error)                   { return Value{}, nil }
+func (v Value) Delete(string) error                                  { return nil }
+func (v Value) Get(string) (Value, error)                            { return Value{}, nil }
+func (v Value) Invoke(...any) (Value, error)                         { return Value{}, nil }
+func (v Value) IsUndefined() bool                                    { return false }
+func (v Value) New(...any) (Value, error)                            { return Value{}, nil }
+func (v Value) Set(string, any) error                                { return nil }
+func (v Value) SetIndex(int, any) error                              { return nil }
+func (v Value) String() (string, error)                              { return "", nil }
+`
+)
+
+func TestIgnoredError(t *testing.T) {
+	t.Parallel()
+	const (
+		fooName = "src/foo/foo.go"
+		fooFile = `
+//go:build js && wasm
+
+package foo
+
+import (
+	"github.com/hack-pad/safejs"
+)
+
+func Foo(el safejs.Value) error {
+	v, _ := el.Get("x")
+	_ = v
+	el.Set("a", 1)
+	_ = el.Delete("a")
+	_, _ = el.String()
+	str, err := el.String()
+	_, _ = str, err
+	defer el.Delete("b")
+	go el.Call("c")
+	var _, _ = el.Get("y")
+	_, _ = safejs.CopyBytesToJS(el, nil)
+	el.IsUndefined()
+	safejs.Global()
+	if err := el.Set("b", 2); err != nil {
+		return err
+	}
+	return nil
+}
+`
+	)
+	dir := makePackageDir(t, map[string]string{
+		safejsStubName: safejsStubFile,
+		fooName:        fooFile,
+	})
+
+	result := analysistest.Run(ignoreTestingErrorf{}, dir, IgnoredErrorAnalyzer, "foo")
+	if !assert.Equal(t, 1, len(result)) {
+		t.FailNow()
+	}
+	result0 := result[0]
+	assertDiagnostics(t, []analysis.Diagnostic{
+		{
+			Pos:      filePos(t, result0.Pass, "foo", strings.Index(fooFile, `el.Get("x")`)),
+			Category: "ignored-error",
+			Message:  "error from safejs.Value.Get call assigned to blank identifier: el.Get(...)",
+		},
+		{
+			Pos:      filePos(t, result0.Pass, "foo", strings.Index(fooFile, `el.Set("a", 1)`)),
+			Category: "ignored-error",
+			Message:  "unchecked error from safejs.Value.Set call: el.Set(...)",
+		},
+		{
+			Pos:      filePos(t, result0.Pass, "foo", strings.Index(fooFile, `el.Delete("a")`)),
+			Category: "ignored-error",
+			Message:  "error from safejs.Value.Delete call assigned to blank identifier: el.Delete(...)",
+		},
+		{
+			Pos:      filePos(t, result0.Pass, "foo", strings.Index(fooFile, `el.String()`)),
+			Category: "ignored-error",
+			Message:  "error from safejs.Value.String call assigned to blank identifier: el.String(...)",
+		},
+		{
+			Pos:      filePos(t, result0.Pass, "foo", strings.Index(fooFile, `el.Delete("b")`)),
+			Category: "ignored-error",
+			Message:  "unchecked error from safejs.Value.Delete call: el.Delete(...)",
+		},
+		{
+			Pos:      filePos(t, result0.Pass, "foo", strings.Index(fooFile, `el.Call("c")`)),
+			Category: "ignored-error",
+			Message:  "unchecked error from safejs.Value.Call call: el.Call(...)",
+		},
+		{
+			Pos:      filePos(t, result0.Pass, "foo", strings.Index(fooFile, `el.Get("y")`)),
+			Category: "ignored-error",
+			Message:  "error from safejs.Value.Get call assigned to blank identifier: el.Get(...)",
+		},
+		{
+			Pos:      filePos(t, result0.Pass, "foo", strings.Index(fooFile, `safejs.CopyBytesToJS(`)),
+			Category: "ignored-error",
+			Message:  "error from safejs.CopyBytesToJS call assigned to blank identifier: safejs.CopyBytesToJS(...)",
+		},
+	}, result0.Diagnostics)
+}
+
+func TestIgnoredErrorResolvesCallee(t *testing.T) {
+	t.Parallel()
+	const (
+		fooName = "src/foo/foo.go"
+		fooFile = `
+//go:build js && wasm
+
+package foo
+
+import (
+	. "github.com/hack-pad/safejs"
+)
+
+func Foo(el Value) {
+	ValueOf(1)
+	get := el.Get
+	get("x")
+	_, _ = get("y")
+	set := el.Set
+	set = func(string, any) error { return nil }
+	set("z", 1)
+	var call func(string, ...any) (Value, error)
+	call("w")
+}
+`
+	)
+	dir := makePackageDir(t, map[string]string{
+		safejsStubName: safejsStubFile,
+		fooName:        fooFile,
+	})
+
+	result := analysistest.Run(ignoreTestingErrorf{}, dir, IgnoredErrorAnalyzer, "foo")
+	if !assert.Equal(t, 1, len(result)) {
+		t.FailNow()
+	}
+	result0 := result[0]
+	assertDiagnostics(t, []analysis.Diagnostic{
+		{
+			Pos:      filePos(t, result0.Pass, "foo", strings.Index(fooFile, `ValueOf(1)`)),
+			Category: "ignored-error",
+			Message:  "unchecked error from safejs.ValueOf call: ValueOf(...)",
+		},
+		{
+			Pos:      filePos(t, result0.Pass, "foo", strings.Index(fooFile, `get("x")`)),
+			Category: "ignored-error",
+			Message:  "unchecked error from safejs.Value.Get call: get(...)",
+		},
+		{
+			Pos:      filePos(t, result0.Pass, "foo", strings.Index(fooFile, `get("y")`)),
+			Category: "ignored-error",
+			Message:  "error from safejs.Value.Get call assigned to blank identifier: get(...)",
+		},
+	}, result0.Diagnostics)
+}
+
+func TestParseSafejsAllowlist(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, map[string]bool{
+		"Value.Delete":  true,
+		"CopyBytesToJS": true,
+		"Value.Set":     true,
+	}, parseSafejsAllowlist(" Value.Delete,github.com/hack-pad/safejs.CopyBytesToJS,, safejs.Value.Set"))
+}
@@ -0,0 +1,511 @@
+//go:build !js
+
+// Package report runs jsguard analyzers as a command, printing findings as text, JSON, or SARIF
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hack-pad/safejs/jsguard"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/multichecker"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+const (
+	formatText  = "text"
+	formatJSON  = "json"
+	formatSARIF = "sarif"
+)
+
+const (
+	targetJS     = "js"
+	targetNative = "native"
+	targetAll    = "all"
+)
+
+const (
+	formatUsage = "output format: text, json, or sarif"
+	targetUsage = "build configuration to analyze: js (GOOS=js GOARCH=wasm), native (the current GOOS and GOARCH), or all of them"
+)
+
+// options are the flags added by Main and MultiMain
+type options struct {
+	format string
+	target string
+	fix    bool
+	// baseline and writeBaseline are applied once to the merged findings of every target, instead of by each target's run
+	baseline      string
+	writeBaseline string
+}
+
+// Main runs analyzer as a command, like singlechecker.Main, with additional -format and -target flags.
+//
+// A single target with text output runs analyzer in this process.
+// Otherwise, the command runs again with -json for each target, then merges and converts their output.
+func Main(analyzer *analysis.Analyzer) {
+	run(analyzer.Name, []*analysis.Analyzer{analyzer}, func() {
+		singlechecker.Main(analyzer)
+	})
+}
+
+// MultiMain runs analyzers as a command, like multichecker.Main, with additional -format and -target flags.
+func MultiMain(name string, analyzers ...*analysis.Analyzer) {
+	run(name, analyzers, func() {
+		multichecker.Main(analyzers...)
+	})
+}
+
+// run parses the -format and -target flags, then runs checkerMain or runs the command again for each target
+func run(name string, analyzers []*analysis.Analyzer, checkerMain func()) {
+	opts, args, err := parseOptions(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+		os.Exit(1)
+	}
+	if opts.format == formatText && opts.target != targetAll {
+		for key, value := range targetEnv(opts.target) {
+			if err := os.Setenv(key, value); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+				os.Exit(1)
+			}
+		}
+		flag.String("format", formatText, formatUsage)
+		flag.String("target", opts.target, targetUsage)
+		jsguard.AddBaselineFlags(flag.CommandLine)
+		jsguard.AddConfigFlags(flag.CommandLine)
+		checkerMain()
+		return
+	}
+	os.Exit(runTargets(name, analyzers, opts, args))
+}
+
+// parseOptions removes the -format, -target, and baseline flags from args, returning their values and the remaining args.
+// Other flags' values can't be told apart from packages, so all args before "--" are checked.
+func parseOptions(args []string) (opts options, remaining []string, err error) {
+	opts = options{format: formatText, target: targetJS}
+	values := map[string]*string{
+		"format":         &opts.format,
+		"target":         &opts.target,
+		"baseline":       &opts.baseline,
+		"write-baseline": &opts.writeBaseline,
+	}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			remaining = append(remaining, args[i:]...)
+			break
+		}
+		remaining = append(remaining, arg)
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		name := strings.TrimLeft(arg, "-")
+		name, value, hasValue := strings.Cut(name, "=")
+		if name == "fix" {
+			opts.fix = !hasValue || value == "true"
+		}
+		option, ok := values[name]
+		if !ok {
+			continue
+		}
+		remaining = remaining[:len(remaining)-1]
+		if !hasValue {
+			if i+1 == len(args) {
+				return options{}, nil, fmt.Errorf("flag needs an argument: -%s", name)
+			}
+			i++
+			value = args[i]
+		}
+		*option = value
+	}
+
+	switch opts.format {
+	case formatText, formatJSON, formatSARIF:
+	default:
+		return options{}, nil, fmt.Errorf("invalid -format %q: must be %s, %s, or %s", opts.format, formatText, formatJSON, formatSARIF)
+	}
+	switch opts.target {
+	case targetJS, targetNative:
+	case targetAll:
+		if opts.fix {
+			return options{}, nil, fmt.Errorf("-fix requires a single -target, like -target=%s", targetJS)
+		}
+	default:
+		return options{}, nil, fmt.Errorf("invalid -target %q: must be %s, %s, or %s", opts.target, targetJS, targetNative, targetAll)
+	}
+	return opts, remaining, nil
+}
+
+// targets returns the single targets included in target
+func targets(target string) []string {
+	if target == targetAll {
+		return []string{targetJS, targetNative}
+	}
+	return []string{target}
+}
+
+// targetEnv returns the environment variables to load packages for target
+func targetEnv(target string) map[string]string {
+	if target == targetJS {
+		return map[string]string{"GOOS": "js", "GOARCH": "wasm"}
+	}
+	env := map[string]string{"GOOS": runtime.GOOS, "GOARCH": runtime.GOARCH}
+	for key := range env {
+		if value := os.Getenv(key); value != "" && value != "js" && value != "wasm" {
+			env[key] = value
+		}
+	}
+	return env
+}
+
+// targetRun is the output of running this command with -json for one target
+type targetRun struct {
+	target string
+	tree   jsonTree
+	// exitCode is non-zero if the command failed, like when packages don't build for the target
+	exitCode int
+}
+
+// runTargets runs this command again with -json for each target, then prints their merged findings.
+// Returns the exit code.
+func runTargets(name string, analyzers []*analysis.Analyzer, opts options, args []string) int {
+	executable, err := os.Executable()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+		return 1
+	}
+	var runs []targetRun
+	for _, target := range targets(opts.target) {
+		var stdout bytes.Buffer
+		cmd := exec.Command(executable, append([]string{"-json", "-target=" + target}, args...)...)
+		cmd.Stdout = &stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			var exitErr *exec.ExitError
+			if !errors.As(err, &exitErr) {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+				return 1
+			}
+			runs = append(runs, targetRun{target: target, exitCode: exitErr.ExitCode()})
+			continue
+		}
+
+		var tree jsonTree
+		if err := json.Unmarshal(stdout.Bytes(), &tree); err != nil {
+			// not analysis results, like -help or -V output
+			_, _ = os.Stdout.Write(stdout.Bytes())
+			return 0
+		}
+		runs = append(runs, targetRun{target: target, tree: tree})
+	}
+	return printTargets(os.Stdout, os.Stderr, name, opts, analyzerRules(analyzers), runs)
+}
+
+// printTargets prints the merged findings of runs in opts' format, after applying opts' baseline. Returns the exit code.
+//
+// Failed targets are skipped with a note, since other targets may still succeed, like when packages only build for one target.
+// If every target failed, returns the last failure's exit code.
+func printTargets(stdout, stderr io.Writer, name string, opts options, rules []jsguard.Rule, runs []targetRun) int {
+	var trees []jsonTree
+	failedCode := 0
+	for _, run := range runs {
+		if run.exitCode != 0 {
+			failedCode = run.exitCode
+			continue
+		}
+		trees = append(trees, run.tree)
+	}
+	if len(trees) == 0 {
+		return failedCode
+	}
+	for _, run := range runs {
+		if run.exitCode != 0 {
+			fmt.Fprintf(stderr, "%s: skipped -target=%s, which failed with exit code %d: packages may not build for it\n", name, run.target, run.exitCode)
+		}
+	}
+
+	findings, errs := mergeFindings(trees)
+	for _, err := range errs {
+		fmt.Fprintf(stderr, "%s: %v\n", name, err)
+	}
+	findings, err := applyBaseline(opts, findings)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", name, err)
+		return 1
+	}
+	if opts.format == formatText {
+		// match singlechecker's text output and exit codes
+		writeText(stderr, findings)
+		switch {
+		case len(errs) > 0:
+			return 1
+		case len(findings) > 0:
+			return 3
+		default:
+			return 0
+		}
+	}
+	if err := write(stdout, opts.format, rules, findings); err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", name, err)
+		return 1
+	}
+	if len(errs) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// applyBaseline filters findings with the -baseline file, or writes them to the -write-baseline file instead of returning them
+func applyBaseline(opts options, findings []finding) ([]finding, error) {
+	if opts.baseline == "" && opts.writeBaseline == "" {
+		return findings, nil
+	}
+	baselineFindings := make([]jsguard.BaselineFinding, len(findings))
+	for i, finding := range findings {
+		baselineFindings[i] = jsguard.BaselineFinding{
+			Analyzer: finding.Analyzer,
+			Package:  packagePath(finding.Package),
+			Message:  finding.Message,
+			Filename: finding.File,
+			Line:     finding.Line,
+			Column:   finding.Column,
+		}
+	}
+	reported, err := jsguard.ApplyBaseline(opts.baseline, opts.writeBaseline, baselineFindings)
+	if err != nil {
+		return nil, err
+	}
+	var remaining []finding
+	for i, finding := range findings {
+		if reported[i] {
+			remaining = append(remaining, finding)
+		}
+	}
+	return remaining, nil
+}
+
+// packagePath returns the path of the package with the given ID, like "foo" for the test variant "foo [foo.test]"
+func packagePath(id string) string {
+	path, _, _ := strings.Cut(id, " ")
+	return path
+}
+
+// analyzerRules returns the rules reported by analyzers and the analyzers they require
+func analyzerRules(analyzers []*analysis.Analyzer) []jsguard.Rule {
+	names := make(map[string]bool)
+	var addNames func(*analysis.Analyzer)
+	addNames = func(analyzer *analysis.Analyzer) {
+		names[analyzer.Name] = true
+		for _, required := range analyzer.Requires {
+			addNames(required)
+		}
+	}
+	for _, analyzer := range analyzers {
+		addNames(analyzer)
+	}
+
+	var rules []jsguard.Rule
+	for _, rule := range jsguard.Rules() {
+		if names[rule.Analyzer] {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+func write(w io.Writer, format string, rules []jsguard.Rule, findings []finding) error {
+	var output interface{}
+	switch format {
+	case formatJSON:
+		output = newJSONReport(rules, findings)
+	case formatSARIF:
+		wd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		output = newSARIFLog(rules, findings, wd)
+	default:
+		return fmt.Errorf("unsupported format: %s", format)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(output)
+}
+
+// jsonTree is the output of the -json flag: package ID -> analyzer name -> diagnostics or an error
+type jsonTree map[string]map[string]json.RawMessage
+
+type jsonDiagnostic struct {
+	Category string `json:"category"`
+	Posn     string `json:"posn"`
+	Message  string `json:"message"`
+}
+
+type jsonError struct {
+	Err string `json:"error"`
+}
+
+// finding is a diagnostic reported by an analyzer
+type finding struct {
+	RuleID   string `json:"ruleId"`
+	Analyzer string `json:"analyzer"`
+	Package  string `json:"package"`
+	Message  string `json:"message"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+}
+
+// mergeFindings returns the findings in trees sorted by position, and any analysis errors.
+// Findings and errors reported for more than one target are only included once.
+func mergeFindings(trees []jsonTree) ([]finding, []error) {
+	var findings []finding
+	var errs []error
+	seenFindings := make(map[finding]bool)
+	seenErrs := make(map[string]bool)
+	for _, tree := range trees {
+		treeFindings, treeErrs := tree.findings()
+		for _, finding := range treeFindings {
+			if !seenFindings[finding] {
+				seenFindings[finding] = true
+				findings = append(findings, finding)
+			}
+		}
+		for _, err := range treeErrs {
+			if !seenErrs[err.Error()] {
+				seenErrs[err.Error()] = true
+				errs = append(errs, err)
+			}
+		}
+	}
+	sortFindings(findings)
+	sort.Slice(errs, func(a, b int) bool {
+		return errs[a].Error() < errs[b].Error()
+	})
+	return findings, errs
+}
+
+// findings returns tree's findings, and any analysis errors
+func (tree jsonTree) findings() ([]finding, []error) {
+	var findings []finding
+	var errs []error
+	for pkg, analyzers := range tree {
+		for analyzer, result := range analyzers {
+			var resultErr jsonError
+			if json.Unmarshal(result, &resultErr) == nil && resultErr.Err != "" {
+				errs = append(errs, fmt.Errorf("%s: %s: %s", pkg, analyzer, resultErr.Err))
+				continue
+			}
+			var diagnostics []jsonDiagnostic
+			if err := json.Unmarshal(result, &diagnostics); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %s: invalid diagnostics: %w", pkg, analyzer, err))
+				continue
+			}
+			for _, diagnostic := range diagnostics {
+				file, line, column := parsePosition(diagnostic.Posn)
+				findings = append(findings, finding{
+					RuleID:   diagnostic.Category,
+					Analyzer: analyzer,
+					Package:  pkg,
+					Message:  diagnostic.Message,
+					File:     file,
+					Line:     line,
+					Column:   column,
+				})
+			}
+		}
+	}
+	return findings, errs
+}
+
+func sortFindings(findings []finding) {
+	sort.Slice(findings, func(a, b int) bool {
+		findingA, findingB := findings[a], findings[b]
+		switch {
+		case findingA.File != findingB.File:
+			return findingA.File < findingB.File
+		case findingA.Line != findingB.Line:
+			return findingA.Line < findingB.Line
+		case findingA.Column != findingB.Column:
+			return findingA.Column < findingB.Column
+		default:
+			return findingA.Message < findingB.Message
+		}
+	})
+}
+
+// writeText writes findings like singlechecker's text output
+func writeText(w io.Writer, findings []finding) {
+	for _, finding := range findings {
+		fmt.Fprintf(w, "%s:%d:%d: %s\n", finding.File, finding.Line, finding.Column, finding.Message)
+	}
+}
+
+// parsePosition splits a token.Position string like "file.go:1:2". Line and column are 0 if missing.
+func parsePosition(posn string) (file string, line, column int) {
+	file = posn
+	var numbers []int
+	for i := 0; i < 2; i++ {
+		colon := strings.LastIndexByte(file, ':')
+		if colon == -1 {
+			break
+		}
+		n, err := strconv.Atoi(file[colon+1:])
+		if err != nil {
+			break
+		}
+		numbers = append([]int{n}, numbers...)
+		file = file[:colon]
+	}
+	switch len(numbers) {
+	case 2:
+		return file, numbers[0], numbers[1]
+	case 1:
+		return file, numbers[0], 0
+	default:
+		return file, 0, 0
+	}
+}
+
+// jsonReport is the output of -format=json
+type jsonReport struct {
+	Rules    []jsonRule `json:"rules"`
+	Findings []finding  `json:"findings"`
+}
+
+type jsonRule struct {
+	ID          string `json:"id"`
+	Analyzer    string `json:"analyzer"`
+	Description string `json:"description"`
+	HelpURL     string `json:"helpUri"`
+}
+
+func newJSONReport(rules []jsguard.Rule, findings []finding) jsonReport {
+	report := jsonReport{
+		Rules:    []jsonRule{},
+		Findings: []finding{},
+	}
+	for _, rule := range rules {
+		report.Rules = append(report.Rules, jsonRule{
+			ID:          rule.ID,
+			Analyzer:    rule.Analyzer,
+			Description: rule.Description,
+			HelpURL:     rule.HelpURL,
+		})
+	}
+	report.Findings = append(report.Findings, findings...)
+	return report
+}
@@ -0,0 +1,314 @@
+//go:build !js
+
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hack-pad/safejs/internal/assert"
+	"github.com/hack-pad/safejs/jsguard"
+	"golang.org/x/tools/go/analysis"
+)
+
+func TestParseOptions(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		args       []string
+		expectOpts options
+		expectArgs []string
+		expectErr  string
+	}{
+		{
+			args:       []string{"./..."},
+			expectOpts: options{format: "text", target: "js"},
+			expectArgs: []string{"./..."},
+		},
+		{
+			args:       []string{"-format=sarif", "-fix", "./..."},
+			expectOpts: options{format: "sarif", target: "js", fix: true},
+			expectArgs: []string{"-fix", "./..."},
+		},
+		{
+			args:       []string{"-c", "1", "--format", "json", "-target", "all", "./..."},
+			expectOpts: options{format: "json", target: "all"},
+			expectArgs: []string{"-c", "1", "./..."},
+		},
+		{
+			args:       []string{"./...", "-target=native", "--", "-format=sarif"},
+			expectOpts: options{format: "text", target: "native"},
+			expectArgs: []string{"./...", "--", "-format=sarif"},
+		},
+		{
+			args:       []string{"-fix=false", "-target=all", "./..."},
+			expectOpts: options{format: "text", target: "all"},
+			expectArgs: []string{"-fix=false", "./..."},
+		},
+		{
+			args:       []string{"-target=all", "-baseline", "old.json", "-write-baseline=new.json", "./..."},
+			expectOpts: options{format: "text", target: "all", baseline: "old.json", writeBaseline: "new.json"},
+			expectArgs: []string{"./..."},
+		},
+		{
+			args:      []string{"-format"},
+			expectErr: "flag needs an argument: -format",
+		},
+		{
+			args:      []string{"-format=xml", "./..."},
+			expectErr: `invalid -format "xml": must be text, json, or sarif`,
+		},
+		{
+			args:      []string{"-target=windows", "./..."},
+			expectErr: `invalid -target "windows": must be js, native, or all`,
+		},
+		{
+			args:      []string{"-fix", "-target=all", "./..."},
+			expectErr: "-fix requires a single -target, like -target=js",
+		},
+	} {
+		tc := tc // enable parallel sub-tests
+		t.Run(fmt.Sprint(tc.args), func(t *testing.T) {
+			t.Parallel()
+			opts, args, err := parseOptions(tc.args)
+			if tc.expectErr != "" {
+				assert.EqualError(t, err, tc.expectErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectOpts, opts)
+			assert.Equal(t, tc.expectArgs, args)
+		})
+	}
+}
+
+func TestMergeFindings(t *testing.T) {
+	t.Parallel()
+	var jsTree, nativeTree jsonTree
+	err := json.Unmarshal([]byte(`{
+	"foo": {
+		"jsguard": [
+			{"category": "unsafe-call", "posn": "/src/foo/foo.go:10:2", "message": "unsafe call to syscall/js found: js.Global(...)"},
+			{"category": "unsafe-method", "posn": "/src/foo/foo.go:4:3", "message": "unsafe method call on js.Value found: v.Get(...)"}
+		]
+	},
+	"bar": {
+		"jsguard": {"error": "failed"}
+	}
+}`), &jsTree)
+	assert.NoError(t, err)
+	err = json.Unmarshal([]byte(`{
+	"foo": {
+		"jsguard": [
+			{"category": "ignore-missing-reason", "posn": "/src/foo/foo_other.go:1:1", "message": "//jsguard:ignore directive requires a reason"},
+			{"category": "unsafe-method", "posn": "/src/foo/foo.go:4:3", "message": "unsafe method call on js.Value found: v.Get(...)"}
+		]
+	},
+	"bar": {
+		"jsguard": {"error": "failed"}
+	}
+}`), &nativeTree)
+	assert.NoError(t, err)
+
+	findings, errs := mergeFindings([]jsonTree{jsTree, nativeTree})
+	assert.Equal(t, []error{errors.New("bar: jsguard: failed")}, errs)
+	assert.Equal(t, []finding{
+		{RuleID: "unsafe-method", Analyzer: "jsguard", Package: "foo", Message: "unsafe method call on js.Value found: v.Get(...)", File: "/src/foo/foo.go", Line: 4, Column: 3},
+		{RuleID: "unsafe-call", Analyzer: "jsguard", Package: "foo", Message: "unsafe call to syscall/js found: js.Global(...)", File: "/src/foo/foo.go", Line: 10, Column: 2},
+		{RuleID: "ignore-missing-reason", Analyzer: "jsguard", Package: "foo", Message: "//jsguard:ignore directive requires a reason", File: "/src/foo/foo_other.go", Line: 1, Column: 1},
+	}, findings)
+
+	var buf bytes.Buffer
+	writeText(&buf, findings[:1])
+	assert.Equal(t, "/src/foo/foo.go:4:3: unsafe method call on js.Value found: v.Get(...)\n", buf.String())
+}
+
+func TestPrintTargets(t *testing.T) {
+	t.Parallel()
+	var jsTree jsonTree
+	err := json.Unmarshal([]byte(`{
+	"foo": {
+		"jsguard": [
+			{"category": "unsafe-call", "posn": "/src/foo/foo.go:10:2", "message": "unsafe call to syscall/js found: js.Global(...)"}
+		]
+	}
+}`), &jsTree)
+	assert.NoError(t, err)
+	jsRun := targetRun{target: targetJS, tree: jsTree}
+	failedNativeRun := targetRun{target: targetNative, exitCode: 1}
+
+	t.Run("failed target does not mask findings", func(t *testing.T) {
+		t.Parallel()
+		var stdout, stderr bytes.Buffer
+		exitCode := printTargets(&stdout, &stderr, "jsguard", options{format: formatText}, nil, []targetRun{jsRun, failedNativeRun})
+		assert.Equal(t, 3, exitCode)
+		assert.Equal(t, "", stdout.String())
+		assert.Equal(t, "jsguard: skipped -target=native, which failed with exit code 1: packages may not build for it\n"+
+			"/src/foo/foo.go:10:2: unsafe call to syscall/js found: js.Global(...)\n", stderr.String())
+	})
+
+	t.Run("failed target with json", func(t *testing.T) {
+		t.Parallel()
+		var stdout, stderr bytes.Buffer
+		exitCode := printTargets(&stdout, &stderr, "jsguard", options{format: formatJSON}, nil, []targetRun{failedNativeRun, jsRun})
+		assert.Equal(t, 0, exitCode)
+		assert.Equal(t, true, bytes.Contains(stdout.Bytes(), []byte(`"ruleId": "unsafe-call"`)))
+		assert.Equal(t, "jsguard: skipped -target=native, which failed with exit code 1: packages may not build for it\n", stderr.String())
+	})
+
+	t.Run("baseline", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		fooFile := filepath.Join(dir, "foo.go")
+		assert.NoError(t, os.WriteFile(fooFile, []byte(`package foo
+
+import "syscall/js"
+
+type Value struct{}
+
+func (v *Value) Get() {
+	js.Global()
+}
+`), 0600))
+		tree := func(target string) targetRun {
+			var tree jsonTree
+			err := json.Unmarshal([]byte(fmt.Sprintf(`{
+	"foo [foo.test]": {
+		"jsguard": [
+			{"category": "unsafe-call", "posn": %q, "message": "unsafe call to syscall/js found: js.Global(...)"}
+		]
+	}
+}`, fooFile+":8:2")), &tree)
+			assert.NoError(t, err)
+			return targetRun{target: target, tree: tree}
+		}
+		runs := []targetRun{tree(targetJS), tree(targetNative)}
+
+		baselineFile := filepath.Join(dir, "baseline.json")
+		var stdout, stderr bytes.Buffer
+		exitCode := printTargets(&stdout, &stderr, "jsguard", options{format: formatText, writeBaseline: baselineFile}, nil, runs)
+		assert.Equal(t, 0, exitCode)
+		assert.Equal(t, "", stderr.String())
+		contents, err := os.ReadFile(baselineFile)
+		assert.NoError(t, err)
+		assert.Equal(t, `{
+  "version": 1,
+  "entries": [
+    {
+      "analyzer": "jsguard",
+      "package": "foo",
+      "function": "Value.Get",
+      "message": "unsafe call to syscall/js found: js.Global(...)",
+      "snippet": "js.Global()",
+      "count": 1
+    }
+  ]
+}
+`, string(contents))
+
+		stdout.Reset()
+		exitCode = printTargets(&stdout, &stderr, "jsguard", options{format: formatJSON, baseline: baselineFile}, nil, runs)
+		assert.Equal(t, 0, exitCode)
+		assert.Equal(t, "", stderr.String())
+		assert.Equal(t, false, bytes.Contains(stdout.Bytes(), []byte(`"ruleId": "unsafe-call"`)))
+	})
+
+	t.Run("all targets failed", func(t *testing.T) {
+		t.Parallel()
+		var stdout, stderr bytes.Buffer
+		exitCode := printTargets(&stdout, &stderr, "jsguard", options{format: formatText}, nil, []targetRun{{target: targetJS, exitCode: 1}, failedNativeRun})
+		assert.Equal(t, 1, exitCode)
+		assert.Equal(t, "", stdout.String())
+		assert.Equal(t, "", stderr.String())
+	})
+}
+
+func TestParsePosition(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		posn         string
+		expectFile   string
+		expectLine   int
+		expectColumn int
+	}{
+		{posn: "foo.go:1:2", expectFile: "foo.go", expectLine: 1, expectColumn: 2},
+		{posn: `C:\foo.go:1:2`, expectFile: `C:\foo.go`, expectLine: 1, expectColumn: 2},
+		{posn: "foo.go:1", expectFile: "foo.go", expectLine: 1},
+		{posn: "foo.go", expectFile: "foo.go"},
+		{posn: "-", expectFile: "-"},
+	} {
+		tc := tc // enable parallel sub-tests
+		t.Run(tc.posn, func(t *testing.T) {
+			t.Parallel()
+			file, line, column := parsePosition(tc.posn)
+			assert.Equal(t, tc.expectFile, file)
+			assert.Equal(t, tc.expectLine, line)
+			assert.Equal(t, tc.expectColumn, column)
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+	root := filepath.FromSlash("/src")
+	rules := []jsguard.Rule{
+		{ID: "unsafe-call", Analyzer: "jsguard", Description: "Call to a syscall/js function which may panic.", HelpURL: "https://pkg.go.dev/github.com/hack-pad/safejs"},
+	}
+	findings := []finding{
+		{RuleID: "unsafe-call", Analyzer: "jsguard", Package: "foo", Message: "unsafe call to syscall/js found: js.Global(...)", File: filepath.Join(root, "foo", "foo.go"), Line: 10, Column: 2},
+		{RuleID: "other", Analyzer: "other", Package: "bar", Message: "other finding", File: filepath.FromSlash("/bar/bar.go")},
+	}
+
+	t.Run("json", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		assert.NoError(t, json.NewEncoder(&buf).Encode(newJSONReport(rules, findings[:1])))
+		assert.Equal(t, `{"rules":[{"id":"unsafe-call","analyzer":"jsguard","description":"Call to a syscall/js function which may panic.","helpUri":"https://pkg.go.dev/github.com/hack-pad/safejs"}],"findings":[{"ruleId":"unsafe-call","analyzer":"jsguard","package":"foo","message":"unsafe call to syscall/js found: js.Global(...)","file":"`+jsonString(findings[0].File)+`","line":10,"column":2}]}`+"\n", buf.String())
+	})
+
+	t.Run("json empty", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		assert.NoError(t, json.NewEncoder(&buf).Encode(newJSONReport(nil, nil)))
+		assert.Equal(t, `{"rules":[],"findings":[]}`+"\n", buf.String())
+	})
+
+	t.Run("sarif", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		assert.NoError(t, json.NewEncoder(&buf).Encode(newSARIFLog(rules, findings, root)))
+		assert.Equal(t, `{"$schema":"https://json.schemastore.org/sarif-2.1.0.json","version":"2.1.0","runs":[{"tool":{"driver":{"name":"jsguard","informationUri":"https://pkg.go.dev/github.com/hack-pad/safejs/jsguard","rules":[{"id":"unsafe-call","shortDescription":{"text":"Call to a syscall/js function which may panic."},"helpUri":"https://pkg.go.dev/github.com/hack-pad/safejs"}]}},"results":[`+
+			`{"ruleId":"unsafe-call","ruleIndex":0,"level":"warning","message":{"text":"unsafe call to syscall/js found: js.Global(...)"},"locations":[{"physicalLocation":{"artifactLocation":{"uri":"foo/foo.go","uriBaseId":"%SRCROOT%"},"region":{"startLine":10,"startColumn":2}}}]},`+
+			`{"ruleId":"other","level":"warning","message":{"text":"other finding"},"locations":[{"physicalLocation":{"artifactLocation":{"uri":"file://`+jsonString(filepath.ToSlash(findings[1].File))+`"}}}]}`+
+			`]}]}`+"\n", buf.String())
+	})
+}
+
+func TestAnalyzerRules(t *testing.T) {
+	t.Parallel()
+	var ruleIDs []string
+	for _, rule := range analyzerRules([]*analysis.Analyzer{jsguard.Analyzer}) {
+		ruleIDs = append(ruleIDs, rule.ID)
+	}
+	assert.Equal(t, []string{"unsafe-call", "unsafe-method", "unsafe-func-value", "unsafe-method-value", "ignore-missing-reason"}, ruleIDs)
+	assert.Equal(t, jsguard.Rules(), analyzerRules(jsguard.Analyzers()))
+}
+
+func jsonString(s string) string {
+	b, err := json.Marshal(s)
+	if err != nil {
+		panic(err)
+	}
+	return string(b[1 : len(b)-1])
+}
+
+func TestTargetEnv(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, []string{"js", "native"}, targets("all"))
+	assert.Equal(t, []string{"native"}, targets("native"))
+	assert.Equal(t, map[string]string{"GOOS": "js", "GOARCH": "wasm"}, targetEnv("js"))
+}
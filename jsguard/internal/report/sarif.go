@@ -0,0 +1,136 @@
+//go:build !js
+
+package report
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/hack-pad/safejs/jsguard"
+)
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+	// sarifSrcRoot is the base of relative artifact URIs, resolved by SARIF consumers to the source root
+	sarifSrcRoot = "%SRCROOT%"
+	toolInfoURI  = "https://pkg.go.dev/github.com/hack-pad/safejs/jsguard"
+)
+
+// sarifLog is the output of -format=sarif, a SARIF 2.1.0 log
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+	HelpURI          string       `json:"helpUri"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex *int            `json:"ruleIndex,omitempty"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// newSARIFLog returns a log of findings. Files inside srcRoot are relative to the %SRCROOT% base URI.
+func newSARIFLog(rules []jsguard.Rule, findings []finding, srcRoot string) sarifLog {
+	driver := sarifDriver{
+		Name:           "jsguard",
+		InformationURI: toolInfoURI,
+		Rules:          []sarifRule{},
+	}
+	ruleIndexes := make(map[string]int)
+	for _, rule := range rules {
+		ruleIndexes[rule.ID] = len(driver.Rules)
+		driver.Rules = append(driver.Rules, sarifRule{
+			ID:               rule.ID,
+			ShortDescription: sarifMessage{Text: rule.Description},
+			HelpURI:          rule.HelpURL,
+		})
+	}
+
+	results := []sarifResult{}
+	for _, finding := range findings {
+		result := sarifResult{
+			RuleID:  finding.RuleID,
+			Level:   "warning",
+			Message: sarifMessage{Text: finding.Message},
+			Locations: []sarifLocation{
+				{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifact(finding.File, srcRoot)}},
+			},
+		}
+		if index, ok := ruleIndexes[finding.RuleID]; ok {
+			result.RuleIndex = &index
+		}
+		if finding.Line > 0 {
+			result.Locations[0].PhysicalLocation.Region = &sarifRegion{
+				StartLine:   finding.Line,
+				StartColumn: finding.Column,
+			}
+		}
+		results = append(results, result)
+	}
+	return sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{
+			{Tool: sarifTool{Driver: driver}, Results: results},
+		},
+	}
+}
+
+// sarifArtifact returns file's location, relative to srcRoot if possible
+func sarifArtifact(file, srcRoot string) sarifArtifactLocation {
+	relative, err := filepath.Rel(srcRoot, file)
+	if err != nil || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+		return sarifArtifactLocation{URI: "file://" + filepath.ToSlash(file)}
+	}
+	return sarifArtifactLocation{
+		URI:       filepath.ToSlash(relative),
+		URIBaseID: sarifSrcRoot,
+	}
+}
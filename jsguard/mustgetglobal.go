@@ -0,0 +1,72 @@
+//go:build !js
+
+package jsguard
+
+import (
+	"go/ast"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// MustGetGlobalAnalyzer reports safejs.MustGetGlobal calls outside package-level var initializers and init functions.
+//
+// MustGetGlobal panics when the global is missing, which is only intended to fail fast while the program starts.
+var MustGetGlobalAnalyzer = &analysis.Analyzer{
+	Name: "mustgetglobal",
+	Doc:  "report safejs.MustGetGlobal calls outside package-level var initializers and init functions",
+	Run:  runMustGetGlobal,
+}
+
+const mustGetGlobalAdvice = "call it in a package-level var initializer or init(), or handle the error from safejs.Global().Get(...) instead"
+
+func runMustGetGlobal(pass *analysis.Pass) (interface{}, error) {
+	finishBaseline, err := applyBaseline(pass)
+	if err != nil {
+		return nil, err
+	}
+	honorIgnoreDirectives(pass)
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				inspectMustGetGlobal(pass, decl, true)
+			case *ast.FuncDecl:
+				isInit := decl.Recv == nil && decl.Name.Name == "init"
+				inspectMustGetGlobal(pass, decl, isInit)
+			}
+		}
+	}
+	return nil, finishBaseline()
+}
+
+// inspectMustGetGlobal reports MustGetGlobal calls in node, unless they run during package initialization.
+// Function literals are assumed to run later unless they're called immediately.
+func inspectMustGetGlobal(pass *analysis.Pass, node ast.Node, initializing bool) {
+	ast.Inspect(node, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.GoStmt:
+			inspectMustGetGlobal(pass, node.Call, false)
+			return false
+		case *ast.FuncLit:
+			inspectMustGetGlobal(pass, node.Body, false)
+			return false
+		case *ast.CallExpr:
+			if funcLit, ok := unparen(node.Fun).(*ast.FuncLit); ok {
+				inspectMustGetGlobal(pass, funcLit.Body, initializing)
+				for _, arg := range node.Args {
+					inspectMustGetGlobal(pass, arg, initializing)
+				}
+				return false
+			}
+			if !initializing && isMustGetGlobalCall(pass, node) {
+				reportf(pass, ruleMustGetGlobalOutsideInit, node.Pos(), "%s panics at runtime if the global is missing: %s", formatCall(pass.Fset, node), mustGetGlobalAdvice)
+			}
+		}
+		return true
+	})
+}
+
+func isMustGetGlobalCall(pass *analysis.Pass, call *ast.CallExpr) bool {
+	fn := calledFunc(pass, call.Fun)
+	return fn != nil && fn.Pkg() != nil && fn.Pkg().Path() == safejsPackagePath && fn.Name() == "MustGetGlobal"
+}
@@ -0,0 +1,154 @@
+//go:build !js
+
+package jsguard
+
+import (
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/hack-pad/safejs/internal/assert"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestMustGetGlobal(t *testing.T) {
+	t.Parallel()
+	const (
+		fooName = "src/foo/foo.go"
+		fooFile = `
+//go:build js && wasm
+
+package foo
+
+import (
+	"github.com/hack-pad/safejs"
+)
+
+var (
+	jsUint8Array = safejs.MustGetGlobal("Uint8Array")
+	jsClasses    = func() []safejs.Value {
+		return []safejs.Value{safejs.MustGetGlobal("Map")}
+	}()
+	getDocument = func() safejs.Value {
+		return safejs.MustGetGlobal("document")
+	}
+)
+
+var jsSet safejs.Value
+
+func init() {
+	jsSet = safejs.MustGetGlobal("Set")
+	go func() {
+		safejs.MustGetGlobal("fetch")
+	}()
+}
+
+type app struct{}
+
+func (app) init() {
+	safejs.MustGetGlobal("WebSocket")
+}
+
+func Handle() error {
+	window := safejs.MustGetGlobal("window")
+	_ = window
+	document, err := safejs.Global().Get("document")
+	_ = document
+	return err
+}
+`
+	)
+	dir := makePackageDir(t, map[string]string{
+		safejsStubName: safejsStubFile,
+		fooName:        fooFile,
+	})
+
+	result := analysistest.Run(ignoreTestingErrorf{}, dir, MustGetGlobalAnalyzer, "foo")
+	if !assert.Equal(t, 1, len(result)) {
+		t.FailNow()
+	}
+	result0 := result[0]
+	pass := result0.Pass
+	const message = "safejs.MustGetGlobal(...) panics at runtime if the global is missing: call it in a package-level var initializer or init(), or handle the error from safejs.Global().Get(...) instead"
+	assertDiagnostics(t, []analysis.Diagnostic{
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, `safejs.MustGetGlobal("document")`)),
+			Category: "must-get-global-outside-init",
+			Message:  message,
+		},
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, `safejs.MustGetGlobal("fetch")`)),
+			Category: "must-get-global-outside-init",
+			Message:  message,
+		},
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, `safejs.MustGetGlobal("WebSocket")`)),
+			Category: "must-get-global-outside-init",
+			Message:  message,
+		},
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, `safejs.MustGetGlobal("window")`)),
+			Category: "must-get-global-outside-init",
+			Message:  message,
+		},
+	}, result0.Diagnostics)
+}
+
+func TestMustGetGlobalInTestFiles(t *testing.T) {
+	t.Parallel()
+	const (
+		fooName = "src/foo/foo.go"
+		fooFile = `
+//go:build js && wasm
+
+package foo
+`
+		fooTestName = "src/foo/foo_test.go"
+		fooTestFile = `
+//go:build js && wasm
+
+package foo
+
+import (
+	"testing"
+
+	"github.com/hack-pad/safejs"
+)
+
+var jsMap = safejs.MustGetGlobal("Map")
+
+func TestFoo(t *testing.T) {
+	safejs.MustGetGlobal("document")
+}
+`
+	)
+	dir := makePackageDir(t, map[string]string{
+		safejsStubName: safejsStubFile,
+		fooName:        fooFile,
+		fooTestName:    fooTestFile,
+	})
+
+	result := analysistest.Run(ignoreTestingErrorf{}, dir, MustGetGlobalAnalyzer, "foo")
+	// test files are only in the package's test variant
+	var testFile *token.File
+	var diagnostics []analysis.Diagnostic
+	for _, result := range result {
+		diagnostics = append(diagnostics, result.Diagnostics...)
+		for _, file := range result.Pass.Files {
+			if tokenFile := result.Pass.Fset.File(file.Pos()); strings.HasSuffix(tokenFile.Name(), "foo_test.go") {
+				testFile = tokenFile
+			}
+		}
+	}
+	if testFile == nil {
+		t.Fatal("Test variant of package foo not found")
+	}
+	assertDiagnostics(t, []analysis.Diagnostic{
+		{
+			Pos:      testFile.Pos(strings.Index(fooTestFile, `safejs.MustGetGlobal("document")`)),
+			Category: "must-get-global-outside-init",
+			Message:  "safejs.MustGetGlobal(...) panics at runtime if the global is missing: call it in a package-level var initializer or init(), or handle the error from safejs.Global().Get(...) instead",
+		},
+	}, diagnostics)
+}
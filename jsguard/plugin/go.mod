@@ -0,0 +1,12 @@
+module github.com/hack-pad/safejs/jsguard/plugin
+
+go 1.21
+
+require (
+	github.com/golangci/plugin-module-register v0.1.1
+	github.com/hack-pad/safejs v0.1.0
+	golang.org/x/tools v0.18.0
+)
+
+// Develop against the jsguard analyzers in this repository
+replace github.com/hack-pad/safejs => ../..
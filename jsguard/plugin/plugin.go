@@ -0,0 +1,203 @@
+//go:build !js
+
+// Package plugin registers jsguard's analyzers as a golangci-lint module plugin.
+//
+// Add it to .custom-gcl.yml:
+//
+//	plugins:
+//	  - module: github.com/hack-pad/safejs/jsguard/plugin
+//	    import: github.com/hack-pad/safejs/jsguard/plugin
+//	    version: latest
+//
+// Then enable it in .golangci.yml:
+//
+//	linters-settings:
+//	  custom:
+//	    jsguard:
+//	      type: module
+//	      settings:
+//	        enable: [unsafe-call, ignored-error, unreleased-func, exported-js-type]
+//
+// Run golangci-lint with GOOS=js GOARCH=wasm to analyze js/wasm files.
+package plugin
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/golangci/plugin-module-register/register"
+	"github.com/hack-pad/safejs/jsguard"
+	"golang.org/x/tools/go/analysis"
+)
+
+// Name is the plugin's name in golangci-lint settings
+const Name = "jsguard"
+
+func init() {
+	register.Plugin(Name, New)
+}
+
+// Settings configures the plugin. All fields are optional.
+type Settings struct {
+	// Enable lists rule IDs to report, like "unsafe-call". Defaults to all rules, except opt-in rules like "exported-js-type".
+	Enable []string `json:"enable"`
+	// Disable lists rule IDs to skip
+	Disable []string `json:"disable"`
+	// Config is a path to a .jsguard.yaml config file, defaults to the nearest one
+	Config string `json:"config"`
+	// Unsafe lists extra packages, types, and functions to consider unsafe, like "github.com/example/dom"
+	Unsafe []string `json:"unsafe"`
+	// Safe lists packages, types, and functions to trust, like "syscall/js.Value.Truthy"
+	Safe []string `json:"safe"`
+	// AllowIgnoredErrors lists safejs functions and methods whose errors may be ignored, like "Value.Delete"
+	AllowIgnoredErrors []string `json:"allow-ignored-errors"`
+	// AllowExportedJS lists packages, types, and functions, methods, or fields which may expose syscall/js types, like "github.com/example/dom/compat"
+	AllowExportedJS []string `json:"allow-exported-js"`
+	// Baseline is a path to a baseline file. Only findings missing from the baseline are reported.
+	Baseline string `json:"baseline"`
+}
+
+// Plugin is a golangci-lint plugin running jsguard's analyzers
+type Plugin struct {
+	settings Settings
+}
+
+var _ register.LinterPlugin = (*Plugin)(nil)
+
+// New returns a Plugin configured with settings decoded from golangci-lint
+func New(settings any) (register.LinterPlugin, error) {
+	s, err := register.DecodeSettings[Settings](settings)
+	if err != nil {
+		return nil, err
+	}
+	return &Plugin{settings: s}, nil
+}
+
+// BuildAnalyzers returns the analyzers reporting enabled rules
+func (p *Plugin) BuildAnalyzers() ([]*analysis.Analyzer, error) {
+	enabled, err := enabledRules(p.settings.Enable, p.settings.Disable)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.setFlags(); err != nil {
+		return nil, err
+	}
+
+	var analyzers []*analysis.Analyzer
+	for _, analyzer := range jsguard.Analyzers() {
+		var ruleCount, enabledCount int
+		optIn := false
+		for _, rule := range jsguard.Rules() {
+			if rule.Analyzer == analyzer.Name {
+				ruleCount++
+				if enabled[rule.ID] {
+					enabledCount++
+					optIn = optIn || rule.OptIn
+				}
+			}
+		}
+		if optIn {
+			if err := analyzer.Flags.Set("enable", "true"); err != nil {
+				return nil, fmt.Errorf("failed to enable %s: %w", analyzer.Name, err)
+			}
+		}
+		switch enabledCount {
+		case 0:
+		case ruleCount:
+			analyzers = append(analyzers, analyzer)
+		default:
+			analyzers = append(analyzers, filterRules(analyzer, enabled))
+		}
+	}
+	return analyzers, nil
+}
+
+// GetLoadMode returns the load mode required by jsguard's analyzers
+func (p *Plugin) GetLoadMode() string {
+	return register.LoadModeTypesInfo
+}
+
+// setFlags applies p's non-empty settings to the analyzers' flags and the baseline and config flags shared by all analyzers
+func (p *Plugin) setFlags() error {
+	sharedFlags := flag.NewFlagSet(Name, flag.ContinueOnError)
+	jsguard.AddBaselineFlags(sharedFlags)
+	jsguard.AddConfigFlags(sharedFlags)
+	sharedValues := map[string]string{
+		"baseline": p.settings.Baseline,
+		"config":   p.settings.Config,
+		"unsafe":   strings.Join(p.settings.Unsafe, ","),
+		"safe":     strings.Join(p.settings.Safe, ","),
+	}
+	for name, value := range sharedValues {
+		if value == "" {
+			continue
+		}
+		if err := sharedFlags.Set(name, value); err != nil {
+			return fmt.Errorf("failed to set flag -%s: %w", name, err)
+		}
+	}
+	values := map[string]map[string]string{
+		jsguard.IgnoredErrorAnalyzer.Name: {
+			"allow": strings.Join(p.settings.AllowIgnoredErrors, ","),
+		},
+		jsguard.ExportedJSAnalyzer.Name: {
+			"allow": strings.Join(p.settings.AllowExportedJS, ","),
+		},
+	}
+	for _, analyzer := range jsguard.Analyzers() {
+		for name, value := range values[analyzer.Name] {
+			if value == "" {
+				continue
+			}
+			if err := analyzer.Flags.Set(name, value); err != nil {
+				return fmt.Errorf("failed to set %s flag -%s: %w", analyzer.Name, name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// enabledRules returns the set of rule IDs in enable, or all rules which aren't opt-in if enable is empty, without those in disable
+func enabledRules(enable, disable []string) (map[string]bool, error) {
+	known := make(map[string]bool)
+	optIn := make(map[string]bool)
+	for _, rule := range jsguard.Rules() {
+		known[rule.ID] = true
+		optIn[rule.ID] = rule.OptIn
+	}
+	for _, ruleID := range append(append([]string(nil), enable...), disable...) {
+		if !known[ruleID] {
+			return nil, fmt.Errorf("unknown jsguard rule: %q", ruleID)
+		}
+	}
+
+	enabled := make(map[string]bool)
+	if len(enable) == 0 {
+		for ruleID := range known {
+			enabled[ruleID] = !optIn[ruleID]
+		}
+	}
+	for _, ruleID := range enable {
+		enabled[ruleID] = true
+	}
+	for _, ruleID := range disable {
+		delete(enabled, ruleID)
+	}
+	return enabled, nil
+}
+
+// filterRules returns a copy of analyzer which only reports diagnostics for enabled rules
+func filterRules(analyzer *analysis.Analyzer, enabled map[string]bool) *analysis.Analyzer {
+	filtered := *analyzer
+	filtered.Run = func(pass *analysis.Pass) (interface{}, error) {
+		report := pass.Report
+		pass.Report = func(diagnostic analysis.Diagnostic) {
+			if enabled[diagnostic.Category] {
+				report(diagnostic)
+			}
+		}
+		return analyzer.Run(pass)
+	}
+	return &filtered
+}
@@ -0,0 +1,120 @@
+//go:build !js
+
+package plugin
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/hack-pad/safejs/internal/assert"
+	"github.com/hack-pad/safejs/jsguard"
+	"golang.org/x/tools/go/analysis"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+	p, err := New(map[string]any{
+		"enable":  []any{"unsafe-call", "ignored-error"},
+		"disable": []any{"unreleased-func"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &Plugin{settings: Settings{
+		Enable:  []string{"unsafe-call", "ignored-error"},
+		Disable: []string{"unreleased-func"},
+	}}, p)
+	assert.Equal(t, "typesinfo", p.GetLoadMode())
+
+	_, err = New(map[string]any{"enabled": []any{"unsafe-call"}})
+	assert.EqualError(t, err, `decoding settings: json: unknown field "enabled"`)
+}
+
+func TestBuildAnalyzers(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		settings        Settings
+		expectAnalyzers []string
+		expectErr       string
+	}{
+		{
+			expectAnalyzers: []string{"jsguard", "callbackblock", "callbackpanic", "funcleak", "funcrelease", "ignorederror", "mustgetglobal", "unsafecaller", "valueconv"},
+		},
+		{
+			settings:        Settings{Enable: []string{"unsafe-call", "ignored-error", "unreleased-func"}},
+			expectAnalyzers: []string{"jsguard", "funcleak", "ignorederror"},
+		},
+		{
+			settings:        Settings{Disable: []string{"blocking-callback", "unsafe-caller", "unsupported-conversion"}},
+			expectAnalyzers: []string{"jsguard", "callbackpanic", "funcleak", "funcrelease", "ignorederror", "mustgetglobal"},
+		},
+		{
+			settings:        Settings{Enable: []string{"exported-js-type"}, AllowExportedJS: []string{"example.com/compat"}},
+			expectAnalyzers: []string{"exportedjs"},
+		},
+		{
+			settings:        Settings{Enable: []string{"unsafe-caller"}, Unsafe: []string{"example.com/dom"}},
+			expectAnalyzers: []string{"unsafecaller"},
+		},
+		{
+			settings:  Settings{Enable: []string{"unsafe-call", "funcleak"}},
+			expectErr: `unknown jsguard rule: "funcleak"`,
+		},
+	} {
+		p := &Plugin{settings: tc.settings}
+		analyzers, err := p.BuildAnalyzers()
+		if tc.expectErr != "" {
+			assert.EqualError(t, err, tc.expectErr)
+			continue
+		}
+		assert.NoError(t, err)
+		var names []string
+		for _, analyzer := range analyzers {
+			names = append(names, analyzer.Name)
+		}
+		assert.Equal(t, tc.expectAnalyzers, names)
+	}
+	assert.Equal(t, "true", jsguard.ExportedJSAnalyzer.Flags.Lookup("enable").Value.String())
+	assert.Equal(t, "example.com/compat", jsguard.ExportedJSAnalyzer.Flags.Lookup("allow").Value.String())
+	// the config flags are shared by all analyzers, instead of set per analyzer
+	assert.Equal(t, (*flag.Flag)(nil), jsguard.Analyzer.Flags.Lookup("unsafe"))
+	assert.Equal(t, (*flag.Flag)(nil), jsguard.UnsafeCallerAnalyzer.Flags.Lookup("unsafe"))
+}
+
+func TestBuildAnalyzersFiltersRules(t *testing.T) {
+	t.Parallel()
+	p := &Plugin{settings: Settings{Enable: []string{"unsafe-call"}}}
+	analyzers, err := p.BuildAnalyzers()
+	assert.NoError(t, err)
+	if !assert.Equal(t, 1, len(analyzers)) {
+		t.FailNow()
+	}
+	assert.Equal(t, false, analyzers[0] == jsguard.Analyzer)
+
+	// the full analyzer is used when all of its rules are enabled
+	p = &Plugin{settings: Settings{Enable: []string{"ignored-error"}}}
+	analyzers, err = p.BuildAnalyzers()
+	assert.NoError(t, err)
+	assert.Equal(t, []*analysis.Analyzer{jsguard.IgnoredErrorAnalyzer}, analyzers)
+}
+
+func TestFilterRules(t *testing.T) {
+	t.Parallel()
+	analyzer := &analysis.Analyzer{
+		Name: "fake",
+		Doc:  "fake",
+		Run: func(pass *analysis.Pass) (interface{}, error) {
+			pass.Report(analysis.Diagnostic{Category: "a", Message: "a"})
+			pass.Report(analysis.Diagnostic{Category: "b", Message: "b"})
+			return "result", nil
+		},
+	}
+	var diagnostics []analysis.Diagnostic
+	pass := &analysis.Pass{
+		Report: func(diagnostic analysis.Diagnostic) {
+			diagnostics = append(diagnostics, diagnostic)
+		},
+	}
+	result, err := filterRules(analyzer, map[string]bool{"b": true}).Run(pass)
+	assert.NoError(t, err)
+	assert.Equal(t, "result", result)
+	assert.Equal(t, []analysis.Diagnostic{{Category: "b", Message: "b"}}, diagnostics)
+}
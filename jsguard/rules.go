@@ -0,0 +1,79 @@
+//go:build !js
+
+package jsguard
+
+import (
+	"fmt"
+	"go/token"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// Rule describes a kind of diagnostic. A diagnostic's Category is its rule's ID.
+type Rule struct {
+	// ID is a stable, unique identifier, like "unsafe-call"
+	ID string
+	// Analyzer is the name of the analyzer reporting this rule
+	Analyzer string
+	// Description summarizes the problem
+	Description string
+	// HelpURL links to documentation for fixing the problem
+	HelpURL string
+	// OptIn rules are only reported when enabled explicitly, like with their analyzer's -enable flag
+	OptIn bool
+}
+
+const (
+	ruleUnsafeCall               = "unsafe-call"
+	ruleUnsafeMethod             = "unsafe-method"
+	ruleUnsafeFuncValue          = "unsafe-func-value"
+	ruleUnsafeMethodValue        = "unsafe-method-value"
+	ruleIgnoreMissingReason      = "ignore-missing-reason"
+	ruleUnsafeCaller             = "unsafe-caller"
+	ruleUnreleasedFunc           = "unreleased-func"
+	ruleBlockingCallback         = "blocking-callback"
+	rulePanickingCallback        = "panicking-callback"
+	ruleMustGetGlobalOutsideInit = "must-get-global-outside-init"
+	ruleIgnoredError             = "ignored-error"
+	ruleUnsupportedConversion    = "unsupported-conversion"
+	ruleExportedJSType           = "exported-js-type"
+	ruleFuncUseAfterRelease      = "func-use-after-release"
+	ruleFuncDoubleRelease        = "func-double-release"
+)
+
+const (
+	safejsDocURL  = "https://pkg.go.dev/github.com/hack-pad/safejs"
+	jsguardDocURL = "https://pkg.go.dev/github.com/hack-pad/safejs/jsguard"
+)
+
+var rules = []Rule{
+	{ID: ruleUnsafeCall, Analyzer: "jsguard", Description: "Call to a syscall/js function which may panic. Use the safejs equivalent, which returns an error instead.", HelpURL: safejsDocURL},
+	{ID: ruleUnsafeMethod, Analyzer: "jsguard", Description: "Call to a syscall/js method which may panic. Use the safejs equivalent, which returns an error instead.", HelpURL: safejsDocURL + "#Value"},
+	{ID: ruleUnsafeFuncValue, Analyzer: "jsguard", Description: "syscall/js function used as a value, which may panic when called. Use the safejs equivalent instead.", HelpURL: safejsDocURL},
+	{ID: ruleUnsafeMethodValue, Analyzer: "jsguard", Description: "syscall/js method used as a value, which may panic when called. Use the safejs equivalent instead.", HelpURL: safejsDocURL + "#Value"},
+	{ID: ruleIgnoreMissingReason, Analyzer: "jsguard", Description: "//jsguard:ignore directive without a reason.", HelpURL: jsguardDocURL},
+	{ID: ruleUnsafeCaller, Analyzer: "unsafecaller", Description: "Call to a function which may panic through syscall/js. Recover in the function and mark it //jsguard:safe, or migrate it to safejs.", HelpURL: jsguardDocURL},
+	{ID: ruleUnreleasedFunc, Analyzer: "funcleak", Description: "Func created by FuncOf is never released, leaking the Go function.", HelpURL: safejsDocURL + "#Func.Release"},
+	{ID: ruleFuncUseAfterRelease, Analyzer: "funcrelease", Description: "Func used after Release, which panics when JavaScript calls it.", HelpURL: safejsDocURL + "#Func.Release"},
+	{ID: ruleFuncDoubleRelease, Analyzer: "funcrelease", Description: "Func released twice.", HelpURL: safejsDocURL + "#Func.Release"},
+	{ID: ruleBlockingCallback, Analyzer: "callbackblock", Description: "Blocking operation inside a FuncOf callback, which deadlocks the JavaScript event loop.", HelpURL: safejsDocURL + "#FuncOf"},
+	{ID: rulePanickingCallback, Analyzer: "callbackpanic", Description: "Panic or log.Fatal inside a FuncOf callback, which crashes the whole Go program.", HelpURL: safejsDocURL + "#FuncOf"},
+	{ID: ruleMustGetGlobalOutsideInit, Analyzer: "mustgetglobal", Description: "MustGetGlobal called outside package initialization, where its panic crashes the program at runtime. Use Global().Get and handle the error instead.", HelpURL: safejsDocURL + "#MustGetGlobal"},
+	{ID: ruleIgnoredError, Analyzer: "ignorederror", Description: "Error returned by safejs is ignored.", HelpURL: safejsDocURL},
+	{ID: ruleUnsupportedConversion, Analyzer: "valueconv", Description: "Value with a type which can never be converted to a JavaScript value.", HelpURL: safejsDocURL + "#ValueOf"},
+	{ID: ruleExportedJSType, Analyzer: "exportedjs", Description: "Exported API whose signature mentions syscall/js types. Expose safejs types instead, and unwrap them with safejs.Unsafe at explicit boundaries.", HelpURL: safejsDocURL + "#Unsafe", OptIn: true},
+}
+
+// Rules returns all rules reported by jsguard's analyzers
+func Rules() []Rule {
+	return append([]Rule(nil), rules...)
+}
+
+// reportf reports a diagnostic for the rule with ID ruleID at pos
+func reportf(pass *analysis.Pass, ruleID string, pos token.Pos, format string, args ...interface{}) {
+	pass.Report(analysis.Diagnostic{
+		Pos:      pos,
+		Category: ruleID,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
@@ -0,0 +1,32 @@
+//go:build !js
+
+package jsguard
+
+import (
+	"testing"
+
+	"github.com/hack-pad/safejs/internal/assert"
+	"golang.org/x/tools/go/analysis"
+)
+
+func TestAnalyzersValidate(t *testing.T) {
+	t.Parallel()
+	assert.NoError(t, analysis.Validate(Analyzers()))
+}
+
+func TestRules(t *testing.T) {
+	t.Parallel()
+	analyzerNames := make(map[string]bool)
+	for _, analyzer := range Analyzers() {
+		analyzerNames[analyzer.Name] = true
+	}
+
+	ruleIDs := make(map[string]bool)
+	for _, rule := range Rules() {
+		assert.Equal(t, false, ruleIDs[rule.ID])
+		ruleIDs[rule.ID] = true
+		assert.Equal(t, true, analyzerNames[rule.Analyzer])
+		assert.Equal(t, true, rule.Description != "")
+		assert.Equal(t, true, rule.HelpURL != "")
+	}
+}
@@ -0,0 +1,305 @@
+//go:build !js
+
+package jsguard
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// UnsafeCallerAnalyzer reports calls to functions which may panic through syscall/js, including functions in other packages.
+//
+// Functions which recover from panics themselves may be marked with safeDirective.
+var UnsafeCallerAnalyzer = &analysis.Analyzer{
+	Name:      "unsafecaller",
+	Doc:       "report calls to functions which may panic through syscall/js",
+	Run:       runUnsafeCaller,
+	FactTypes: []analysis.Fact{new(mayPanicFact), new(recoversFact)},
+}
+
+// safeDirective marks a function as recovering from any syscall/js panics itself, so its callers are not reported.
+//
+// For example:
+//
+//	// Title returns the document's title, or an error if it isn't available.
+//	//
+//	//jsguard:safe
+//	func Title() (title string, err error)
+const safeDirective = "//jsguard:safe"
+
+// mayPanicFact marks functions which may panic through syscall/js.
+// Chain lists the calls leading to the panic, ending with the syscall/js function.
+type mayPanicFact struct {
+	Chain []string
+}
+
+func (*mayPanicFact) AFact() {}
+
+func (f *mayPanicFact) String() string {
+	return fmt.Sprintf("mayPanic(%s)", strings.Join(f.Chain, " -> "))
+}
+
+// recoversFact marks functions which call recover(), so deferring a call to them recovers from panics.
+// Analyzers can't share fact types, so CallbackPanicAnalyzer has its own callbackRecoversFact.
+type recoversFact struct{}
+
+func (*recoversFact) AFact() {}
+
+func (*recoversFact) String() string {
+	return "recovers"
+}
+
+func runUnsafeCaller(pass *analysis.Pass) (interface{}, error) {
+	cfg, err := loadConfig(pass)
+	if err != nil {
+		return nil, err
+	}
+	finishBaseline, err := applyBaseline(pass)
+	if err != nil {
+		return nil, err
+	}
+	honorIgnoreDirectives(pass)
+	decls := funcDecls(pass)
+	exportRecoversFacts(pass, decls, newRecoversFact)
+	// sort functions in source order, so the reported chains are stable
+	funcs := make([]*types.Func, 0, len(decls))
+	guarded := make(map[*ast.FuncDecl]bool)
+	for fn, decl := range decls {
+		funcs = append(funcs, fn)
+		guarded[decl] = hasDirective(decl.Doc, safeDirective) || recovers(pass, decls, decl.Body, newRecoversFact)
+	}
+	sort.Slice(funcs, func(a, b int) bool {
+		return funcs[a].Pos() < funcs[b].Pos()
+	})
+
+	checker := unsafeCallerChecker{
+		pass:    pass,
+		config:  cfg,
+		decls:   decls,
+		guarded: guarded,
+		chains:  make(map[*types.Func][]string),
+	}
+	// repeat until no new chains are found, since recursive calls are skipped while their callers are checked
+	for changed := true; changed; {
+		changed = false
+		checker.checked = make(map[*types.Func]bool)
+		for _, fn := range funcs {
+			if checker.chains[fn] == nil && checker.funcChain(fn) != nil {
+				changed = true
+			}
+		}
+	}
+	// safejs recovers from syscall/js panics, so don't mark its internals as unsafe.
+	// The standard library only uses syscall/js to implement itself on js/wasm, so its functions aren't marked either.
+	if exportsMayPanic(pass) {
+		for fn, chain := range checker.chains {
+			pass.ExportObjectFact(fn, &mayPanicFact{Chain: chain})
+		}
+	}
+
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			if funcDecl, ok := decl.(*ast.FuncDecl); ok && guarded[funcDecl] {
+				continue
+			}
+			ast.Inspect(decl, func(node ast.Node) bool {
+				if call, ok := node.(*ast.CallExpr); ok {
+					if chain := checker.wrapperChain(call); chain != nil {
+						reportf(pass, ruleUnsafeCaller, call.Pos(), "%s may panic through syscall/js: %s", formatCall(pass.Fset, call), strings.Join(chain, " -> "))
+					}
+				}
+				return true
+			})
+		}
+	}
+	return nil, finishBaseline()
+}
+
+type unsafeCallerChecker struct {
+	pass    *analysis.Pass
+	config  *config
+	decls   map[*types.Func]*ast.FuncDecl
+	guarded map[*ast.FuncDecl]bool
+	// chains holds the call chain to syscall/js for each function in this package which may panic
+	chains map[*types.Func][]string
+	// checked holds functions already checked, or being checked, during this round
+	checked map[*types.Func]bool
+}
+
+// funcChain returns the call chain to syscall/js for same-package function fn, or nil if it doesn't panic or hasn't been found to yet
+func (u unsafeCallerChecker) funcChain(fn *types.Func) []string {
+	if u.chains[fn] != nil || u.checked[fn] {
+		return u.chains[fn]
+	}
+	u.checked[fn] = true
+	decl := u.decls[fn]
+	if decl == nil || u.guarded[decl] {
+		return nil
+	}
+	chain := u.bodyChain(decl.Body)
+	if chain != nil {
+		u.chains[fn] = chain
+	}
+	return chain
+}
+
+// bodyChain returns the call chain of the first call in body which may panic through syscall/js, or nil if there are none.
+// Function literals are skipped unless they're called immediately, since they may run elsewhere.
+func (u unsafeCallerChecker) bodyChain(body *ast.BlockStmt) []string {
+	var chain []string
+	ast.Inspect(body, func(node ast.Node) bool {
+		if chain != nil {
+			return false
+		}
+		switch node := node.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.CallExpr:
+			if funcLit, ok := unparen(node.Fun).(*ast.FuncLit); ok {
+				chain = u.bodyChain(funcLit.Body)
+			} else {
+				chain = u.callChain(node)
+			}
+		}
+		return true
+	})
+	return chain
+}
+
+// callChain returns the call chain if call may panic through syscall/js, either directly, through a function configured as unsafe, or through a wrapper function
+func (u unsafeCallerChecker) callChain(call *ast.CallExpr) []string {
+	fn := calledFunc(u.pass, call.Fun)
+	if fn == nil {
+		return nil
+	}
+	if !u.config.isSafeCall(fn) {
+		return []string{funcDisplayName(fn)}
+	}
+	return u.wrapperChain(call)
+}
+
+// wrapperChain returns the call chain if call's function is not in syscall/js, but may panic through it
+func (u unsafeCallerChecker) wrapperChain(call *ast.CallExpr) []string {
+	fn := calledFunc(u.pass, call.Fun)
+	if fn == nil || fn.Pkg() == nil || fn.Pkg().Path() == jsPackagePath || u.config.isTrusted(fn) {
+		return nil
+	}
+	var chain []string
+	if fn.Pkg() == u.pass.Pkg {
+		chain = u.funcChain(fn)
+	} else {
+		var fact mayPanicFact
+		if u.pass.ImportObjectFact(fn, &fact) {
+			chain = fact.Chain
+		}
+	}
+	if chain == nil {
+		return nil
+	}
+	return append([]string{funcDisplayName(fn)}, chain...)
+}
+
+// exportsMayPanic returns true if pass's functions should be marked with a mayPanicFact when they may panic through syscall/js
+func exportsMayPanic(pass *analysis.Pass) bool {
+	path := pass.Pkg.Path()
+	switch {
+	case path == safejsPackagePath || strings.HasPrefix(path, safejsPackagePath+"/"):
+		return false
+	case path == jsPackagePath:
+		return true
+	default:
+		return !inGOROOT(pass)
+	}
+}
+
+// inGOROOT returns true if pass's package is part of the standard library
+func inGOROOT(pass *analysis.Pass) bool {
+	if len(pass.Files) == 0 {
+		return false
+	}
+	return isGOROOTFile(pass.Fset.File(pass.Files[0].Pos()).Name())
+}
+
+// isGOROOTFile returns true if fileName is in a standard library package
+func isGOROOTFile(fileName string) bool {
+	goroot := filepath.Join(build.Default.GOROOT, "src") + string(filepath.Separator)
+	return strings.HasPrefix(filepath.Clean(fileName), goroot)
+}
+
+func newRecoversFact() analysis.Fact {
+	return &recoversFact{}
+}
+
+// exportRecoversFacts marks each function in decls which calls recover() with a fact from newFact
+func exportRecoversFacts(pass *analysis.Pass, decls map[*types.Func]*ast.FuncDecl, newFact func() analysis.Fact) {
+	for fn, decl := range decls {
+		if callsRecover(pass, decl.Body) {
+			pass.ExportObjectFact(fn, newFact())
+		}
+	}
+}
+
+// recovers returns true if body defers a function which calls recover(), either a function literal or a named function.
+// Named functions in other packages are found with facts from newFact, so callers must export them with exportRecoversFacts.
+func recovers(pass *analysis.Pass, decls map[*types.Func]*ast.FuncDecl, body *ast.BlockStmt, newFact func() analysis.Fact) bool {
+	for _, stmt := range body.List {
+		deferStmt, ok := stmt.(*ast.DeferStmt)
+		if !ok {
+			continue
+		}
+		if funcLit, ok := unparen(deferStmt.Call.Fun).(*ast.FuncLit); ok {
+			if callsRecover(pass, funcLit.Body) {
+				return true
+			}
+			continue
+		}
+		fn := calledFunc(pass, deferStmt.Call.Fun)
+		switch {
+		case fn == nil:
+		case decls[fn] != nil:
+			if callsRecover(pass, decls[fn].Body) {
+				return true
+			}
+		case fn.Pkg() != pass.Pkg:
+			if pass.ImportObjectFact(fn, newFact()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// callsRecover returns true if body calls recover()
+func callsRecover(pass *analysis.Pass, body *ast.BlockStmt) bool {
+	found := false
+	ast.Inspect(body, func(node ast.Node) bool {
+		if call, ok := node.(*ast.CallExpr); ok && isBuiltin(pass, call, "recover") {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// funcDisplayName returns fn's name qualified by package name and receiver type, like "js.Value.Get"
+func funcDisplayName(fn *types.Func) string {
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return fmt.Sprintf("%s.%s", fn.Pkg().Name(), fn.Name())
+	}
+	recvType := recv.Type()
+	if pointer, ok := recvType.(*types.Pointer); ok {
+		recvType = pointer.Elem()
+	}
+	if named, ok := recvType.(*types.Named); ok {
+		return fmt.Sprintf("%s.%s.%s", fn.Pkg().Name(), named.Obj().Name(), fn.Name())
+	}
+	return fmt.Sprintf("%s.%s", fn.Pkg().Name(), fn.Name())
+}
@@ -0,0 +1,154 @@
+//go:build !js
+
+package jsguard
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hack-pad/safejs/internal/assert"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestUnsafeCaller(t *testing.T) {
+	t.Parallel()
+	const (
+		domName = "src/dom/dom.go"
+		domFile = `
+//go:build js && wasm
+
+package dom
+
+import (
+	"errors"
+	"syscall/js"
+)
+
+type Element struct {
+	value js.Value
+}
+
+func Query(selector string) Element {
+	doc := document()
+	return Element{value: doc.Call("querySelector", selector)}
+}
+
+func document() js.Value {
+	return js.Global().Get("document")
+}
+
+func (e *Element) SetText(text string) {
+	e.value.Set("textContent", text)
+}
+
+func (e *Element) IsMissing() bool {
+	return e.value.IsUndefined()
+}
+
+//jsguard:safe
+func SafeQuery(selector string) (Element, error) {
+	return Element{value: document()}, nil
+}
+
+func RecoveredQuery(selector string) (element Element, err error) {
+	defer func() {
+		if recover() != nil {
+			err = errors.New("query failed")
+		}
+	}()
+	return Query(selector), nil
+}
+
+func RecoverErr(err *error) {
+	if recover() != nil {
+		*err = errors.New("failed")
+	}
+}
+
+func recoverQuery(err *error) {
+	if recover() != nil {
+		*err = errors.New("query failed")
+	}
+}
+
+func HelperRecoveredQuery(selector string) (element Element, err error) {
+	defer recoverQuery(&err)
+	return Query(selector), nil
+}
+
+func Later() func() {
+	return func() {
+		document()
+	}
+}
+`
+		fooName = "src/foo/foo.go"
+		fooFile = `
+//go:build js && wasm
+
+package foo
+
+import (
+	"dom"
+	"os"
+)
+
+var title = dom.Query("title")
+
+func setTitle(text string) {
+	title.SetText(text)
+}
+
+func Foo() {
+	setTitle("hello")
+	button := dom.Query("button")
+	_ = button.IsMissing()
+	dom.SafeQuery("a")
+	dom.RecoveredQuery("b")
+	dom.HelperRecoveredQuery("c")
+	dom.Later()
+	os.Getwd()
+}
+
+func RecoveredTitle(text string) (err error) {
+	defer dom.RecoverErr(&err)
+	setTitle(text)
+	return nil
+}
+`
+	)
+	dir := makePackageDir(t, map[string]string{
+		domName: domFile,
+		fooName: fooFile,
+	})
+
+	result := analysistest.Run(ignoreTestingErrorf{}, dir, UnsafeCallerAnalyzer, "foo")
+	if !assert.Equal(t, 1, len(result)) {
+		t.FailNow()
+	}
+	result0 := result[0]
+	pass := result0.Pass
+	assertDiagnostics(t, []analysis.Diagnostic{
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, `dom.Query("title")`)),
+			Category: "unsafe-caller",
+			Message:  "dom.Query(...) may panic through syscall/js: dom.Query -> dom.document -> js.Value.Get",
+		},
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, `title.SetText(text)`)),
+			Category: "unsafe-caller",
+			Message:  "title.SetText(...) may panic through syscall/js: dom.Element.SetText -> js.Value.Set",
+		},
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, `setTitle("hello")`)),
+			Category: "unsafe-caller",
+			Message:  "setTitle(...) may panic through syscall/js: foo.setTitle -> dom.Element.SetText -> js.Value.Set",
+		},
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, `dom.Query("button")`)),
+			Category: "unsafe-caller",
+			Message:  "dom.Query(...) may panic through syscall/js: dom.Query -> dom.document -> js.Value.Get",
+		},
+	}, result0.Diagnostics)
+}
@@ -0,0 +1,216 @@
+//go:build !js
+
+package jsguard
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// ValueConversionAnalyzer reports values passed to JavaScript whose static types can never be converted by js.ValueOf.
+//
+// syscall/js panics on these values, and safejs returns an error at runtime instead.
+var ValueConversionAnalyzer = &analysis.Analyzer{
+	Name: "valueconv",
+	Doc:  "report values passed to JavaScript with types js.ValueOf cannot convert",
+	Run:  runValueConversion,
+}
+
+const (
+	jsSupportedTypes     = "js.Value, js.Func, nil, bool, integer and float types, string, []any, and map[string]any"
+	safejsSupportedTypes = "safejs.Value, safejs.Func, safejs.Error, " + jsSupportedTypes
+)
+
+// convertedArgs describes which arguments of a function get converted to JavaScript values
+type convertedArgs struct {
+	// first is the index of the first converted argument. All following arguments are converted too.
+	first int
+	// safejsTypes is true if safejs.Value, safejs.Func, and safejs.Error are converted too
+	safejsTypes bool
+}
+
+// convertingFuncs are functions which convert their arguments with js.ValueOf, keyed by package path or receiver type and then function name
+var convertingFuncs = map[string]map[string]convertedArgs{
+	jsPackagePath: {
+		"ValueOf": {first: 0},
+	},
+	jsPackagePath + ".Value": {
+		"Call":     {first: 1},
+		"Invoke":   {first: 0},
+		"New":      {first: 0},
+		"Set":      {first: 1},
+		"SetIndex": {first: 1},
+	},
+	safejsPackagePath: {
+		"ArrayOf": {first: 0, safejsTypes: true},
+		"ValueOf": {first: 0},
+	},
+	safejsPackagePath + ".Value": {
+		"Apply":     {first: 1, safejsTypes: true},
+		"Bind":      {first: 1, safejsTypes: true},
+		"Call":      {first: 1, safejsTypes: true},
+		"Invoke":    {first: 0, safejsTypes: true},
+		"New":       {first: 0, safejsTypes: true},
+		"Set":       {first: 1, safejsTypes: true},
+		"SetIndex":  {first: 1, safejsTypes: true},
+		"SetSymbol": {first: 1, safejsTypes: true},
+	},
+}
+
+func runValueConversion(pass *analysis.Pass) (interface{}, error) {
+	finishBaseline, err := applyBaseline(pass)
+	if err != nil {
+		return nil, err
+	}
+	honorIgnoreDirectives(pass)
+	decls := funcDecls(pass)
+	for _, file := range pass.Files {
+		ast.Inspect(file, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			if isFuncOfCall(pass, call) {
+				inspectCallbackReturns(pass, decls, call)
+				return true
+			}
+			inspectConvertedArgs(pass, call)
+			return true
+		})
+	}
+	return nil, finishBaseline()
+}
+
+func inspectConvertedArgs(pass *analysis.Pass, call *ast.CallExpr) {
+	fn := calledFunc(pass, call.Fun)
+	if fn == nil {
+		return
+	}
+	typeName, ok := funcTypeName(fn)
+	if !ok {
+		return
+	}
+	converted, ok := convertingFuncs[typeName][fn.Name()]
+	if !ok {
+		return
+	}
+	args := call.Args
+	if call.Ellipsis.IsValid() {
+		args = args[:len(args)-1] // the spread []any is converted element by element at runtime
+	}
+	for i := converted.first; i < len(args); i++ {
+		typ := pass.TypesInfo.TypeOf(args[i])
+		if typ == nil || convertibleType(typ, converted.safejsTypes) {
+			continue
+		}
+		reportf(pass, ruleUnsupportedConversion, args[i].Pos(), "unsupported type %s passed to %s%s; supported types are %s",
+			typeString(pass, typ), formatCall(pass.Fset, call), conversionHint(typ, converted.safejsTypes), supportedTypes(converted.safejsTypes))
+	}
+}
+
+// inspectCallbackReturns reports unsupported types returned from funcOfCall's callback
+func inspectCallbackReturns(pass *analysis.Pass, decls map[*types.Func]*ast.FuncDecl, funcOfCall *ast.CallExpr) {
+	body := funcOfCallback(pass, decls, funcOfCall)
+	if body == nil {
+		return
+	}
+	safejsTypes := calledFunc(pass, funcOfCall.Fun).Pkg().Path() == safejsPackagePath
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			if len(node.Results) != 1 {
+				return true
+			}
+			typ := pass.TypesInfo.TypeOf(node.Results[0])
+			if typ == nil || convertibleType(typ, safejsTypes) {
+				return true
+			}
+			reportf(pass, ruleUnsupportedConversion, node.Results[0].Pos(), "unsupported type %s returned from %s callback%s; supported types are %s",
+				typeString(pass, typ), formatCall(pass.Fset, funcOfCall), conversionHint(typ, safejsTypes), supportedTypes(safejsTypes))
+		}
+		return true
+	})
+}
+
+// convertibleType returns true if values of type typ may be converted by js.ValueOf.
+// Interfaces are only checked at runtime, so they're always considered convertible.
+func convertibleType(typ types.Type, safejsTypes bool) bool {
+	if types.IsInterface(typ) {
+		return true
+	}
+	switch typ := typ.(type) {
+	case *types.Basic:
+		info := typ.Info()
+		return typ.Kind() == types.UntypedNil ||
+			typ.Kind() == types.UnsafePointer ||
+			info&(types.IsBoolean|types.IsString) != 0 ||
+			info&types.IsNumeric != 0 && info&types.IsComplex == 0
+	case *types.Slice:
+		return isEmptyInterface(typ.Elem())
+	case *types.Map:
+		return types.Identical(typ.Key(), types.Typ[types.String]) && isEmptyInterface(typ.Elem())
+	case *types.Named:
+		return isJSType(typ, "Value") || isJSType(typ, "Func") ||
+			safejsTypes && (isSafejsType(typ) || isNamedType(typ, safejsPackagePath, "Func") || isNamedType(typ, safejsPackagePath, "Error"))
+	default:
+		return false
+	}
+}
+
+func isEmptyInterface(typ types.Type) bool {
+	return types.Identical(typ, types.NewInterfaceType(nil, nil))
+}
+
+func isNamedType(typ types.Type, pkgPath, name string) bool {
+	named, ok := typ.(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == pkgPath && named.Obj().Name() == name
+}
+
+// conversionHint suggests how to convert a value of type typ into a supported type, if there's an obvious way
+func conversionHint(typ types.Type, safejsTypes bool) string {
+	if !safejsTypes && (isSafejsType(typ) || isNamedType(typ, safejsPackagePath, "Func")) {
+		return ", unwrap it with safejs.Unsafe"
+	}
+	if named, ok := typ.(*types.Named); ok {
+		if basic, ok := named.Underlying().(*types.Basic); ok && convertibleType(basic, safejsTypes) {
+			return fmt.Sprintf(", convert it to %s", basic.Name())
+		}
+	}
+	switch underlying := typ.Underlying().(type) {
+	case *types.Slice, *types.Array:
+		return ", copy it into a []any"
+	case *types.Map:
+		if key, ok := underlying.Key().Underlying().(*types.Basic); ok && key.Info()&types.IsString != 0 {
+			return ", copy it into a map[string]any"
+		}
+	case *types.Struct:
+		return ", copy its fields into a map[string]any"
+	case *types.Pointer:
+		if _, isStruct := underlying.Elem().Underlying().(*types.Struct); isStruct {
+			return ", copy its fields into a map[string]any"
+		}
+	}
+	return ""
+}
+
+func supportedTypes(safejsTypes bool) string {
+	if safejsTypes {
+		return safejsSupportedTypes
+	}
+	return jsSupportedTypes
+}
+
+// typeString formats typ with package names instead of paths, like "safejs.Value"
+func typeString(pass *analysis.Pass, typ types.Type) string {
+	return types.TypeString(typ, func(pkg *types.Package) string {
+		if pkg == pass.Pkg {
+			return ""
+		}
+		return pkg.Name()
+	})
+}
@@ -0,0 +1,140 @@
+//go:build !js
+
+package jsguard
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hack-pad/safejs/internal/assert"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestValueConversion(t *testing.T) {
+	t.Parallel()
+	const (
+		fooName = "src/foo/foo.go"
+		fooFile = `
+//go:build js && wasm
+
+package foo
+
+import (
+	"syscall/js"
+	"unsafe"
+
+	"github.com/hack-pad/safejs"
+)
+
+type ID string
+
+type point struct {
+	X, Y int
+}
+
+func Foo(v js.Value, s safejs.Value, e safejs.Error, x any, args []any) {
+	js.ValueOf(nil)
+	js.ValueOf(true)
+	js.ValueOf(1.5)
+	js.ValueOf(uint8(1))
+	js.ValueOf(unsafe.Pointer(nil))
+	js.ValueOf("str")
+	js.ValueOf([]any{1})
+	js.ValueOf(map[string]any{"a": 1})
+	js.ValueOf(x)
+	js.ValueOf(v)
+	js.ValueOf(js.FuncOf(nil))
+	v.Call("f", args...)
+	v.Call("f", []string{"a"})
+	v.Invoke(point{})
+	v.New(&point{})
+	v.Set("id", ID("a"))
+	v.SetIndex(0, map[string]string{})
+	v.Call("f", s)
+	js.ValueOf(make(chan int))
+	s.Call("f", s, v)
+	s.Set("points", [2]point{})
+	s.Set("err", e)
+	safejs.ValueOf(s)
+	js.FuncOf(func(this js.Value, args []js.Value) any {
+		if len(args) == 0 {
+			return nil
+		}
+		return []int{1}
+	})
+	safejs.FuncOf(func(this safejs.Value, args []safejs.Value) any {
+		func() []int { return nil }()
+		return this
+	})
+}
+`
+	)
+	dir := makePackageDir(t, map[string]string{
+		safejsStubName: safejsStubFile,
+		fooName:        fooFile,
+	})
+
+	result := analysistest.Run(ignoreTestingErrorf{}, dir, ValueConversionAnalyzer, "foo")
+	if !assert.Equal(t, 1, len(result)) {
+		t.FailNow()
+	}
+	result0 := result[0]
+	pass := result0.Pass
+	const (
+		jsSupported     = "; supported types are js.Value, js.Func, nil, bool, integer and float types, string, []any, and map[string]any"
+		safejsSupported = "; supported types are safejs.Value, safejs.Func, safejs.Error, js.Value, js.Func, nil, bool, integer and float types, string, []any, and map[string]any"
+	)
+	assertDiagnostics(t, []analysis.Diagnostic{
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, `[]string{"a"}`)),
+			Category: "unsupported-conversion",
+			Message:  "unsupported type []string passed to v.Call(...), copy it into a []any" + jsSupported,
+		},
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, `point{})`)),
+			Category: "unsupported-conversion",
+			Message:  "unsupported type point passed to v.Invoke(...), copy its fields into a map[string]any" + jsSupported,
+		},
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, `&point{}`)),
+			Category: "unsupported-conversion",
+			Message:  "unsupported type *point passed to v.New(...), copy its fields into a map[string]any" + jsSupported,
+		},
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, `ID("a")`)),
+			Category: "unsupported-conversion",
+			Message:  "unsupported type ID passed to v.Set(...), convert it to string" + jsSupported,
+		},
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, `map[string]string{}`)),
+			Category: "unsupported-conversion",
+			Message:  "unsupported type map[string]string passed to v.SetIndex(...), copy it into a map[string]any" + jsSupported,
+		},
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, `s)`)),
+			Category: "unsupported-conversion",
+			Message:  "unsupported type safejs.Value passed to v.Call(...), unwrap it with safejs.Unsafe" + jsSupported,
+		},
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, `make(chan int)`)),
+			Category: "unsupported-conversion",
+			Message:  "unsupported type chan int passed to js.ValueOf(...)" + jsSupported,
+		},
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, `[2]point{}`)),
+			Category: "unsupported-conversion",
+			Message:  "unsupported type [2]point passed to s.Set(...), copy it into a []any" + safejsSupported,
+		},
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, `s)`+"\n\tjs.FuncOf")),
+			Category: "unsupported-conversion",
+			Message:  "unsupported type safejs.Value passed to safejs.ValueOf(...), unwrap it with safejs.Unsafe" + jsSupported,
+		},
+		{
+			Pos:      filePos(t, pass, "foo", strings.Index(fooFile, `[]int{1}`)),
+			Category: "unsupported-conversion",
+			Message:  "unsupported type []int returned from js.FuncOf(...) callback, copy it into a []any" + jsSupported,
+		},
+	}, result0.Diagnostics)
+}
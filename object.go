@@ -0,0 +1,70 @@
+package safejs
+
+import (
+	"fmt"
+)
+
+// NewObject returns a new, empty JavaScript object. Equivalent to JavaScript's "{}".
+func NewObject() (Value, error) {
+	objectType, err := jsObject.Get()
+	if err != nil {
+		return Value{}, err
+	}
+	return objectType.New()
+}
+
+// NewArray returns a new JavaScript array with the given length. Equivalent to JavaScript's "new Array(length)".
+// Returns an error if length is negative.
+func NewArray(length int) (Value, error) {
+	if length < 0 {
+		return Value{}, fmt.Errorf("invalid array length: %d", length)
+	}
+	arrayType, err := jsArray.Get()
+	if err != nil {
+		return Value{}, err
+	}
+	return arrayType.New(length)
+}
+
+// ObjectOf returns a new JavaScript object with the given alternating keys and values.
+// Keys must be strings or Symbol Values. Values get mapped to JavaScript values according to the ValueOf function.
+//
+// For example:
+//
+//	obj, err := safejs.ObjectOf("name", "foo", "count", 1)
+//
+// Returns an error if kv has an odd length, a key is not a string or Symbol, or a value failed to map to a JavaScript value.
+func ObjectOf(kv ...any) (Value, error) {
+	if len(kv)%2 != 0 {
+		return Value{}, fmt.Errorf("odd number of key-value arguments: %d", len(kv))
+	}
+	obj, err := NewObject()
+	if err != nil {
+		return Value{}, err
+	}
+	for i := 0; i < len(kv); i += 2 {
+		switch key := kv[i].(type) {
+		case string:
+			err = obj.Set(key, kv[i+1])
+		case Value:
+			err = obj.SetSymbol(key, kv[i+1])
+		default:
+			err = fmt.Errorf("invalid object key type at index %d: %T", i, key)
+		}
+		if err != nil {
+			return Value{}, err
+		}
+	}
+	return obj, nil
+}
+
+// ArrayOf returns a new JavaScript array containing items. Equivalent to JavaScript's "Array.of(...items)".
+// Items get mapped to JavaScript values according to the ValueOf function.
+// Returns an error if an item failed to map to a JavaScript value.
+func ArrayOf(items ...any) (Value, error) {
+	arrayType, err := jsArray.Get()
+	if err != nil {
+		return Value{}, err
+	}
+	return arrayType.Call("of", items...)
+}
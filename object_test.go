@@ -0,0 +1,118 @@
+//go:build (js && wasm) || safejstest
+
+package safejs
+
+import (
+	"testing"
+
+	"github.com/hack-pad/safejs/internal/assert"
+)
+
+func TestNewObject(t *testing.T) {
+	t.Parallel()
+	obj, err := NewObject()
+	assert.NoError(t, err)
+	assert.Equal(t, TypeObject, obj.Type())
+
+	objectType, err := Global().Get("Object")
+	assert.NoError(t, err)
+	keys, err := objectType.Call("keys", obj)
+	assert.NoError(t, err)
+	length, err := keys.Length()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, length)
+
+	obj2, err := NewObject()
+	assert.NoError(t, err)
+	assert.Equal(t, false, obj.Equal(obj2))
+}
+
+func TestNewArray(t *testing.T) {
+	t.Parallel()
+	t.Run("valid length", func(t *testing.T) {
+		t.Parallel()
+		arr, err := NewArray(3)
+		assert.NoError(t, err)
+		length, err := arr.Length()
+		assert.NoError(t, err)
+		assert.Equal(t, 3, length)
+
+		arrayType, err := Global().Get("Array")
+		assert.NoError(t, err)
+		isArray, err := arrayType.Call("isArray", arr)
+		assert.NoError(t, err)
+		isArrayBool, err := isArray.Bool()
+		assert.NoError(t, err)
+		assert.Equal(t, true, isArrayBool)
+	})
+
+	t.Run("negative length", func(t *testing.T) {
+		t.Parallel()
+		_, err := NewArray(-1)
+		assert.EqualError(t, err, "invalid array length: -1")
+	})
+}
+
+func TestObjectOf(t *testing.T) {
+	t.Parallel()
+	t.Run("string and symbol keys", func(t *testing.T) {
+		t.Parallel()
+		symbol, err := NewSymbol("bar")
+		assert.NoError(t, err)
+		obj, err := ObjectOf("foo", 1, symbol, "baz")
+		assert.NoError(t, err)
+
+		foo, err := obj.Get("foo")
+		assert.NoError(t, err)
+		fooInt, err := foo.Int()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, fooInt)
+
+		bar, err := obj.GetSymbol(symbol)
+		assert.NoError(t, err)
+		barStr, err := bar.String()
+		assert.NoError(t, err)
+		assert.Equal(t, "baz", barStr)
+	})
+
+	t.Run("odd arguments", func(t *testing.T) {
+		t.Parallel()
+		_, err := ObjectOf("foo")
+		assert.EqualError(t, err, "odd number of key-value arguments: 1")
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		t.Parallel()
+		_, err := ObjectOf("foo", 1, 2, 3)
+		assert.EqualError(t, err, "invalid object key type at index 2: int")
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		t.Parallel()
+		_, err := ObjectOf("foo", []string{"bar"})
+		assert.EqualError(t, err, "ValueOf: invalid value")
+	})
+}
+
+func TestArrayOf(t *testing.T) {
+	t.Parallel()
+	arr, err := ArrayOf(1)
+	assert.NoError(t, err)
+	length, err := arr.Length()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, length)
+
+	arr, err = ArrayOf("foo", 2, Null())
+	assert.NoError(t, err)
+	length, err = arr.Length()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, length)
+	item, err := arr.Index(0)
+	assert.NoError(t, err)
+	itemStr, err := item.String()
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", itemStr)
+	item, err = arr.Index(2)
+	assert.NoError(t, err)
+	assert.Equal(t, true, item.IsNull())
+}
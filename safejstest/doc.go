@@ -0,0 +1,14 @@
+/*
+Package safejstest runs code using safejs under a plain "go test", without a JavaScript runtime.
+
+Build with the "safejstest" tag, like "go test -tags safejstest ./...", to replace [syscall/js] with an in-memory fake written in pure Go.
+The fake supports objects, arrays, functions, prototypes, instanceof, typed arrays, Symbols, and thrown errors, with the same panics as [syscall/js].
+Built-in globals include Object, Function, Array, Symbol, Reflect, the Error types, ArrayBuffer, DataView, and the typed array constructors.
+There is no DOM, Promise, or event loop, so install fakes for those with [SetGlobal].
+
+The fake's global object is shared by the whole test binary.
+
+To exercise error handling, [InjectFaults] makes selected safejs operations throw errors, like every call to a method named "getItem".
+It works with both the fake and real JavaScript runtimes.
+*/
+package safejstest
@@ -0,0 +1,189 @@
+//go:build (js && wasm) || safejstest
+
+package safejstest
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/hack-pad/safejs/internal/backend"
+	"github.com/hack-pad/safejs/internal/js"
+)
+
+// Rule selects safejs operations to fail with [InjectFaults].
+type Rule struct {
+	// Op matches operations by the name of the underlying [syscall/js] function or Value method, like "Get", "Call", "Invoke", "New", "ValueOf", or "FuncOf".
+	// These aren't always the names of the safejs functions which run them. For example,
+	// [safejs.Value.Apply] runs "Call" with the name "apply" on Reflect,
+	// [safejs.Value.GetSymbol] runs "Call" with the name "get" on Reflect,
+	// [safejs.NewObject] runs "New",
+	// and [safejs.CopyToGo] runs "CopyBytesToGo".
+	// Matches every operation if empty.
+	Op string
+	// Name matches the property name of "Get", "Set", and "Delete", or the method name of "Call".
+	// Matches every name if empty.
+	Name string
+	// Every fails only every Nth matching operation, like 3 to fail the 3rd, 6th, and so on. Fails every matching operation if 0 or 1.
+	Every int
+	// TypeError throws a TypeError instead of an Error.
+	TypeError bool
+	// Message is the thrown error's message. Defaults to "injected fault" with the operation and name.
+	Message string
+}
+
+// InjectFaults makes safejs operations matching any of rules throw a JavaScript error, until the test finishes.
+// Use it to exercise error handling which real JavaScript runtimes rarely trigger.
+//
+// Since faults apply to every goroutine, tests using InjectFaults should not run in parallel.
+// Globals which safejs uses internally, like Reflect and Uint8Array, are cached after their first successful lookup, so faults in those lookups may not trigger.
+func InjectFaults(tb testing.TB, rules ...Rule) {
+	tb.Helper()
+	faults := &faultBackend{
+		Backend: backend.Current(),
+		rules:   rules,
+		counts:  make([]int, len(rules)),
+	}
+	previous := backend.Set(faults)
+	tb.Cleanup(func() {
+		backend.Set(previous)
+	})
+}
+
+// faultBackend wraps a Backend, throwing errors from operations matching its rules
+type faultBackend struct {
+	backend.Backend
+	mu     sync.Mutex
+	rules  []Rule
+	counts []int
+}
+
+// fault throws a JavaScript error if op and name match a rule
+func (b *faultBackend) fault(op, name string) {
+	rule, matched := b.match(op, name)
+	if !matched {
+		return
+	}
+	message := rule.Message
+	if message == "" {
+		message = "injected fault: " + op
+		if name != "" {
+			message += " " + name
+		}
+	}
+	errorType := "Error"
+	if rule.TypeError {
+		errorType = "TypeError"
+	}
+	global := b.Backend.Global()
+	panic(js.Error{Value: b.Backend.New(b.Backend.Get(global, errorType), message)})
+}
+
+func (b *faultBackend) match(op, name string) (Rule, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, rule := range b.rules {
+		if (rule.Op != "" && rule.Op != op) || (rule.Name != "" && rule.Name != name) {
+			continue
+		}
+		b.counts[i]++
+		if rule.Every <= 1 || b.counts[i]%rule.Every == 0 {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+func (b *faultBackend) ValueOf(x any) js.Value {
+	b.fault("ValueOf", "")
+	return b.Backend.ValueOf(x)
+}
+
+func (b *faultBackend) FuncOf(fn func(this js.Value, args []js.Value) any) js.Func {
+	b.fault("FuncOf", "")
+	return b.Backend.FuncOf(fn)
+}
+
+func (b *faultBackend) CopyBytesToGo(dst []byte, src js.Value) int {
+	b.fault("CopyBytesToGo", "")
+	return b.Backend.CopyBytesToGo(dst, src)
+}
+
+func (b *faultBackend) CopyBytesToJS(dst js.Value, src []byte) int {
+	b.fault("CopyBytesToJS", "")
+	return b.Backend.CopyBytesToJS(dst, src)
+}
+
+func (b *faultBackend) Get(v js.Value, p string) js.Value {
+	b.fault("Get", p)
+	return b.Backend.Get(v, p)
+}
+
+func (b *faultBackend) Set(v js.Value, p string, x any) {
+	b.fault("Set", p)
+	b.Backend.Set(v, p, x)
+}
+
+func (b *faultBackend) Delete(v js.Value, p string) {
+	b.fault("Delete", p)
+	b.Backend.Delete(v, p)
+}
+
+func (b *faultBackend) Index(v js.Value, i int) js.Value {
+	b.fault("Index", "")
+	return b.Backend.Index(v, i)
+}
+
+func (b *faultBackend) SetIndex(v js.Value, i int, x any) {
+	b.fault("SetIndex", "")
+	b.Backend.SetIndex(v, i, x)
+}
+
+func (b *faultBackend) Length(v js.Value) int {
+	b.fault("Length", "")
+	return b.Backend.Length(v)
+}
+
+func (b *faultBackend) Call(v js.Value, m string, args ...any) js.Value {
+	b.fault("Call", m)
+	return b.Backend.Call(v, m, args...)
+}
+
+func (b *faultBackend) Invoke(v js.Value, args ...any) js.Value {
+	b.fault("Invoke", "")
+	return b.Backend.Invoke(v, args...)
+}
+
+func (b *faultBackend) New(v js.Value, args ...any) js.Value {
+	b.fault("New", "")
+	return b.Backend.New(v, args...)
+}
+
+func (b *faultBackend) InstanceOf(v, t js.Value) bool {
+	b.fault("InstanceOf", "")
+	return b.Backend.InstanceOf(v, t)
+}
+
+func (b *faultBackend) Bool(v js.Value) bool {
+	b.fault("Bool", "")
+	return b.Backend.Bool(v)
+}
+
+func (b *faultBackend) Float(v js.Value) float64 {
+	b.fault("Float", "")
+	return b.Backend.Float(v)
+}
+
+func (b *faultBackend) Int(v js.Value) int {
+	b.fault("Int", "")
+	return b.Backend.Int(v)
+}
+
+func (b *faultBackend) String(v js.Value) string {
+	b.fault("String", "")
+	return b.Backend.String(v)
+}
+
+func (b *faultBackend) Truthy(v js.Value) bool {
+	b.fault("Truthy", "")
+	return b.Backend.Truthy(v)
+}
@@ -0,0 +1,63 @@
+//go:build (js && wasm) || safejstest
+
+package safejstest
+
+import (
+	"testing"
+
+	"github.com/hack-pad/safejs"
+	"github.com/hack-pad/safejs/internal/assert"
+)
+
+func TestInjectFaults(t *testing.T) { //nolint:paralleltest // Injects faults for all tests
+	obj, err := safejs.ObjectOf("foo", 1, "bar", 2)
+	assert.NoError(t, err)
+
+	t.Run("match operation and name", func(t *testing.T) {
+		InjectFaults(t, Rule{Op: "Get", Name: "foo"})
+		_, err := obj.Get("foo")
+		assert.EqualError(t, err, "JavaScript error: injected fault: Get foo")
+		_, err = obj.Get("bar")
+		assert.NoError(t, err)
+		err = obj.Set("foo", 3)
+		assert.NoError(t, err)
+	})
+
+	t.Run("match every Nth call", func(t *testing.T) {
+		InjectFaults(t, Rule{Op: "Call", Every: 2})
+		_, err := obj.Call("hasOwnProperty", "foo")
+		assert.NoError(t, err)
+		_, err = obj.Call("hasOwnProperty", "foo")
+		assert.EqualError(t, err, "JavaScript error: injected fault: Call hasOwnProperty")
+		_, err = obj.Call("hasOwnProperty", "foo")
+		assert.NoError(t, err)
+	})
+
+	t.Run("match underlying operation", func(t *testing.T) {
+		hasOwnProperty, err := obj.Get("hasOwnProperty")
+		assert.NoError(t, err)
+		InjectFaults(t, Rule{Op: "Call", Name: "apply"})
+		_, err = hasOwnProperty.Apply(obj, "foo")
+		assert.EqualError(t, err, "JavaScript error: injected fault: Call apply")
+	})
+
+	t.Run("type error with message", func(t *testing.T) {
+		InjectFaults(t, Rule{Op: "ValueOf", TypeError: true, Message: "some error"})
+		_, err := safejs.ValueOf(1)
+		assert.EqualError(t, err, "JavaScript error: some error")
+	})
+
+	t.Run("match all", func(t *testing.T) {
+		InjectFaults(t, Rule{})
+		_, err := safejs.NewObject()
+		assert.EqualError(t, err, "JavaScript error: injected fault: New")
+		_, err = obj.Length()
+		assert.EqualError(t, err, "JavaScript error: injected fault: Length")
+	})
+
+	value, err := obj.Get("foo")
+	assert.NoError(t, err)
+	foo, err := value.Int()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, foo)
+}
@@ -0,0 +1,46 @@
+//go:build !js && safejstest
+
+package safejstest
+
+import (
+	"testing"
+
+	"github.com/hack-pad/safejs"
+	"github.com/hack-pad/safejs/internal/js"
+)
+
+// Throw throws value as a JavaScript exception. Call it inside a [safejs.FuncOf] callback to make the function throw, like a fake browser API which fails.
+func Throw(value safejs.Value) {
+	panic(js.Error{Value: safejs.Unsafe(value)})
+}
+
+// SetGlobal sets the global property name to value for the rest of the test, then restores the previous value during cleanup.
+//
+// Since globals are shared, tests using SetGlobal for the same name should not run in parallel.
+// Globals read with [safejs.MustGetGlobal] are cached, so set them before their first use.
+func SetGlobal(tb testing.TB, name string, value any) {
+	tb.Helper()
+	global := safejs.Global()
+	existed, err := global.Call("hasOwnProperty", name)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	previous, err := global.Get(name)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if err := global.Set(name, value); err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() {
+		var err error
+		if exists, _ := existed.Bool(); exists {
+			err = global.Set(name, previous)
+		} else {
+			err = global.Delete(name)
+		}
+		if err != nil {
+			tb.Error(err)
+		}
+	})
+}
@@ -0,0 +1,53 @@
+//go:build !js && safejstest
+
+package safejstest
+
+import (
+	"testing"
+
+	"github.com/hack-pad/safejs"
+	"github.com/hack-pad/safejs/internal/assert"
+)
+
+func TestThrow(t *testing.T) {
+	t.Parallel()
+	fn, err := safejs.FuncOf(func(this safejs.Value, args []safejs.Value) any {
+		Throw(args[0])
+		return nil
+	})
+	assert.NoError(t, err)
+	defer fn.Release()
+
+	errorType, err := safejs.Global().Get("Error")
+	assert.NoError(t, err)
+	jsErr, err := errorType.New("some error")
+	assert.NoError(t, err)
+
+	_, err = fn.Value().Invoke(jsErr)
+	assert.EqualError(t, err, "JavaScript error: some error")
+}
+
+func TestSetGlobal(t *testing.T) { //nolint:paralleltest // Modifies globals
+	const name = "safejstestGlobal"
+	t.Run("set", func(t *testing.T) {
+		SetGlobal(t, name, "foo")
+		value, err := safejs.Global().Get(name)
+		assert.NoError(t, err)
+		str, err := value.String()
+		assert.NoError(t, err)
+		assert.Equal(t, "foo", str)
+	})
+
+	hasGlobal, err := safejs.Global().Call("hasOwnProperty", name)
+	assert.NoError(t, err)
+	exists, err := hasGlobal.Bool()
+	assert.NoError(t, err)
+	assert.Equal(t, false, exists)
+
+	t.Run("restore", func(t *testing.T) {
+		SetGlobal(t, "NaN", 1)
+	})
+	value, err := safejs.Global().Get("NaN")
+	assert.NoError(t, err)
+	assert.Equal(t, true, value.IsNaN())
+}
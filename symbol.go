@@ -0,0 +1,157 @@
+package safejs
+
+import (
+	"errors"
+	"fmt"
+)
+
+// NewSymbol returns a new, unique JavaScript Symbol with the given description.
+// Equivalent to JavaScript's Symbol(description).
+func NewSymbol(description string) (Value, error) {
+	symbolType, err := jsSymbol.Get()
+	if err != nil {
+		return Value{}, err
+	}
+	return symbolType.Invoke(description)
+}
+
+// SymbolFor returns the JavaScript Symbol for key from the global Symbol registry, creating it if needed.
+// Equivalent to JavaScript's Symbol.for(key).
+func SymbolFor(key string) (Value, error) {
+	symbolType, err := jsSymbol.Get()
+	if err != nil {
+		return Value{}, err
+	}
+	return symbolType.Call("for", key)
+}
+
+func wellKnownSymbol(name string) (Value, error) {
+	symbolType, err := jsSymbol.Get()
+	if err != nil {
+		return Value{}, err
+	}
+	symbol, err := symbolType.Get(name)
+	if err != nil {
+		return Value{}, err
+	}
+	if symbol.Type() != TypeSymbol {
+		return Value{}, fmt.Errorf("well-known symbol Symbol.%s is not supported: %v", name, symbol.Type())
+	}
+	return symbol, nil
+}
+
+// SymbolAsyncIterator returns the well-known symbol Symbol.asyncIterator.
+func SymbolAsyncIterator() (Value, error) {
+	return wellKnownSymbol("asyncIterator")
+}
+
+// SymbolHasInstance returns the well-known symbol Symbol.hasInstance.
+func SymbolHasInstance() (Value, error) {
+	return wellKnownSymbol("hasInstance")
+}
+
+// SymbolIsConcatSpreadable returns the well-known symbol Symbol.isConcatSpreadable.
+func SymbolIsConcatSpreadable() (Value, error) {
+	return wellKnownSymbol("isConcatSpreadable")
+}
+
+// SymbolIterator returns the well-known symbol Symbol.iterator.
+func SymbolIterator() (Value, error) {
+	return wellKnownSymbol("iterator")
+}
+
+// SymbolMatch returns the well-known symbol Symbol.match.
+func SymbolMatch() (Value, error) {
+	return wellKnownSymbol("match")
+}
+
+// SymbolMatchAll returns the well-known symbol Symbol.matchAll.
+func SymbolMatchAll() (Value, error) {
+	return wellKnownSymbol("matchAll")
+}
+
+// SymbolReplace returns the well-known symbol Symbol.replace.
+func SymbolReplace() (Value, error) {
+	return wellKnownSymbol("replace")
+}
+
+// SymbolSearch returns the well-known symbol Symbol.search.
+func SymbolSearch() (Value, error) {
+	return wellKnownSymbol("search")
+}
+
+// SymbolSpecies returns the well-known symbol Symbol.species.
+func SymbolSpecies() (Value, error) {
+	return wellKnownSymbol("species")
+}
+
+// SymbolSplit returns the well-known symbol Symbol.split.
+func SymbolSplit() (Value, error) {
+	return wellKnownSymbol("split")
+}
+
+// SymbolToPrimitive returns the well-known symbol Symbol.toPrimitive.
+func SymbolToPrimitive() (Value, error) {
+	return wellKnownSymbol("toPrimitive")
+}
+
+// SymbolToStringTag returns the well-known symbol Symbol.toStringTag.
+func SymbolToStringTag() (Value, error) {
+	return wellKnownSymbol("toStringTag")
+}
+
+// SymbolUnscopables returns the well-known symbol Symbol.unscopables.
+func SymbolUnscopables() (Value, error) {
+	return wellKnownSymbol("unscopables")
+}
+
+// reflectSymbolCall runs JavaScript's Reflect[method](v, sym, args...) after verifying sym is a Symbol.
+// Reflect throws a TypeError if v is not an object, which is returned as an error.
+func reflectSymbolCall(method string, v, sym Value, args ...any) (Value, error) {
+	if sym.Type() != TypeSymbol {
+		return Value{}, fmt.Errorf("invalid type for symbol key: %v", sym.Type())
+	}
+	reflect, err := jsReflect.Get()
+	if err != nil {
+		return Value{}, err
+	}
+	return reflect.Call(method, append([]any{v, sym}, args...)...)
+}
+
+// GetSymbol returns the JavaScript property keyed by the Symbol sym of value v.
+// Returns an error if sym is not a Symbol or v is not a JavaScript object.
+func (v Value) GetSymbol(sym Value) (Value, error) {
+	return reflectSymbolCall("get", v, sym)
+}
+
+// SetSymbol sets the JavaScript property keyed by the Symbol sym of value v to ValueOf(x).
+// Returns an error if sym is not a Symbol, v is not a JavaScript object, x failed to map to a JavaScript value, or the property could not be set, like on a frozen object.
+func (v Value) SetSymbol(sym Value, x any) error {
+	result, err := reflectSymbolCall("set", v, sym, x)
+	if err != nil {
+		return err
+	}
+	return reflectSymbolResult(result, "failed to set Symbol property: property is read-only or object is not extensible")
+}
+
+// DeleteSymbol deletes the JavaScript property keyed by the Symbol sym of value v.
+// Returns an error if sym is not a Symbol, v is not a JavaScript object, or the property could not be deleted, like a non-configurable property.
+func (v Value) DeleteSymbol(sym Value) error {
+	result, err := reflectSymbolCall("deleteProperty", v, sym)
+	if err != nil {
+		return err
+	}
+	return reflectSymbolResult(result, "failed to delete Symbol property: property is not configurable")
+}
+
+// reflectSymbolResult returns an error with message if Reflect's boolean result is false
+func reflectSymbolResult(result Value, message string) error {
+	ok, err := result.Bool()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New(message)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+//go:build (js && wasm) || safejstest
+
+package safejs
+
+import (
+	"testing"
+
+	"github.com/hack-pad/safejs/internal/assert"
+)
+
+func TestNewSymbol(t *testing.T) {
+	t.Parallel()
+	symbol1, err := NewSymbol("foo")
+	assert.NoError(t, err)
+	symbol2, err := NewSymbol("foo")
+	assert.NoError(t, err)
+
+	assert.Equal(t, TypeSymbol, symbol1.Type())
+	assert.Equal(t, false, symbol1.Equal(symbol2))
+}
+
+func TestSymbolFor(t *testing.T) {
+	t.Parallel()
+	symbol1, err := SymbolFor("foo")
+	assert.NoError(t, err)
+	symbol2, err := SymbolFor("foo")
+	assert.NoError(t, err)
+
+	assert.Equal(t, TypeSymbol, symbol1.Type())
+	assert.Equal(t, true, symbol1.Equal(symbol2))
+}
+
+func TestWellKnownSymbols(t *testing.T) {
+	t.Parallel()
+	for name, fn := range map[string]func() (Value, error){
+		"asyncIterator":      SymbolAsyncIterator,
+		"hasInstance":        SymbolHasInstance,
+		"isConcatSpreadable": SymbolIsConcatSpreadable,
+		"iterator":           SymbolIterator,
+		"match":              SymbolMatch,
+		"matchAll":           SymbolMatchAll,
+		"replace":            SymbolReplace,
+		"search":             SymbolSearch,
+		"species":            SymbolSpecies,
+		"split":              SymbolSplit,
+		"toPrimitive":        SymbolToPrimitive,
+		"toStringTag":        SymbolToStringTag,
+		"unscopables":        SymbolUnscopables,
+	} {
+		name, fn := name, fn
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			symbol, err := fn()
+			assert.NoError(t, err)
+			jsSymbol, err := Global().Get("Symbol")
+			assert.NoError(t, err)
+			expected, err := jsSymbol.Get(name)
+			assert.NoError(t, err)
+			assert.Equal(t, true, expected.Equal(symbol))
+		})
+	}
+}
+
+func TestValueGetSymbol(t *testing.T) {
+	t.Parallel()
+	t.Run("well-known symbol", func(t *testing.T) {
+		t.Parallel()
+		arr, err := ValueOf([]any{1, 2, 3})
+		assert.NoError(t, err)
+		iterator, err := SymbolIterator()
+		assert.NoError(t, err)
+
+		iteratorFn, err := arr.GetSymbol(iterator)
+		assert.NoError(t, err)
+		assert.Equal(t, TypeFunction, iteratorFn.Type())
+	})
+
+	t.Run("not a symbol", func(t *testing.T) {
+		t.Parallel()
+		obj, err := ValueOf(map[string]any{})
+		assert.NoError(t, err)
+		key, err := ValueOf("foo")
+		assert.NoError(t, err)
+
+		_, err = obj.GetSymbol(key)
+		assert.EqualError(t, err, "invalid type for symbol key: string")
+	})
+
+	t.Run("not an object", func(t *testing.T) {
+		t.Parallel()
+		symbol, err := NewSymbol("foo")
+		assert.NoError(t, err)
+
+		_, err = Undefined().GetSymbol(symbol)
+		assert.Equal(t, true, err != nil)
+	})
+}
+
+func TestValueSetSymbol(t *testing.T) {
+	t.Parallel()
+	obj, err := ValueOf(map[string]any{})
+	assert.NoError(t, err)
+	toStringTag, err := SymbolToStringTag()
+	assert.NoError(t, err)
+
+	err = obj.SetSymbol(toStringTag, "Foo")
+	assert.NoError(t, err)
+
+	objectType, err := Global().Get("Object")
+	assert.NoError(t, err)
+	prototype, err := objectType.Get("prototype")
+	assert.NoError(t, err)
+	toString, err := prototype.Get("toString")
+	assert.NoError(t, err)
+	result, err := toString.Call("call", obj)
+	assert.NoError(t, err)
+	resultStr, err := result.String()
+	assert.NoError(t, err)
+	assert.Equal(t, "[object Foo]", resultStr)
+
+	err = Undefined().SetSymbol(toStringTag, "Foo")
+	assert.Equal(t, true, err != nil)
+}
+
+func TestValueDeleteSymbol(t *testing.T) {
+	t.Parallel()
+	obj, err := ValueOf(map[string]any{})
+	assert.NoError(t, err)
+	symbol, err := SymbolFor("foo")
+	assert.NoError(t, err)
+
+	assert.NoError(t, obj.SetSymbol(symbol, 1))
+	value, err := obj.GetSymbol(symbol)
+	assert.NoError(t, err)
+	valueInt, err := value.Int()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, valueInt)
+
+	assert.NoError(t, obj.DeleteSymbol(symbol))
+	value, err = obj.GetSymbol(symbol)
+	assert.NoError(t, err)
+	assert.Equal(t, true, value.IsUndefined())
+}
+
+func TestValueSymbolFrozenObject(t *testing.T) {
+	t.Parallel()
+	symbol, err := SymbolFor("foo")
+	assert.NoError(t, err)
+	obj, err := ValueOf(map[string]any{})
+	assert.NoError(t, err)
+	assert.NoError(t, obj.SetSymbol(symbol, 1))
+	objectType, err := Global().Get("Object")
+	assert.NoError(t, err)
+	_, err = objectType.Call("freeze", obj)
+	assert.NoError(t, err)
+
+	err = obj.SetSymbol(symbol, 2)
+	assert.EqualError(t, err, "failed to set Symbol property: property is read-only or object is not extensible")
+	err = obj.DeleteSymbol(symbol)
+	assert.EqualError(t, err, "failed to delete Symbol property: property is not configurable")
+
+	value, err := obj.GetSymbol(symbol)
+	assert.NoError(t, err)
+	valueInt, err := value.Int()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, valueInt)
+}
@@ -1,8 +1,6 @@
-//go:build js && wasm
-
 package safejs
 
-import "syscall/js"
+import "github.com/hack-pad/safejs/internal/js"
 
 // Type represents the JavaScript type of a Value.
 type Type int
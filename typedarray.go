@@ -0,0 +1,240 @@
+package safejs
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"github.com/hack-pad/safejs/internal/backend"
+	"github.com/hack-pad/safejs/internal/catch"
+)
+
+var (
+	jsInt8Array         = newCachedGlobal("Int8Array")
+	jsInt16Array        = newCachedGlobal("Int16Array")
+	jsInt32Array        = newCachedGlobal("Int32Array")
+	jsBigInt64Array     = newCachedGlobal("BigInt64Array")
+	jsUint8Array        = newCachedGlobal("Uint8Array")
+	jsUint8ClampedArray = newCachedGlobal("Uint8ClampedArray")
+	jsUint16Array       = newCachedGlobal("Uint16Array")
+	jsUint32Array       = newCachedGlobal("Uint32Array")
+	jsBigUint64Array    = newCachedGlobal("BigUint64Array")
+	jsFloat32Array      = newCachedGlobal("Float32Array")
+	jsFloat64Array      = newCachedGlobal("Float64Array")
+)
+
+// TypedArrayElement is a Go numeric type with a matching JavaScript TypedArray.
+//
+// For example, float32 matches Float32Array and int64 matches BigInt64Array.
+type TypedArrayElement interface {
+	int8 | int16 | int32 | int64 |
+		uint8 | uint16 | uint32 | uint64 |
+		float32 | float64
+}
+
+// CopyToGo copies elements from the TypedArray src to dst.
+// Returns the number of elements copied, which is the minimum of the lengths of src and dst.
+// Returns an error if src is not a TypedArray matching T, like a Float32Array for []float32.
+//
+// The copy is done as a single byte-level copy over src's underlying buffer.
+func CopyToGo[T TypedArrayElement](dst []T, src Value) (int, error) {
+	srcBytes, n, err := typedArrayBytes[T]("CopyToGo", "src", src, len(dst))
+	if err != nil || n == 0 {
+		return 0, err
+	}
+	_, err = catch.Try(func() int {
+		return backend.Current().CopyBytesToGo(sliceBytes(dst[:n]), srcBytes.jsValue)
+	})
+	if err != nil {
+		return 0, err
+	}
+	littleEndian, err := isLittleEndian()
+	if err != nil {
+		return 0, err
+	}
+	if !littleEndian {
+		swapBytes(dst[:n])
+	}
+	return n, nil
+}
+
+// CopyToJS copies elements from src to the TypedArray dst.
+// Returns the number of elements copied, which is the minimum of the lengths of src and dst.
+// Returns an error if dst is not a TypedArray matching T, like a Float32Array for []float32.
+//
+// The copy is done as a single byte-level copy over dst's underlying buffer.
+func CopyToJS[T TypedArrayElement](dst Value, src []T) (int, error) {
+	dstBytes, n, err := typedArrayBytes[T]("CopyToJS", "dst", dst, len(src))
+	if err != nil || n == 0 {
+		return 0, err
+	}
+	littleEndian, err := isLittleEndian()
+	if err != nil {
+		return 0, err
+	}
+	src = src[:n]
+	if !littleEndian {
+		src = append([]T(nil), src...)
+		swapBytes(src)
+	}
+	_, err = catch.Try(func() int {
+		return backend.Current().CopyBytesToJS(dstBytes.jsValue, sliceBytes(src))
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// typedArrayConstructors returns the TypedArray constructors with elements matching T
+func typedArrayConstructors[T TypedArrayElement]() []*cachedGlobal {
+	var zero T
+	switch any(zero).(type) {
+	case int8:
+		return []*cachedGlobal{jsInt8Array}
+	case int16:
+		return []*cachedGlobal{jsInt16Array}
+	case int32:
+		return []*cachedGlobal{jsInt32Array}
+	case int64:
+		return []*cachedGlobal{jsBigInt64Array}
+	case uint8:
+		return []*cachedGlobal{jsUint8Array, jsUint8ClampedArray}
+	case uint16:
+		return []*cachedGlobal{jsUint16Array}
+	case uint32:
+		return []*cachedGlobal{jsUint32Array}
+	case uint64:
+		return []*cachedGlobal{jsBigUint64Array}
+	case float32:
+		return []*cachedGlobal{jsFloat32Array}
+	case float64:
+		return []*cachedGlobal{jsFloat64Array}
+	default:
+		panic(fmt.Sprintf("unsupported typed array element type: %T", zero)) // unreachable: T is constrained to TypedArrayElement
+	}
+}
+
+// typedArrayBytes verifies array is a TypedArray matching T, then returns a Uint8Array over array's first 'n' elements.
+// 'n' is the minimum of array's length and maxLength.
+func typedArrayBytes[T TypedArrayElement](funcName, argName string, array Value, maxLength int) (Value, int, error) {
+	constructors := typedArrayConstructors[T]()
+	var names []string
+	isMatch := false
+	for _, constructor := range constructors {
+		names = append(names, constructor.property)
+		isInstance, err := instanceOfGlobal(array, constructor)
+		if err != nil {
+			return Value{}, 0, err
+		}
+		if isInstance {
+			isMatch = true
+			break
+		}
+	}
+	if !isMatch {
+		return Value{}, 0, fmt.Errorf("%s: expected %s to be a %s", funcName, argName, strings.Join(names, " or "))
+	}
+
+	length, err := array.Length()
+	if err != nil {
+		return Value{}, 0, err
+	}
+	n := length
+	if maxLength < n {
+		n = maxLength
+	}
+	buffer, err := array.Get("buffer")
+	if err != nil {
+		return Value{}, 0, err
+	}
+	byteOffset, err := array.Get("byteOffset")
+	if err != nil {
+		return Value{}, 0, err
+	}
+	uint8Array, err := jsUint8Array.Get()
+	if err != nil {
+		return Value{}, 0, err
+	}
+	var zero T
+	arrayBytes, err := uint8Array.New(buffer, byteOffset, n*int(unsafe.Sizeof(zero)))
+	return arrayBytes, n, err
+}
+
+// sliceBytes returns the memory backing s as a byte slice
+func sliceBytes[T TypedArrayElement](s []T) []byte {
+	if len(s) == 0 {
+		return nil
+	}
+	return unsafe.Slice((*byte)(unsafe.Pointer(&s[0])), len(s)*int(unsafe.Sizeof(s[0])))
+}
+
+// swapBytes reverses the byte order of each element in s
+func swapBytes[T TypedArrayElement](s []T) {
+	var zero T
+	size := int(unsafe.Sizeof(zero))
+	b := sliceBytes(s)
+	for i := 0; i < len(b); i += size {
+		elem := b[i : i+size]
+		for j, k := 0, size-1; j < k; j, k = j+1, k-1 {
+			elem[j], elem[k] = elem[k], elem[j]
+		}
+	}
+}
+
+var littleEndian struct {
+	mu    sync.Mutex
+	value bool
+	ok    bool
+}
+
+// isLittleEndian reports whether the JavaScript runtime's TypedArrays are little-endian, matching WebAssembly's memory layout.
+// TypedArrays use the host's byte order, so this is true on virtually all platforms.
+//
+// Only successful checks are cached, so a transient failure is retried on the next call.
+func isLittleEndian() (bool, error) {
+	littleEndian.mu.Lock()
+	defer littleEndian.mu.Unlock()
+	if littleEndian.ok {
+		return littleEndian.value, nil
+	}
+	value, err := checkLittleEndian()
+	if err != nil {
+		return false, err
+	}
+	littleEndian.value, littleEndian.ok = value, true
+	return value, nil
+}
+
+func checkLittleEndian() (bool, error) {
+	uint16Array, err := jsUint16Array.Get()
+	if err != nil {
+		return false, err
+	}
+	uint8Array, err := jsUint8Array.Get()
+	if err != nil {
+		return false, err
+	}
+	value, err := uint16Array.New(1)
+	if err != nil {
+		return false, err
+	}
+	if err := value.SetIndex(0, 0x0102); err != nil {
+		return false, err
+	}
+	buffer, err := value.Get("buffer")
+	if err != nil {
+		return false, err
+	}
+	valueBytes, err := uint8Array.New(buffer)
+	if err != nil {
+		return false, err
+	}
+	firstByte, err := valueBytes.Index(0)
+	if err != nil {
+		return false, err
+	}
+	firstByteInt, err := firstByte.Int()
+	return firstByteInt == 0x02, err
+}
@@ -0,0 +1,147 @@
+//go:build (js && wasm) || safejstest
+
+package safejs
+
+import (
+	"testing"
+
+	"github.com/hack-pad/safejs/internal/assert"
+)
+
+func newTypedArray(t *testing.T, className string, args ...any) Value {
+	t.Helper()
+	constructor, err := Global().Get(className)
+	assert.NoError(t, err)
+	array, err := constructor.New(args...)
+	assert.NoError(t, err)
+	return array
+}
+
+func TestCopyToGo(t *testing.T) {
+	t.Parallel()
+	t.Run("float32", func(t *testing.T) {
+		t.Parallel()
+		src := newTypedArray(t, "Float32Array", []any{1.5, -2.25, 3})
+		dst := make([]float32, 3)
+		n, err := CopyToGo(dst, src)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, n)
+		assert.Equal(t, []float32{1.5, -2.25, 3}, dst)
+	})
+
+	t.Run("partial dst", func(t *testing.T) {
+		t.Parallel()
+		src := newTypedArray(t, "Int16Array", []any{1, -2, 3, -4})
+		dst := make([]int16, 2)
+		n, err := CopyToGo(dst, src)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+		assert.Equal(t, []int16{1, -2}, dst)
+	})
+
+	t.Run("partial src with offset", func(t *testing.T) {
+		t.Parallel()
+		full := newTypedArray(t, "Uint32Array", []any{1, 2, 3, 4})
+		src, err := full.Call("subarray", 1, 3)
+		assert.NoError(t, err)
+		dst := []uint32{0, 0, 0, 9}
+		n, err := CopyToGo(dst, src)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+		assert.Equal(t, []uint32{2, 3, 0, 9}, dst)
+	})
+
+	t.Run("uint8 clamped", func(t *testing.T) {
+		t.Parallel()
+		src := newTypedArray(t, "Uint8ClampedArray", []any{1, 300})
+		dst := make([]uint8, 2)
+		n, err := CopyToGo(dst, src)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+		assert.Equal(t, []uint8{1, 255}, dst)
+	})
+
+	t.Run("empty dst", func(t *testing.T) {
+		t.Parallel()
+		src := newTypedArray(t, "Float64Array", 2)
+		n, err := CopyToGo([]float64(nil), src)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, n)
+	})
+
+	t.Run("wrong element type", func(t *testing.T) {
+		t.Parallel()
+		src := newTypedArray(t, "Float64Array", 2)
+		_, err := CopyToGo(make([]float32, 2), src)
+		assert.EqualError(t, err, "CopyToGo: expected src to be a Float32Array")
+
+		_, err = CopyToGo(make([]uint8, 2), Undefined())
+		assert.EqualError(t, err, "CopyToGo: expected src to be a Uint8Array or Uint8ClampedArray")
+	})
+}
+
+func TestCopyToJS(t *testing.T) {
+	t.Parallel()
+	t.Run("float64", func(t *testing.T) {
+		t.Parallel()
+		dst := newTypedArray(t, "Float64Array", 3)
+		n, err := CopyToJS(dst, []float64{0.5, -1, 1e100})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, n)
+
+		result := make([]float64, 3)
+		_, err = CopyToGo(result, dst)
+		assert.NoError(t, err)
+		assert.Equal(t, []float64{0.5, -1, 1e100}, result)
+
+		elem, err := dst.Index(1)
+		assert.NoError(t, err)
+		elemFloat, err := elem.Float()
+		assert.NoError(t, err)
+		assert.Equal(t, -1.0, elemFloat)
+	})
+
+	t.Run("partial src", func(t *testing.T) {
+		t.Parallel()
+		dst := newTypedArray(t, "Int32Array", 4)
+		n, err := CopyToJS(dst, []int32{-7, 8})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+
+		result := make([]int32, 4)
+		_, err = CopyToGo(result, dst)
+		assert.NoError(t, err)
+		assert.Equal(t, []int32{-7, 8, 0, 0}, result)
+	})
+
+	t.Run("partial dst", func(t *testing.T) {
+		t.Parallel()
+		dst := newTypedArray(t, "BigInt64Array", 1)
+		n, err := CopyToJS(dst, []int64{-1 << 40, 2})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+
+		result := make([]int64, 1)
+		_, err = CopyToGo(result, dst)
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{-1 << 40}, result)
+	})
+
+	t.Run("wrong element type", func(t *testing.T) {
+		t.Parallel()
+		dst := newTypedArray(t, "Int16Array", 2)
+		_, err := CopyToJS(dst, []uint16{1, 2})
+		assert.EqualError(t, err, "CopyToJS: expected dst to be a Uint16Array")
+	})
+}
+
+func TestSwapBytes(t *testing.T) {
+	t.Parallel()
+	values := []uint32{0x01020304, 0x0a0b0c0d}
+	swapBytes(values)
+	assert.Equal(t, []uint32{0x04030201, 0x0d0c0b0a}, values)
+
+	bytes := []uint8{1, 2}
+	swapBytes(bytes)
+	assert.Equal(t, []uint8{1, 2}, bytes)
+}
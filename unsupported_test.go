@@ -0,0 +1,59 @@
+//go:build !js && !safejstest
+
+package safejs
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hack-pad/safejs/internal/assert"
+)
+
+func TestUnsupported(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, TypeUndefined, Global().Type())
+	assert.Equal(t, true, Global().IsUndefined())
+	assert.Equal(t, true, Null().IsNull())
+	assert.Equal(t, true, Null().Equal(Null()))
+
+	for _, tc := range []struct {
+		name string
+		fn   func() error
+	}{
+		{name: "Global().Get", fn: func() error {
+			_, err := Global().Get("Uint8Array")
+			return err
+		}},
+		{name: "ValueOf", fn: func() error {
+			_, err := ValueOf("foo")
+			return err
+		}},
+		{name: "FuncOf", fn: func() error {
+			_, err := FuncOf(func(this Value, args []Value) any { return nil })
+			return err
+		}},
+		{name: "NewObject", fn: func() error {
+			_, err := NewObject()
+			return err
+		}},
+		{name: "CopyBytesToJS", fn: func() error {
+			_, err := CopyBytesToJS(Undefined(), []byte("foo"))
+			return err
+		}},
+		{name: "Value.String", fn: func() error {
+			_, err := Undefined().String()
+			return err
+		}},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := tc.fn()
+			if !errors.Is(err, ErrUnsupported) {
+				t.Errorf("Expected ErrUnsupported, got: %v", err)
+			}
+		})
+	}
+
+	assert.Equal(t, "safejs: JavaScript is not supported on this platform", ErrUnsupported.Error())
+}
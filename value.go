@@ -1,12 +1,11 @@
-//go:build js && wasm
-
 package safejs
 
 import (
 	"fmt"
-	"syscall/js"
 
+	"github.com/hack-pad/safejs/internal/backend"
 	"github.com/hack-pad/safejs/internal/catch"
+	"github.com/hack-pad/safejs/internal/js"
 )
 
 // Value is a safer version of js.Value. Any panic returns an error instead.
@@ -45,7 +44,7 @@ func toJSValue(jsValue any) any {
 	case Func:
 		return value.fn
 	case Error:
-		return value.err
+		return value.err.Value
 	case map[string]any:
 		newValue := make(map[string]any)
 		for mapKey, mapValue := range value {
@@ -78,14 +77,46 @@ func toValues(args []js.Value) []Value {
 // ValueOf returns value as a JavaScript value. See [js.ValueOf] for details.
 func ValueOf(value any) (Value, error) {
 	jsValue, err := catch.Try(func() js.Value {
-		return js.ValueOf(value)
+		return backend.Current().ValueOf(value)
 	})
 	return Safe(jsValue), err
 }
 
+// Apply does a JavaScript call of the value v with the given "this" value and arguments, like JavaScript's Reflect.apply().
+// The arguments get mapped to JavaScript values according to the ValueOf function.
+// Returns an error if v is not a JavaScript function, the arguments failed to map to JavaScript values, or the function throws an error.
+func (v Value) Apply(this Value, args ...any) (Value, error) {
+	if v.Type() != TypeFunction {
+		return Value{}, fmt.Errorf("invalid type for apply: %v", v.Type())
+	}
+	reflect, err := jsReflect.Get()
+	if err != nil {
+		return Value{}, err
+	}
+	args = toJSValues(args)
+	return catch.Try(func() Value {
+		return Safe(backend.Current().Call(reflect.jsValue, "apply", v.jsValue, this.jsValue, args))
+	})
+}
+
+// Bind returns a new JavaScript function which calls v with the given "this" value and leading arguments, like JavaScript's Function.prototype.bind().
+// The arguments get mapped to JavaScript values according to the ValueOf function.
+// Returns an error if v is not a JavaScript function or the arguments failed to map to JavaScript values.
+func (v Value) Bind(this Value, args ...any) (Value, error) {
+	if v.Type() != TypeFunction {
+		return Value{}, fmt.Errorf("invalid type for bind: %v", v.Type())
+	}
+	args = toJSValues(args)
+	return catch.Try(func() Value {
+		return Safe(backend.Current().Call(v.jsValue, "bind", append([]any{this.jsValue}, args...)...))
+	})
+}
+
 // Bool attempts to convert this value into a boolean, otherwise returns an error.
 func (v Value) Bool() (bool, error) {
-	return catch.Try(v.jsValue.Bool)
+	return catch.Try(func() bool {
+		return backend.Current().Bool(v.jsValue)
+	})
 }
 
 // Call does a JavaScript call to the method m of value v with the given arguments.
@@ -94,38 +125,40 @@ func (v Value) Bool() (bool, error) {
 func (v Value) Call(m string, args ...any) (Value, error) {
 	args = toJSValues(args)
 	return catch.Try(func() Value {
-		return Safe(v.jsValue.Call(m, args...))
+		return Safe(backend.Current().Call(v.jsValue, m, args...))
 	})
 }
 
 // Delete deletes the JavaScript property p of value v. Returns an error if v is not a JavaScript object.
 func (v Value) Delete(p string) error {
 	return catch.TrySideEffect(func() {
-		v.jsValue.Delete(p)
+		backend.Current().Delete(v.jsValue, p)
 	})
 }
 
 // Equal reports whether v and w are equal according to JavaScript's === operator.
 func (v Value) Equal(w Value) bool {
-	return v.jsValue.Equal(w.jsValue)
+	return backend.Current().Equal(v.jsValue, w.jsValue)
 }
 
 // Float returns the value v as a float64. Returns an error if v is not a JavaScript number.
 func (v Value) Float() (float64, error) {
-	return catch.Try(v.jsValue.Float)
+	return catch.Try(func() float64 {
+		return backend.Current().Float(v.jsValue)
+	})
 }
 
 // Get returns the JavaScript property p of value v. Returns an error if v is not a JavaScript object.
 func (v Value) Get(p string) (Value, error) {
 	return catch.Try(func() Value {
-		return Safe(v.jsValue.Get(p))
+		return Safe(backend.Current().Get(v.jsValue, p))
 	})
 }
 
 // Index returns JavaScript index i of value v. Returns an error if v is not a JavaScript object.
 func (v Value) Index(i int) (Value, error) {
 	return catch.Try(func() Value {
-		return Safe(v.jsValue.Index(i))
+		return Safe(backend.Current().Index(v.jsValue, i))
 	})
 }
 
@@ -146,13 +179,15 @@ func (v Value) InstanceOf(t Value) (bool, error) {
 		return false, fmt.Errorf("invalid constructor type for instanceof: %v", prototype.Type())
 	}
 	return catch.Try(func() bool {
-		return v.jsValue.InstanceOf(t.jsValue)
+		return backend.Current().InstanceOf(v.jsValue, t.jsValue)
 	})
 }
 
 // Int returns the value v truncated to an int. Returns an error if v is not a JavaScript number.
 func (v Value) Int() (int, error) {
-	return catch.Try(v.jsValue.Int)
+	return catch.Try(func() int {
+		return backend.Current().Int(v.jsValue)
+	})
 }
 
 // Invoke does a JavaScript call of the value v with the given arguments.
@@ -161,29 +196,31 @@ func (v Value) Int() (int, error) {
 func (v Value) Invoke(args ...any) (Value, error) {
 	args = toJSValues(args)
 	return catch.Try(func() Value {
-		return Safe(v.jsValue.Invoke(args...))
+		return Safe(backend.Current().Invoke(v.jsValue, args...))
 	})
 }
 
 // IsNaN reports whether v is the JavaScript value "NaN".
 func (v Value) IsNaN() bool {
-	return v.jsValue.IsNaN()
+	return backend.Current().IsNaN(v.jsValue)
 }
 
 // IsNull reports whether v is the JavaScript value "null".
 func (v Value) IsNull() bool {
-	return v.jsValue.IsNull()
+	return backend.Current().IsNull(v.jsValue)
 }
 
 // IsUndefined reports whether v is the JavaScript value "undefined".
 func (v Value) IsUndefined() bool {
-	return v.jsValue.IsUndefined()
+	return backend.Current().IsUndefined(v.jsValue)
 }
 
 // Length returns the JavaScript property "length" of v.
 // Returns an error if v is not a JavaScript object.
 func (v Value) Length() (int, error) {
-	return catch.Try(v.jsValue.Length)
+	return catch.Try(func() int {
+		return backend.Current().Length(v.jsValue)
+	})
 }
 
 // New uses JavaScript's "new" operator with value v as constructor and the given arguments.
@@ -192,7 +229,7 @@ func (v Value) Length() (int, error) {
 func (v Value) New(args ...any) (Value, error) {
 	args = toJSValues(args)
 	return catch.Try(func() Value {
-		return Safe(v.jsValue.New(args...))
+		return Safe(backend.Current().New(v.jsValue, args...))
 	})
 }
 
@@ -201,7 +238,7 @@ func (v Value) New(args ...any) (Value, error) {
 func (v Value) Set(p string, x any) error {
 	x = toJSValue(x)
 	return catch.TrySideEffect(func() {
-		v.jsValue.Set(p, x)
+		backend.Current().Set(v.jsValue, p, x)
 	})
 }
 
@@ -210,7 +247,7 @@ func (v Value) Set(p string, x any) error {
 func (v Value) SetIndex(i int, x any) error {
 	x = toJSValue(x)
 	return catch.TrySideEffect(func() {
-		v.jsValue.SetIndex(i, x)
+		backend.Current().SetIndex(v.jsValue, i, x)
 	})
 }
 
@@ -223,7 +260,9 @@ func (v Value) SetIndex(i int, x any) error {
 // NOTE: [syscall/js] takes the stance that String is a special case due to Go's String method convention and avoids panicking.
 // However, js.String() can still fail in other ways so an error is returned anyway.
 func (v Value) String() (string, error) {
-	return catch.Try(v.jsValue.String)
+	return catch.Try(func() string {
+		return backend.Current().String(v.jsValue)
+	})
 }
 
 // Truthy returns the JavaScript "truthiness" of the value v.
@@ -232,11 +271,13 @@ func (v Value) String() (string, error) {
 //
 // Returns an error if v's type is invalid or if the value fails to load from the JavaScript runtime.
 func (v Value) Truthy() (bool, error) {
-	return catch.Try(v.jsValue.Truthy)
+	return catch.Try(func() bool {
+		return backend.Current().Truthy(v.jsValue)
+	})
 }
 
 // Type returns the JavaScript type of the value v.
 // It is similar to JavaScript's typeof operator, except it returns TypeNull instead of TypeObject for null.
 func (v Value) Type() Type {
-	return Type(v.jsValue.Type())
+	return Type(backend.Current().Type(v.jsValue))
 }
@@ -1,12 +1,12 @@
-//go:build js && wasm
+//go:build (js && wasm) || safejstest
 
 package safejs
 
 import (
-	"syscall/js"
 	"testing"
 
 	"github.com/hack-pad/safejs/internal/assert"
+	"github.com/hack-pad/safejs/internal/js"
 )
 
 func TestSafeUnsafe(t *testing.T) {
@@ -91,6 +91,104 @@ func TestValueCall(t *testing.T) {
 	assert.Equal(t, "bar", resultStr)
 }
 
+func TestValueApply(t *testing.T) {
+	t.Parallel()
+	t.Run("explicit this", func(t *testing.T) {
+		t.Parallel()
+		var fnThis Value
+		var fnArgs []Value
+		fn, err := FuncOf(func(this Value, args []Value) any {
+			fnThis = this
+			fnArgs = args
+			return "bar"
+		})
+		assert.NoError(t, err)
+		defer fn.Release()
+		this, err := ValueOf(map[string]any{})
+		assert.NoError(t, err)
+		argValue, err := ValueOf("baz")
+		assert.NoError(t, err)
+
+		result, err := fn.Value().Apply(this, argValue, 1)
+		assert.NoError(t, err)
+		resultStr, err := result.String()
+		assert.NoError(t, err)
+		assert.Equal(t, "bar", resultStr)
+		assert.Equal(t, true, this.Equal(fnThis))
+		if assert.Equal(t, 2, len(fnArgs)) {
+			assert.Equal(t, true, argValue.Equal(fnArgs[0]))
+			fnArg1, err := fnArgs[1].Int()
+			assert.NoError(t, err)
+			assert.Equal(t, 1, fnArg1)
+		}
+	})
+
+	t.Run("not a function", func(t *testing.T) {
+		t.Parallel()
+		value, err := ValueOf("foo")
+		assert.NoError(t, err)
+
+		_, err = value.Apply(Undefined())
+		assert.EqualError(t, err, "invalid type for apply: string")
+	})
+
+	t.Run("error argument", func(t *testing.T) {
+		t.Parallel()
+		var fnArgs []Value
+		fn, err := FuncOf(func(this Value, args []Value) any {
+			fnArgs = args
+			return nil
+		})
+		assert.NoError(t, err)
+		defer fn.Release()
+		jsErr := Error{err: js.Error{Value: js.ValueOf(map[string]any{"message": "foo"})}}
+
+		_, err = fn.Value().Apply(Undefined(), jsErr)
+		assert.NoError(t, err)
+		if assert.Equal(t, 1, len(fnArgs)) {
+			assert.Equal(t, true, fnArgs[0].Equal(Safe(jsErr.err.Value)))
+		}
+	})
+}
+
+func TestValueBind(t *testing.T) {
+	t.Parallel()
+	t.Run("explicit this", func(t *testing.T) {
+		t.Parallel()
+		var fnThis Value
+		var fnArgs []Value
+		fn, err := FuncOf(func(this Value, args []Value) any {
+			fnThis = this
+			fnArgs = args
+			return nil
+		})
+		assert.NoError(t, err)
+		defer fn.Release()
+		this, err := ValueOf(map[string]any{})
+		assert.NoError(t, err)
+
+		bound, err := fn.Value().Bind(this, "foo")
+		assert.NoError(t, err)
+		_, err = bound.Invoke("bar")
+		assert.NoError(t, err)
+		assert.Equal(t, true, this.Equal(fnThis))
+		if assert.Equal(t, 2, len(fnArgs)) {
+			arg0, err := fnArgs[0].String()
+			assert.NoError(t, err)
+			assert.Equal(t, "foo", arg0)
+			arg1, err := fnArgs[1].String()
+			assert.NoError(t, err)
+			assert.Equal(t, "bar", arg1)
+		}
+	})
+
+	t.Run("not a function", func(t *testing.T) {
+		t.Parallel()
+		_, err := Null().Bind(Undefined())
+		assert.EqualError(t, err, "invalid type for bind: null")
+	})
+}
+
 func TestValueDelete(t *testing.T) {
 	t.Parallel()
 	obj, err := ValueOf(map[string]any{
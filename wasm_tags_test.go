@@ -16,7 +16,7 @@ import (
 func TestAllWasmTags(t *testing.T) {
 	t.Parallel()
 	const rootDir = "."
-	walkErr := filepath.Walk(rootDir, func(path string, info fs.FileInfo, err error) (resultErr error) {
+	walkErr := filepath.Walk(rootDir, func(path string, info fs.FileInfo, err error) error {
 		switch {
 		case err != nil:
 			return err
@@ -25,52 +25,62 @@ func TestAllWasmTags(t *testing.T) {
 		case info.IsDir(),
 			filepath.Ext(path) != ".go":
 			return nil
-		case path == "wasm_tags_test.go":
-			// ignore this file, since it must run with file system support enabled
-			return nil
 		}
 
-		f, err := os.Open(path)
+		expr, err := buildConstraint(path)
 		if err != nil {
 			return err
 		}
-		defer handleCloseErr(f, &resultErr)
-		scanner := bufio.NewScanner(f)
-		for scanner.Scan() {
-			line := strings.TrimSpace(scanner.Text())
-			if line == "" {
-				continue
-			}
-			if !strings.HasPrefix(line, "//") {
-				// hit non-comment line, so no build tags exist (see https://golang.org/cmd/go/#hdr-Build_constraints)
-				t.Errorf("File %q does not contain a js,wasm build tag", path)
-				break
-			}
-
-			expr, err := constraint.Parse(line)
-			if err != nil {
-				t.Logf("Build constraint failed to parse line in file %q: %q; %v", path, line, err)
-				continue
-			}
-			if isJSWasm(expr) {
-				break
-			}
+		isTest := strings.HasSuffix(path, "_test.go")
+		switch {
+		case !isTest && expr != nil:
+			t.Errorf("File %q must build on all platforms, but has build constraint %q", path, expr)
+		case isTest && expr == nil:
+			t.Errorf("File %q does not contain a js,wasm build tag", path)
+		case isTest && evalTags(expr, "js", "wasm") == evalTags(expr):
+			t.Errorf("File %q must run either with a JavaScript runtime or without one, but has build constraint %q", path, expr)
 		}
-		return scanner.Err()
+		return nil
 	})
 	if walkErr != nil {
 		t.Error("Walk failed:", walkErr)
 	}
 }
 
-func isJSWasm(expr constraint.Expr) bool {
-	switch expr := expr.(type) {
-	case *constraint.AndExpr:
-		x, y := expr.X.String(), expr.Y.String()
-		return (x == "js" && y == "wasm") || (x == "wasm" && y == "js")
-	default:
-		return false
+// buildConstraint returns the file's "//go:build" constraint, or nil if it has none
+func buildConstraint(path string) (expr constraint.Expr, resultErr error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer handleCloseErr(f, &resultErr)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "//") {
+			// hit non-comment line, so no build tags exist (see https://golang.org/cmd/go/#hdr-Build_constraints)
+			break
+		}
+		if constraint.IsGoBuild(line) {
+			return constraint.Parse(line)
+		}
 	}
+	return nil, scanner.Err()
+}
+
+// evalTags reports whether expr is satisfied when only the given tags are set
+func evalTags(expr constraint.Expr, tags ...string) bool {
+	return expr.Eval(func(tag string) bool {
+		for _, t := range tags {
+			if tag == t {
+				return true
+			}
+		}
+		return false
+	})
 }
 
 func handleCloseErr(closer io.Closer, resultErr *error) {